@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var alignCmd = &cobra.Command{
+	Use:   "align --script <script.txt> <input-file>",
+	Short: "Time an existing script against audio/video to a subtitle file",
+	Long: `Transcribe an audio or video file, align the words of a reference script to
+the recognised words and write subtitles of the script text. Script words
+take the timing of the recognised words they are aligned to, even where they
+were misrecognised; words missing from the recognition are timed between their
+neighbours. The script then goes through the same segmentation pipeline as a
+transcript.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAlign,
+}
+
+var scriptPath string
+
+func init() {
+	addTranscribeFlags(alignCmd)
+	alignCmd.Flags().StringVar(&scriptPath, "script", "", "plain text file of the exact words spoken")
+	alignCmd.MarkFlagRequired("script")
+
+	rootCmd.AddCommand(alignCmd)
+}
+
+func runAlign(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		return fmt.Errorf("read script: %w", err)
+	}
+	script := strings.TrimPrefix(string(data), "\ufeff") // byte order mark
+	if strings.TrimSpace(script) == "" {
+		return fmt.Errorf("script %s is empty", scriptPath)
+	}
+
+	opts, err := transcribeOptions(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	opts.Script = script
+	return runWorker(opts)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/pipeline"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/textwidth"
+
+	"github.com/spf13/cobra"
+)
+
+var dualCmd = &cobra.Command{
+	Use:   "dual <a.srt> <b.srt>",
+	Short: "Merge two subtitle files into a dual-language track",
+	Long: `Align the cues of two SRT files by time overlap and write combined cues showing
+both languages: an SRT with the first file's text above the second's, or an
+ASS script with the first file at the top of the screen and the second at the
+bottom. Cues that overlap several cues of the other file are combined with all
+of them.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDual,
+}
+
+var (
+	dualOutput     string
+	dualFormat     string
+	dualCPLA       int
+	dualCPLB       int
+	dualMinOverlap float64
+	dualWidthMode  string
+)
+
+func init() {
+	defaults := config.Default()
+
+	dualCmd.Flags().StringVarP(&dualOutput, "output", "o", "", "output path (default: <a>.dual.srt or <a>.dual.ass)")
+	dualCmd.Flags().StringVar(&dualFormat, "format", "", "output format: srt or ass (default: from the output extension, else srt)")
+	dualCmd.Flags().IntVar(&dualCPLA, "cpl-a", 0, "characters per line for the first file (0 = by script: --cjk-cpl or --latin-cpl default)")
+	dualCmd.Flags().IntVar(&dualCPLB, "cpl-b", 0, "characters per line for the second file (0 = by script)")
+	dualCmd.Flags().Float64Var(&dualMinOverlap, "min-overlap", 0.3, "share of the shorter cue (0-1) that must overlap for two cues to be paired")
+	dualCmd.Flags().StringVar(&dualWidthMode, "width-mode", defaults.WidthMode.String(), "line width model: runes, eastasian (full-width = 2 units), graphemes")
+
+	rootCmd.AddCommand(dualCmd)
+}
+
+func runDual(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(dualFormat)
+	if format == "" {
+		format = "srt"
+		if strings.EqualFold(filepath.Ext(dualOutput), ".ass") {
+			format = "ass"
+		}
+	}
+	if format != "srt" && format != "ass" {
+		return fmt.Errorf("unknown format %q (want srt or ass)", dualFormat)
+	}
+	if dualMinOverlap < 0 || dualMinOverlap > 1 {
+		return fmt.Errorf("--min-overlap must be between 0 and 1, got %g", dualMinOverlap)
+	}
+
+	mode, err := textwidth.ParseMode(dualWidthMode)
+	if err != nil {
+		return err
+	}
+
+	a, err := subtitle.LoadSRT(args[0])
+	if err != nil {
+		return err
+	}
+	b, err := subtitle.LoadSRT(args[1])
+	if err != nil {
+		return err
+	}
+
+	settings := config.Default().SubtitleSettings
+	settings.WidthMode = mode
+	cues := pipeline.CombineDual(a, b, &settings, pipeline.DualOptions{
+		MinOverlap: dualMinOverlap,
+		CPLA:       dualCPLA,
+		CPLB:       dualCPLB,
+	})
+
+	out := dualOutput
+	if out == "" {
+		out = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".dual." + format
+	}
+
+	var content string
+	if format == "ass" {
+		content = dualASS(cues, strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0])))
+	} else {
+		content = dualSRT(cues)
+	}
+	if err := os.WriteFile(out, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+
+	slog.Info("dual-language subtitles saved", "path", out, "cues", len(cues), "a", len(a), "b", len(b))
+	return nil
+}
+
+// dualSRT writes each combined cue with the first file's lines above the
+// second's.
+func dualSRT(cues []pipeline.DualCue) string {
+	out := make([]subtitle.Cue, len(cues))
+	for i, c := range cues {
+		lines := append(append([]string{}, c.A...), c.B...)
+		out[i] = subtitle.Cue{Start: c.Start, End: c.End, Text: strings.Join(lines, "\n")}
+	}
+	return subtitle.FormatSRT(out)
+}
+
+// dualASS writes the first file's text with a top-aligned style and the
+// second's with a bottom-aligned one.
+func dualASS(cues []pipeline.DualCue, title string) string {
+	top := subtitle.DefaultASSStyle("Top")
+	top.Alignment = subtitle.AlignTop
+	bottom := subtitle.DefaultASSStyle("Bottom")
+
+	var events []subtitle.ASSEvent
+	for _, c := range cues {
+		if len(c.A) > 0 {
+			events = append(events, subtitle.ASSEvent{Start: c.Start, End: c.End, Style: top.Name, Text: strings.Join(c.A, "\n")})
+		}
+		if len(c.B) > 0 {
+			events = append(events, subtitle.ASSEvent{Start: c.Start, End: c.End, Style: bottom.Name, Text: strings.Join(c.B, "\n")})
+		}
+	}
+	return subtitle.FormatASS(title, []subtitle.ASSStyle{top, bottom}, events)
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"scribe2srt/internal/lang"
+
+	"github.com/spf13/cobra"
+)
+
+var languagesCmd = &cobra.Command{
+	Use:   "languages",
+	Short: "List the languages accepted by --language",
+	Long: `List every language supported by the speech-to-text model, with the codes
+accepted by --language (ISO 639-1 or ISO 639-3) and the layout defaults used
+for it.`,
+	Args: cobra.NoArgs,
+	RunE: runLanguages,
+}
+
+func init() {
+	rootCmd.AddCommand(languagesCmd)
+}
+
+func runLanguages(cmd *cobra.Command, args []string) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CODE\tISO 639-3\tNAME\tSCRIPT\tSPACES\tCPS\tCPL\tSENTENCE END\tRTL")
+	for _, l := range lang.All() {
+		code1 := l.Code1
+		if code1 == "" {
+			code1 = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%g\t%d\t%s\t%s\n",
+			code1, l.Code3, l.Name, l.Script,
+			yesNo(l.SpaceDelimited), l.CPS, l.CPL, l.SentenceFinal, yesNo(l.RTL))
+	}
+	return tw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
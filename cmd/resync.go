@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/pipeline"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/worker"
+
+	"github.com/spf13/cobra"
+)
+
+var resyncCmd = &cobra.Command{
+	Use:   "resync <existing.srt> <input-file>",
+	Short: "Retime an existing SRT against the speech of audio/video",
+	Long: `Transcribe an audio or video file and retime the cues of an existing SRT to
+the recognised speech. The text of the cues is aligned to the recognised words;
+each cue whose words are found takes their timing, and cues that cannot be
+anchored, such as translated or paraphrased lines, are shifted with their
+anchored neighbours. Cue text and the division into cues are kept unchanged.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runResync,
+}
+
+var (
+	resyncOutput string
+	resyncReport string
+)
+
+func init() {
+	defaults := config.Default()
+	fs := resyncCmd.Flags()
+
+	fs.StringVarP(&resyncOutput, "output", "o", "", "output SRT path (default: <existing>.resync.srt)")
+	fs.StringVar(&resyncReport, "report", "", "write the cues that could not be anchored to this TSV file")
+	fs.StringVarP(&language, "language", "l", "auto", "language code of the speech (ISO 639-1 or 639-3) or auto")
+	fs.BoolVar(&noAsync, "no-async", false, "disable concurrent chunk processing")
+	fs.IntVarP(&maxConcurrent, "max-concurrent", "j", defaults.MaxConcurrentChunks, "max concurrent API uploads")
+	fs.IntVar(&maxRetries, "max-retries", defaults.MaxRetries, "max retries per chunk")
+	fs.IntVar(&rateLimit, "rate-limit", defaults.APIRateLimitPerMin, "API requests per minute")
+	fs.IntVar(&splitDuration, "split-duration", defaults.SplitDurationMin, "audio split threshold in minutes")
+	fs.StringVar(&keytermsPath, "keyterms", "", "text file of key terms or phrases (one per line) to bias recognition towards")
+	fs.Float64Var(&minGap, "min-gap", defaults.MinSubtitleGap, "minimum gap kept between retimed subtitles in seconds")
+
+	rootCmd.AddCommand(resyncCmd)
+}
+
+func runResync(cmd *cobra.Command, args []string) error {
+	cues, err := subtitle.LoadSRT(args[0])
+	if err != nil {
+		return err
+	}
+	if len(cues) == 0 {
+		return fmt.Errorf("%s has no cues", args[0])
+	}
+
+	absPath, err := resolveInput(args[1])
+	if err != nil {
+		return err
+	}
+	langCode, err := resolveLanguage(language)
+	if err != nil {
+		return err
+	}
+	keyterms, err := resolveKeyterms(keytermsPath, false, nil)
+	if err != nil {
+		return err
+	}
+
+	settings := config.Default().SubtitleSettings
+	settings.MinSubtitleGap = minGap
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	transcript, err := worker.Transcribe(ctx, worker.Options{
+		InputPath:        absPath,
+		Language:         langCode,
+		NoAsync:          noAsync,
+		MaxConcurrent:    maxConcurrent,
+		MaxRetries:       maxRetries,
+		RateLimitPerMin:  rateLimit,
+		SplitDurationMin: splitDuration,
+		Settings:         &settings,
+		Keyterms:         keyterms,
+	})
+	if err != nil {
+		return err
+	}
+
+	retimed, report, err := pipeline.Resync(cues, transcript, &settings)
+	if err != nil {
+		return err
+	}
+
+	out := resyncOutput
+	if out == "" {
+		out = strings.TrimSuffix(args[0], filepath.Ext(args[0])) + ".resync.srt"
+	}
+	if err := os.WriteFile(out, []byte(subtitle.FormatSRT(retimed)), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", out, err)
+	}
+
+	slog.Info("subtitles resynced", "path", out, "cues", len(cues), "anchored", report.Anchored)
+	if len(report.Unanchored) > 0 {
+		numbers := make([]string, len(report.Unanchored))
+		for i, c := range report.Unanchored {
+			numbers[i] = strconv.Itoa(c + 1)
+		}
+		slog.Warn("some cues could not be anchored and were shifted with their neighbours",
+			"count", len(report.Unanchored), "cues", strings.Join(numbers, ","))
+	}
+	if resyncReport != "" {
+		if err := saveResyncReport(resyncReport, cues, retimed, report.Unanchored); err != nil {
+			slog.Warn("failed to save resync report", "err", err)
+		} else {
+			slog.Info("resync report saved", "path", resyncReport)
+		}
+	}
+	return nil
+}
+
+// saveResyncReport writes one line per unanchored cue: its number, original
+// and new times, and its text on one line.
+func saveResyncReport(path string, orig, retimed []subtitle.Cue, unanchored []int) error {
+	var sb strings.Builder
+	sb.WriteString("cue\told_start\told_end\tnew_start\tnew_end\ttext\n")
+	for _, i := range unanchored {
+		fmt.Fprintf(&sb, "%d\t%s\t%s\t%s\t%s\t%s\n", i+1,
+			subtitle.FormatSRTTime(orig[i].Start), subtitle.FormatSRTTime(orig[i].End),
+			subtitle.FormatSRTTime(retimed[i].Start), subtitle.FormatSRTTime(retimed[i].End),
+			strings.Join(strings.Fields(orig[i].Text), " "))
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
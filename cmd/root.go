@@ -17,7 +17,7 @@ var (
 var rootCmd = &cobra.Command{
 	Use:     "scribe2srt",
 	Version: Version,
-	Short:   "Convert audio/video files to SRT subtitles using ElevenLabs STT",
+	Short:   "Convert audio/video files to subtitles using ElevenLabs STT",
 	Long: `Scribe2SRT converts audio and video files into professional SRT subtitle files
 using the ElevenLabs Speech-to-Text API with a two-stage processing pipeline
 (sentence splitting + intelligent merging).`,
@@ -10,16 +10,26 @@ import (
 	"strings"
 	"syscall"
 
+	"scribe2srt/internal/api"
+	"scribe2srt/internal/bidi"
 	"scribe2srt/internal/config"
+	"scribe2srt/internal/glossary"
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/profanity"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/textwidth"
+	"scribe2srt/internal/timecode"
+	"scribe2srt/internal/translate"
 	"scribe2srt/internal/worker"
+	"scribe2srt/internal/zhconv"
 
 	"github.com/spf13/cobra"
 )
 
 var transcribeCmd = &cobra.Command{
 	Use:   "transcribe <input-file>",
-	Short: "Transcribe audio/video to SRT subtitles",
-	Long: `Transcribe an audio or video file into an SRT subtitle file using the
+	Short: "Transcribe audio/video to a subtitle file",
+	Long: `Transcribe an audio or video file into a subtitle file using the
 ElevenLabs Speech-to-Text API with a two-stage processing pipeline.`,
 	Args: cobra.ExactArgs(1),
 	RunE: runTranscribe,
@@ -28,6 +38,19 @@ ElevenLabs Speech-to-Text API with a two-stage processing pipeline.`,
 var (
 	language        string
 	output          string
+	outputFormat    string
+	karaoke         bool
+	lrcEvents       string
+	title           string
+	artist          string
+	ttmlTime        string
+	stlCodePage     string
+	stlCharset      string
+	stlDisplay      string
+	stlRowChars     int
+	stlEpisode      string
+	stlPublisher    string
+	stlCountry      string
 	tagAudioEvents  bool
 	noAsync         bool
 	maxConcurrent   int
@@ -44,55 +67,276 @@ var (
 	latinCPS      float64
 	cjkCPL        int
 	latinCPL      int
+	widthMode     string
+	rtlMode       string
+	audioOverlap  string
+	audioEvents   string
+	glossaryPath  string
+	glossaryOut   string
+	keytermsPath  string
+	glossaryTerms bool
+	cleanVerbatim bool
+	fillersPath   string
+	zhVariant     string
+
+	// Profanity flags.
+	profanityLists       []string
+	profanityAllow       string
+	profanityStyle       string
+	profanityReplacement string
+
+	// Frame timing flags.
+	fps               string
+	minGapFrames      int
+	minDurationFrames int
+	chainFrames       int
+	timecodeFormat    string
+
+	// Shot-change flags.
+	snapToShots   bool
+	shotThreshold float64
+	shotWindow    float64
+
+	// Translation flags.
+	translateTo      string
+	translateUnit    string
+	translatorName   string
+	translatorURL    string
+	translatorModel  string
+	translatorKeyEnv string
+	bilingual        bool
+	translateContext int
+	translateBatch   int
 )
 
 func init() {
+	addTranscribeFlags(transcribeCmd)
+	rootCmd.AddCommand(transcribeCmd)
+}
+
+// addTranscribeFlags defines the transcription and subtitle flags on cmd.
+// Commands that transcribe share them, and the variables they set.
+func addTranscribeFlags(cmd *cobra.Command) {
 	defaults := config.Default()
+	fs := cmd.Flags()
 
-	transcribeCmd.Flags().StringVarP(&language, "language", "l", "auto", "language: ko, ja, zh, en, auto")
-	transcribeCmd.Flags().StringVarP(&output, "output", "o", "", "output SRT path (default: <input>.srt)")
-	transcribeCmd.Flags().BoolVar(&tagAudioEvents, "tag-audio-events", true, "tag audio events")
-	transcribeCmd.Flags().BoolVar(&noAsync, "no-async", false, "disable concurrent chunk processing")
-	transcribeCmd.Flags().IntVarP(&maxConcurrent, "max-concurrent", "j", defaults.MaxConcurrentChunks, "max concurrent API uploads")
-	transcribeCmd.Flags().IntVar(&maxRetries, "max-retries", defaults.MaxRetries, "max retries per chunk")
-	transcribeCmd.Flags().IntVar(&rateLimit, "rate-limit", defaults.APIRateLimitPerMin, "API requests per minute")
-	transcribeCmd.Flags().IntVar(&splitDuration, "split-duration", defaults.SplitDurationMin, "audio split threshold in minutes")
-	transcribeCmd.Flags().BoolVar(&saveJSON, "save-json", false, "save combined transcript JSON alongside SRT")
+	fs.StringVarP(&language, "language", "l", "auto", "language code (ISO 639-1 or 639-3, see 'scribe2srt languages') or auto")
+	fs.StringVarP(&output, "output", "o", "", "output path (default: <input> with the extension of --format)")
+	fs.StringVar(&outputFormat, "format", "", "output format: srt, ass, vtt, lrc, ttml (IMSC1), dfxp, stl (EBU) or scc (CEA-608) (default: from the output extension, else srt)")
+	fs.BoolVar(&karaoke, "karaoke", false, "mark the timing of each word: {\\k} tags in ASS, inline timestamps in WebVTT, enhanced LRC")
+	fs.StringVar(&lrcEvents, "lrc-events", config.LRCDropEvents.String(), "audio events in LRC output: drop, or marker (instrumental-break lines)")
+	fs.StringVar(&title, "title", "", "programme title for formats with metadata (default: the input's title tag)")
+	fs.StringVar(&artist, "artist", "", "artist for LRC output (default: the input's artist tag)")
+	fs.StringVar(&ttmlTime, "ttml-time", subtitle.TTMLClock.String(), "TTML time expressions: clock, frames (requires --fps) or smpte (DFXP only, requires --fps)")
+	fs.StringVar(&stlCodePage, "stl-code-page", defaults.STL.CodePage.String(), "EBU STL header code page: 437, 850, 860, 863 or 865")
+	fs.StringVar(&stlCharset, "stl-charset", "auto", "EBU STL text character table: latin, cyrillic, arabic, greek, hebrew, or auto (by language)")
+	fs.StringVar(&stlDisplay, "stl-display", defaults.STL.Display.String(), "EBU STL display standard: teletext or open")
+	fs.IntVar(&stlRowChars, "stl-row-chars", defaults.STL.RowChars, "EBU STL row width in characters, 1-40; lines are laid out to fit (0 = 37 for teletext, 40 for open)")
+	fs.StringVar(&stlEpisode, "stl-episode", "", "EBU STL episode title")
+	fs.StringVar(&stlPublisher, "stl-publisher", "", "EBU STL publisher")
+	fs.StringVar(&stlCountry, "stl-country", "", "EBU STL country of origin (ISO 3166 alpha-3, e.g. GBR)")
+	fs.BoolVar(&tagAudioEvents, "tag-audio-events", true, "tag audio events")
+	fs.BoolVar(&noAsync, "no-async", false, "disable concurrent chunk processing")
+	fs.IntVarP(&maxConcurrent, "max-concurrent", "j", defaults.MaxConcurrentChunks, "max concurrent API uploads")
+	fs.IntVar(&maxRetries, "max-retries", defaults.MaxRetries, "max retries per chunk")
+	fs.IntVar(&rateLimit, "rate-limit", defaults.APIRateLimitPerMin, "API requests per minute")
+	fs.IntVar(&splitDuration, "split-duration", defaults.SplitDurationMin, "audio split threshold in minutes")
+	fs.BoolVar(&saveJSON, "save-json", false, "save combined transcript JSON alongside the subtitle file")
 
 	// Subtitle tuning flags.
-	transcribeCmd.Flags().Float64Var(&minDuration, "min-duration", defaults.MinSubtitleDuration, "minimum subtitle duration in seconds")
-	transcribeCmd.Flags().Float64Var(&maxDuration, "max-duration", defaults.MaxSubtitleDuration, "maximum subtitle duration in seconds")
-	transcribeCmd.Flags().Float64Var(&minGap, "min-gap", defaults.MinSubtitleGap, "minimum gap between subtitles in seconds")
-	transcribeCmd.Flags().Float64Var(&cjkCPS, "cjk-cps", defaults.CJKCPS, "CJK characters per second limit")
-	transcribeCmd.Flags().Float64Var(&latinCPS, "latin-cps", defaults.LatinCPS, "Latin characters per second limit")
-	transcribeCmd.Flags().IntVar(&cjkCPL, "cjk-cpl", defaults.CJKCharsPerLine, "CJK characters per line limit")
-	transcribeCmd.Flags().IntVar(&latinCPL, "latin-cpl", defaults.LatinCharsPerLine, "Latin characters per line limit")
+	fs.Float64Var(&minDuration, "min-duration", defaults.MinSubtitleDuration, "minimum subtitle duration in seconds")
+	fs.Float64Var(&maxDuration, "max-duration", defaults.MaxSubtitleDuration, "maximum subtitle duration in seconds")
+	fs.Float64Var(&minGap, "min-gap", defaults.MinSubtitleGap, "minimum gap between subtitles in seconds")
+	fs.Float64Var(&cjkCPS, "cjk-cps", defaults.CJKCPS, "CJK characters per second limit (unset: the transcript language's, see 'scribe2srt languages')")
+	fs.Float64Var(&latinCPS, "latin-cps", defaults.LatinCPS, "characters per second limit for other scripts (unset: the transcript language's, see 'scribe2srt languages')")
+	fs.IntVar(&cjkCPL, "cjk-cpl", defaults.CJKCharsPerLine, "CJK characters per line limit (unset: the transcript language's)")
+	fs.IntVar(&latinCPL, "latin-cpl", defaults.LatinCharsPerLine, "characters per line limit for other scripts (unset: the transcript language's)")
+	fs.StringVar(&widthMode, "width-mode", defaults.WidthMode.String(), "line width model: runes, eastasian (full-width = 2 units), graphemes")
+	fs.StringVar(&audioOverlap, "audio-overlap", defaults.AudioOverlap.String(), "audio events overlapping speech: separate (own cue, moved clear of the speech), drop, merge (into the speech line) or stack (own line above, unless the speech fills two lines)")
+	fs.StringVar(&audioEvents, "audio-events", "", "audio event policy JSON file: drop categories, relabel, bracket style, min duration, attach to speech")
+	fs.StringVar(&glossaryPath, "glossary", "", "glossary TSV of corrections (pattern<TAB>replacement[<TAB>case,regex]) applied before sentence splitting")
+	fs.StringVar(&glossaryOut, "glossary-report", "", "write the glossary substitutions applied to this TSV file")
+	fs.StringVar(&keytermsPath, "keyterms", "", "text file of key terms or phrases (one per line) to bias recognition towards")
+	fs.BoolVar(&glossaryTerms, "glossary-keyterms", false, "also send the glossary's replacement terms as key terms")
+	fs.BoolVar(&cleanVerbatim, "clean-verbatim", false, "remove filler words (um, uh, ...) and collapse stutters and repetitions")
+	fs.StringVar(&fillersPath, "fillers", "", "JSON file of per-language filler lists for --clean-verbatim, e.g. {\"en\": [\"um\", \"uh\"]}")
+	fs.StringArrayVar(&profanityLists, "profanity-list", nil, "profanity word list file to mask, as [lang=]path (repeatable; without lang it applies to all languages)")
+	fs.StringVar(&profanityAllow, "profanity-allow", "", "allow-list file of words never masked")
+	fs.StringVar(&profanityStyle, "profanity-style", profanity.Asterisks.String(), "masking style: asterisks (****), first-letter (f***) or replace")
+	fs.StringVar(&profanityReplacement, "profanity-replacement", profanity.DefaultReplacement, "replacement text for --profanity-style replace")
+	fs.StringVar(&zhVariant, "zh-variant", zhconv.None.String(), "convert Chinese transcripts phrase by phrase to hans (Simplified), hant (Traditional), tw (Taiwan) or hk (Hong Kong)")
+	fs.StringVar(&rtlMode, "rtl-mode", defaults.RTLMode.String(), "right-to-left line marks: none, rlm (RLM prefix), embed (RLE/PDF), isolate (RLI/PDI)")
 
-	rootCmd.AddCommand(transcribeCmd)
+	// Frame timing flags.
+	fs.StringVar(&fps, "fps", "", "snap cue times to frames at this rate: 23.976, 24, 25, 29.97df, 29.97ndf, 30")
+	fs.IntVar(&minGapFrames, "min-gap-frames", defaults.MinGapFrames, "minimum gap between chained subtitles in frames (with --fps)")
+	fs.IntVar(&minDurationFrames, "min-duration-frames", defaults.MinDurationFrames, "minimum subtitle duration in frames (with --fps; 0 = --min-duration rounded up)")
+	fs.IntVar(&chainFrames, "chain-frames", defaults.ChainFrames, "close gaps shorter than this many frames to exactly --min-gap-frames (with --fps)")
+	fs.StringVar(&timecodeFormat, "timecode", "ms", "cue time format: ms, or smpte (HH:MM:SS:FF frame timecodes, requires --fps and --format dfxp, stl or scc)")
+
+	// Shot-change flags.
+	fs.BoolVar(&snapToShots, "snap-to-shots", false, "move cue boundaries onto nearby shot changes (video input, uses ffmpeg scene detection)")
+	fs.Float64Var(&shotThreshold, "shot-threshold", defaults.ShotThreshold, "scene-change score (0-1) that counts as a shot change")
+	fs.Float64Var(&shotWindow, "shot-window", defaults.ShotSnapWindow, "max distance in seconds from a cue boundary to a shot change it snaps to")
+
+	// Translation flags.
+	fs.StringVar(&translateTo, "translate-to", "", "translate the subtitles into this language code after merging")
+	fs.StringVar(&translateUnit, "translate-unit", config.TranslateCues.String(), "what to translate: cue (keep cue timing) or sentence (translate whole sentences, then re-segment them into cues)")
+	fs.StringVar(&translatorName, "translator", translate.OpenAI.String(), "translation provider: openai (OpenAI-compatible chat API) or http (local JSON endpoint)")
+	fs.StringVar(&translatorURL, "translator-url", "", "translation service URL (openai default: "+translate.DefaultOpenAIURL+")")
+	fs.StringVar(&translatorModel, "translator-model", "gpt-4o-mini", "model name for the openai translator")
+	fs.StringVar(&translatorKeyEnv, "translator-key-env", "OPENAI_API_KEY", "environment variable holding the translator API key")
+	fs.BoolVar(&bilingual, "bilingual", false, "keep the source text above the translation")
+	fs.IntVar(&translateContext, "translate-context", defaults.TranslateContext, "neighbouring cues sent as context on each side of a translation batch")
+	fs.IntVar(&translateBatch, "translate-batch", defaults.TranslateBatch, "cues translated per request")
 }
 
 func runTranscribe(cmd *cobra.Command, args []string) error {
-	inputPath := args[0]
+	opts, err := transcribeOptions(cmd, args[0])
+	if err != nil {
+		return err
+	}
+	return runWorker(opts)
+}
 
-	// Resolve to absolute path.
-	absPath, err := filepath.Abs(inputPath)
+// transcribeOptions validates the input file and the transcription flags and
+// returns the worker options for them.
+func transcribeOptions(cmd *cobra.Command, inputPath string) (worker.Options, error) {
+	absPath, err := resolveInput(inputPath)
 	if err != nil {
-		return fmt.Errorf("resolve path: %w", err)
+		return worker.Options{}, err
 	}
 
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		return fmt.Errorf("file not found: %s", inputPath)
+	langCode, err := resolveLanguage(language)
+	if err != nil {
+		return worker.Options{}, err
 	}
 
-	// Validate file extension.
-	ext := strings.ToLower(filepath.Ext(absPath))
-	validExts := map[string]bool{
-		".mp3": true, ".m4a": true, ".wav": true, ".flac": true,
-		".ogg": true, ".aac": true, ".mp4": true, ".mov": true,
-		".mkv": true, ".avi": true, ".flv": true, ".webm": true,
+	mode, err := textwidth.ParseMode(widthMode)
+	if err != nil {
+		return worker.Options{}, err
 	}
-	if !validExts[ext] {
-		return fmt.Errorf("unsupported file type: %s", ext)
+
+	rtl, err := bidi.ParseMode(rtlMode)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	overlap, err := config.ParseAudioOverlap(audioOverlap)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	var eventPolicy *config.AudioEventPolicy
+	if audioEvents != "" {
+		eventPolicy, err = config.LoadAudioEventPolicy(audioEvents)
+		if err != nil {
+			return worker.Options{}, err
+		}
+	}
+
+	var gloss *glossary.Glossary
+	if glossaryPath != "" {
+		gloss, err = glossary.Load(glossaryPath)
+		if err != nil {
+			return worker.Options{}, err
+		}
+	}
+
+	var fillers map[string][]string
+	if fillersPath != "" {
+		fillers, err = config.LoadFillers(fillersPath)
+		if err != nil {
+			return worker.Options{}, err
+		}
+	}
+
+	filter, err := loadProfanityFilter()
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	zhConverter, err := loadZhConverter(zhVariant, langCode)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	translation, err := loadTranslation(langCode)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	keyterms, err := resolveKeyterms(keytermsPath, glossaryTerms, gloss)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	rate, err := timecode.ParseRate(fps)
+	if err != nil {
+		return worker.Options{}, err
+	}
+
+	var smpte bool
+	switch strings.ToLower(timecodeFormat) {
+	case "ms", "":
+	case "smpte":
+		if rate.IsZero() {
+			return worker.Options{}, fmt.Errorf("--timecode smpte requires --fps")
+		}
+		smpte = true
+	default:
+		return worker.Options{}, fmt.Errorf("unknown timecode format %q (want ms or smpte)", timecodeFormat)
+	}
+
+	format, err := resolveOutputFormat(outputFormat, output)
+	if err != nil {
+		return worker.Options{}, err
+	}
+	if karaoke && format != config.FormatASS && format != config.FormatVTT && format != config.FormatLRC {
+		return worker.Options{}, fmt.Errorf("--karaoke requires --format ass, vtt or lrc")
+	}
+	lrcMode, err := config.ParseLRCEvents(lrcEvents)
+	if err != nil {
+		return worker.Options{}, err
+	}
+	timing, err := subtitle.ParseTTMLTiming(ttmlTime)
+	if err != nil {
+		return worker.Options{}, err
+	}
+	// SRT and the other text formats define only millisecond times; SMPTE
+	// timecodes go where the format has them. STL and SCC are always
+	// timed in frames.
+	if smpte {
+		switch format {
+		case config.FormatDFXP:
+			if cmd.Flags().Changed("ttml-time") && timing != subtitle.TTMLSMPTE {
+				return worker.Options{}, fmt.Errorf("--timecode smpte conflicts with --ttml-time %s", timing)
+			}
+			timing = subtitle.TTMLSMPTE
+		case config.FormatSTL, config.FormatSCC:
+		default:
+			return worker.Options{}, fmt.Errorf("--timecode smpte requires --format dfxp, stl or scc; %s does not define frame timecodes", format)
+		}
+	}
+	if timing != subtitle.TTMLClock && rate.IsZero() {
+		return worker.Options{}, fmt.Errorf("--ttml-time %s requires --fps", timing)
+	}
+	if timing == subtitle.TTMLSMPTE && format != config.FormatDFXP {
+		return worker.Options{}, fmt.Errorf("--ttml-time smpte is only supported with --format dfxp; IMSC1 requires media time")
+	}
+	stl, err := stlSettings()
+	if err != nil {
+		return worker.Options{}, err
+	}
+	if n := rate.Nominal(); format == config.FormatSTL && !rate.IsZero() && n != 25 && n != 30 {
+		return worker.Options{}, fmt.Errorf("--format stl requires --fps 25, 29.97 or 30, got %s", rate)
+	}
+	if format == config.FormatSCC && !rate.IsZero() && (rate.Num != 30000 || rate.Den != 1001) {
+		return worker.Options{}, fmt.Errorf("--format scc requires --fps 29.97 (SCC timecode is 29.97 drop-frame), got %s", rate)
+	}
+
+	if snapToShots && (shotThreshold <= 0 || shotThreshold >= 1) {
+		return worker.Options{}, fmt.Errorf("--shot-threshold must be between 0 and 1, got %g", shotThreshold)
 	}
 
 	settings := &config.SubtitleSettings{
@@ -103,16 +347,35 @@ func runTranscribe(cmd *cobra.Command, args []string) error {
 		LatinCPS:            latinCPS,
 		CJKCharsPerLine:     cjkCPL,
 		LatinCharsPerLine:   latinCPL,
+		LanguageLimits:      languageLimits(cmd),
+		WidthMode:           mode,
+		RTLMode:             rtl,
+		FrameRate:           rate,
+		MinGapFrames:        minGapFrames,
+		MinDurationFrames:   minDurationFrames,
+		ChainFrames:         chainFrames,
+		ShotSnapWindow:      shotWindow,
+		AudioOverlap:        overlap,
+		AudioEvents:         eventPolicy,
+		Glossary:            gloss,
+		CleanVerbatim:       cleanVerbatim,
+		Fillers:             fillers,
+		Profanity:           filter,
+		ZhConverter:         zhConverter,
+		Translation:         translation,
+		Format:              format,
+		Karaoke:             karaoke,
+		LRCEvents:           lrcMode,
+		Title:               title,
+		Artist:              artist,
+		TTMLTiming:          timing,
+		STL:                 stl,
 	}
 
-	// Setup signal handling for graceful cancellation.
-	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer stop()
-
-	opts := worker.Options{
+	return worker.Options{
 		InputPath:        absPath,
 		OutputPath:       output,
-		Language:         language,
+		Language:         langCode,
 		TagAudioEvents:   tagAudioEvents,
 		NoAsync:          noAsync,
 		MaxConcurrent:    maxConcurrent,
@@ -121,7 +384,17 @@ func runTranscribe(cmd *cobra.Command, args []string) error {
 		SplitDurationMin: splitDuration,
 		SaveJSON:         saveJSON,
 		Settings:         settings,
-	}
+		SnapToShots:      snapToShots,
+		ShotThreshold:    shotThreshold,
+		GlossaryReport:   glossaryOut,
+		Keyterms:         keyterms,
+	}, nil
+}
+
+// runWorker runs the worker with cancellation on SIGINT and SIGTERM.
+func runWorker(opts worker.Options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	if err := worker.Run(ctx, opts); err != nil {
 		return err
@@ -132,3 +405,228 @@ func runTranscribe(cmd *cobra.Command, args []string) error {
 	}
 	return nil
 }
+
+// stlSettings parses the EBU STL flags.
+func stlSettings() (config.STLSettings, error) {
+	codePage, err := subtitle.ParseSTLCodePage(stlCodePage)
+	if err != nil {
+		return config.STLSettings{}, err
+	}
+	display, err := subtitle.ParseSTLDisplay(stlDisplay)
+	if err != nil {
+		return config.STLSettings{}, err
+	}
+	if stlRowChars < 0 || stlRowChars > 40 {
+		return config.STLSettings{}, fmt.Errorf("--stl-row-chars must be 0 (display default) or 1-40, got %d", stlRowChars)
+	}
+	if stlCountry != "" && len(stlCountry) != 3 {
+		return config.STLSettings{}, fmt.Errorf("--stl-country must be a three-letter ISO 3166 code, got %q", stlCountry)
+	}
+	stl := config.STLSettings{
+		CodePage:     codePage,
+		Display:      display,
+		RowChars:     stlRowChars,
+		EpisodeTitle: stlEpisode,
+		Publisher:    stlPublisher,
+		Country:      stlCountry,
+	}
+	if !strings.EqualFold(stlCharset, "auto") {
+		charset, err := subtitle.ParseSTLCharset(stlCharset)
+		if err != nil {
+			return config.STLSettings{}, err
+		}
+		stl.Charset = &charset
+	}
+	return stl, nil
+}
+
+// resolveOutputFormat parses a --format value. Without one, the format is
+// taken from the extension of the output path, falling back to SRT.
+func resolveOutputFormat(name, outputPath string) (config.OutputFormat, error) {
+	if name == "" {
+		if f, err := config.ParseOutputFormat(strings.TrimPrefix(filepath.Ext(outputPath), ".")); err == nil {
+			return f, nil
+		}
+	}
+	return config.ParseOutputFormat(name)
+}
+
+// resolveInput returns the absolute path of a media file to transcribe,
+// checking that it exists and has a supported extension.
+func resolveInput(inputPath string) (string, error) {
+	// Resolve to absolute path.
+	absPath, err := filepath.Abs(inputPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve path: %w", err)
+	}
+
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("file not found: %s", inputPath)
+	}
+
+	// Validate file extension.
+	ext := strings.ToLower(filepath.Ext(absPath))
+	validExts := map[string]bool{
+		".mp3": true, ".m4a": true, ".wav": true, ".flac": true,
+		".ogg": true, ".aac": true, ".mp4": true, ".mov": true,
+		".mkv": true, ".avi": true, ".flv": true, ".webm": true,
+	}
+	if !validExts[ext] {
+		return "", fmt.Errorf("unsupported file type: %s", ext)
+	}
+	return absPath, nil
+}
+
+// resolveLanguage validates a --language value against the language registry
+// and returns the ISO 639-3 code sent to the API, or "auto".
+func resolveLanguage(code string) (string, error) {
+	if lang.IsAuto(code) {
+		return "auto", nil
+	}
+	l, ok := lang.Lookup(code)
+	if !ok {
+		return "", fmt.Errorf("unsupported language %q (run 'scribe2srt languages' for the list)", code)
+	}
+	return l.Code3, nil
+}
+
+// languageLimits returns the CPS and CPL limits left at their defaults on
+// the command line; they follow the transcript language's registry entry.
+func languageLimits(cmd *cobra.Command) config.LanguageLimits {
+	limits := config.AllLimits
+	for flag, limit := range map[string]config.LanguageLimits{
+		"latin-cps": config.LatinCPSLimit,
+		"latin-cpl": config.LatinCPLLimit,
+		"cjk-cps":   config.CJKCPSLimit,
+		"cjk-cpl":   config.CJKCPLLimit,
+	} {
+		if cmd.Flags().Changed(flag) {
+			limits &^= limit
+		}
+	}
+	return limits
+}
+
+// resolveKeyterms collects the key terms from the --keyterms file and, when
+// fromGlossary is set, the glossary, and applies the API limits.
+func resolveKeyterms(path string, fromGlossary bool, g *glossary.Glossary) ([]string, error) {
+	var terms []string
+	if path != "" {
+		loaded, err := api.LoadKeyterms(path)
+		if err != nil {
+			return nil, err
+		}
+		terms = loaded
+	}
+	if fromGlossary {
+		if g == nil {
+			return nil, fmt.Errorf("--glossary-keyterms requires --glossary")
+		}
+		terms = append(terms, g.Terms()...)
+	}
+
+	kept, skipped := api.NormalizeKeyterms(terms)
+	if len(skipped) > 0 {
+		slog.Warn("some key terms were skipped",
+			"skipped", len(skipped), "max_terms", api.MaxKeyterms, "max_length", api.MaxKeytermLength)
+	}
+	if len(kept) > 0 {
+		slog.Debug("key terms", "count", len(kept))
+	}
+	return kept, nil
+}
+
+// loadProfanityFilter builds the profanity filter from the --profanity-*
+// flags, or returns nil when no list is given.
+func loadProfanityFilter() (*profanity.Filter, error) {
+	if len(profanityLists) == 0 {
+		if profanityAllow != "" {
+			return nil, fmt.Errorf("--profanity-allow requires --profanity-list")
+		}
+		return nil, nil
+	}
+
+	style, err := profanity.ParseStyle(profanityStyle)
+	if err != nil {
+		return nil, err
+	}
+	f := profanity.New(style, profanityReplacement)
+
+	for _, spec := range profanityLists {
+		code, path, ok := strings.Cut(spec, "=")
+		if !ok {
+			code, path = "", spec
+		} else if _, known := lang.Lookup(code); !known {
+			return nil, fmt.Errorf("--profanity-list %s: unsupported language %q", spec, code)
+		}
+		if err := f.LoadList(code, path); err != nil {
+			return nil, err
+		}
+	}
+	if profanityAllow != "" {
+		if err := f.LoadAllowList(profanityAllow); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// loadZhConverter parses --zh-variant and loads its dictionaries. It is an
+// error to ask for a variant when the transcript language is set to a
+// language not written in Chinese characters.
+func loadZhConverter(variant, langCode string) (*zhconv.Converter, error) {
+	v, err := zhconv.ParseVariant(variant)
+	if err != nil || v == zhconv.None {
+		return nil, err
+	}
+	if !lang.IsAuto(langCode) {
+		if l, _ := lang.Lookup(langCode); l.Script != lang.Han {
+			return nil, fmt.Errorf("--zh-variant requires a Chinese transcript, got language %q", langCode)
+		}
+	}
+	return zhconv.New(v)
+}
+
+// loadTranslation builds the translation settings from the --translate-*
+// and --translator-* flags, or returns nil when --translate-to is not set.
+func loadTranslation(sourceLang string) (*config.Translation, error) {
+	if translateTo == "" {
+		if bilingual {
+			return nil, fmt.Errorf("--bilingual requires --translate-to")
+		}
+		return nil, nil
+	}
+	target, ok := lang.Lookup(translateTo)
+	if !ok {
+		return nil, fmt.Errorf("--translate-to: unsupported language %q", translateTo)
+	}
+	if !lang.IsAuto(sourceLang) {
+		if source, _ := lang.Lookup(sourceLang); source.Code3 == target.Code3 {
+			return nil, fmt.Errorf("--translate-to %s is the transcript language", translateTo)
+		}
+	}
+	if translateBatch < 1 || translateContext < 0 {
+		return nil, fmt.Errorf("--translate-batch must be at least 1 and --translate-context at least 0")
+	}
+
+	unit, err := config.ParseTranslationUnit(translateUnit)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := translate.ParseProvider(translatorName)
+	if err != nil {
+		return nil, err
+	}
+	t, err := translate.New(provider, translatorURL, translatorModel, os.Getenv(translatorKeyEnv))
+	if err != nil {
+		return nil, err
+	}
+	return &config.Translation{
+		Translator: t,
+		Target:     target.Code(),
+		Unit:       unit,
+		Bilingual:  bilingual,
+		Context:    translateContext,
+		BatchSize:  translateBatch,
+	}, nil
+}
@@ -75,7 +75,9 @@ func mimeFromExt(ext string) string {
 }
 
 // Transcribe uploads an audio/video file to ElevenLabs STT and returns the transcript.
-func Transcribe(ctx context.Context, filePath, languageCode string, tagAudioEvents bool, progress ProgressFunc) (*pipeline.TranscriptResponse, error) {
+// Key terms, if any, bias recognition towards those words and phrases; a
+// backend that rejects them yields ErrKeytermsUnsupported.
+func Transcribe(ctx context.Context, filePath, languageCode string, tagAudioEvents bool, keyterms []string, progress ProgressFunc) (*pipeline.TranscriptResponse, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("open file: %w", err)
@@ -121,6 +123,13 @@ func Transcribe(ctx context.Context, filePath, languageCode string, tagAudioEven
 			}
 		}
 
+		for _, term := range keyterms {
+			if err := mw.WriteField("keyterms", term); err != nil {
+				errCh <- err
+				return
+			}
+		}
+
 		mimeType := mimeFromExt(filepath.Ext(filePath))
 		h := make(textproto.MIMEHeader)
 		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, filepath.Base(filePath)))
@@ -176,6 +185,9 @@ func Transcribe(ctx context.Context, filePath, languageCode string, tagAudioEven
 
 	if resp.StatusCode != http.StatusOK {
 		respBody, _ := io.ReadAll(resp.Body)
+		if len(keyterms) > 0 && isKeytermsRejection(resp.StatusCode, string(respBody)) {
+			return nil, fmt.Errorf("%w: API returned status %d: %s", ErrKeytermsUnsupported, resp.StatusCode, string(respBody))
+		}
 		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
 	}
 
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits on the key terms accepted by the speech-to-text API.
+const (
+	MaxKeyterms      = 100
+	MaxKeytermLength = 50
+)
+
+// ErrKeytermsUnsupported is returned by Transcribe when the backend rejects
+// the key terms field, so callers can retry without biasing.
+var ErrKeytermsUnsupported = errors.New("key terms not supported by the transcription backend")
+
+// LoadKeyterms reads key terms from a text file, one term or phrase per
+// line. Blank lines and lines starting with # are ignored.
+func LoadKeyterms(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open key terms: %w", err)
+	}
+	defer f.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read key terms %s: %w", path, err)
+	}
+	return terms, nil
+}
+
+// NormalizeKeyterms trims and de-duplicates terms, keeping the first
+// occurrence, and enforces the API limits. Terms that are too long or beyond
+// MaxKeyterms are returned as skipped.
+func NormalizeKeyterms(terms []string) (kept, skipped []string) {
+	seen := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		t = strings.Join(strings.Fields(t), " ")
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if utf8.RuneCountInString(t) > MaxKeytermLength || len(kept) >= MaxKeyterms {
+			skipped = append(skipped, t)
+			continue
+		}
+		kept = append(kept, t)
+	}
+	return kept, skipped
+}
+
+// isKeytermsRejection reports whether an error response from the API
+// complains about the key terms field.
+func isKeytermsRejection(status int, body string) bool {
+	if status != 400 && status != 422 {
+		return false
+	}
+	return strings.Contains(strings.ToLower(body), "keyterm")
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadKeyterms(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terms.txt")
+	content := "# characters\nTanjiro Kamado\n\n  Nezuko  \nacetaminophen\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadKeyterms(path)
+	if err != nil {
+		t.Fatalf("LoadKeyterms() error = %v", err)
+	}
+	want := []string{"Tanjiro Kamado", "Nezuko", "acetaminophen"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadKeyterms() = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeKeyterms(t *testing.T) {
+	long := strings.Repeat("x", MaxKeytermLength+1)
+	kept, skipped := NormalizeKeyterms([]string{"OpenAI", " open  ai ", "openai", "", long, "GPT"})
+
+	if want := []string{"OpenAI", "open ai", "GPT"}; !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept = %v, want %v", kept, want)
+	}
+	if want := []string{long}; !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %v, want %v", skipped, want)
+	}
+}
+
+func TestNormalizeKeyterms_Limit(t *testing.T) {
+	terms := make([]string, MaxKeyterms+5)
+	for i := range terms {
+		terms[i] = fmt.Sprintf("term %d", i)
+	}
+	kept, skipped := NormalizeKeyterms(terms)
+	if len(kept) != MaxKeyterms || len(skipped) != 5 {
+		t.Errorf("kept %d, skipped %d; want %d and 5", len(kept), len(skipped), MaxKeyterms)
+	}
+}
+
+func TestIsKeytermsRejection(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{422, `{"detail":"Unexpected field: keyterms"}`, true},
+		{400, `{"detail":"keyterms are not supported for this model"}`, true},
+		{400, `{"detail":"invalid language_code"}`, false},
+		{500, `keyterms`, false},
+	}
+	for _, tt := range tests {
+		if got := isKeytermsRejection(tt.status, tt.body); got != tt.want {
+			t.Errorf("isKeytermsRejection(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
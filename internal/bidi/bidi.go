@@ -0,0 +1,180 @@
+// Package bidi adds Unicode directional formatting to right-to-left subtitle
+// lines so players that ignore the paragraph direction still render the
+// punctuation and embedded left-to-right text on the correct side.
+package bidi
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Mode selects how right-to-left lines are marked up.
+type Mode int
+
+const (
+	// None writes text unchanged.
+	None Mode = iota
+	// RLM prefixes each RTL line with a RIGHT-TO-LEFT MARK and follows each
+	// embedded LTR run with a LEFT-TO-RIGHT MARK. It is the most widely
+	// supported option.
+	RLM
+	// Embed wraps each RTL line in RLE…PDF and each embedded LTR run in
+	// LRE…PDF.
+	Embed
+	// Isolate wraps each RTL line in RLI…PDI and each embedded LTR run in
+	// LRI…PDI. Only recent renderers support isolates.
+	Isolate
+)
+
+// Directional formatting characters.
+const (
+	lrm = "\u200e" // LEFT-TO-RIGHT MARK
+	rlm = "\u200f" // RIGHT-TO-LEFT MARK
+	lre = "\u202a" // LEFT-TO-RIGHT EMBEDDING
+	rle = "\u202b" // RIGHT-TO-LEFT EMBEDDING
+	pdf = "\u202c" // POP DIRECTIONAL FORMATTING
+	lri = "\u2066" // LEFT-TO-RIGHT ISOLATE
+	rli = "\u2067" // RIGHT-TO-LEFT ISOLATE
+	pdi = "\u2069" // POP DIRECTIONAL ISOLATE
+)
+
+// ParseMode parses an --rtl-mode value.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none", "off":
+		return None, nil
+	case "rlm":
+		return RLM, nil
+	case "embed":
+		return Embed, nil
+	case "isolate":
+		return Isolate, nil
+	}
+	return None, fmt.Errorf("unknown RTL mode %q (want none, rlm, embed or isolate)", s)
+}
+
+func (m Mode) String() string {
+	switch m {
+	case RLM:
+		return "rlm"
+	case Embed:
+		return "embed"
+	case Isolate:
+		return "isolate"
+	default:
+		return "none"
+	}
+}
+
+// marks returns the characters placed around an RTL line and around each LTR
+// run inside it.
+func (m Mode) marks() (lineOpen, lineClose, runOpen, runClose string) {
+	switch m {
+	case RLM:
+		return rlm, "", "", lrm
+	case Embed:
+		return rle, pdf, lre, pdf
+	case Isolate:
+		return rli, pdi, lri, pdi
+	}
+	return "", "", "", ""
+}
+
+// isRTLLetter reports whether r is a strong right-to-left letter.
+func isRTLLetter(r rune) bool {
+	return unicode.IsLetter(r) &&
+		unicode.In(r, unicode.Hebrew, unicode.Arabic, unicode.Syriac, unicode.Thaana, unicode.Nko)
+}
+
+// IsRTL reports whether right-to-left letters dominate line.
+func IsRTL(line string) bool {
+	rtl, ltr := 0, 0
+	for _, r := range line {
+		switch {
+		case isRTLLetter(r):
+			rtl++
+		case unicode.IsLetter(r):
+			ltr++
+		}
+	}
+	return rtl > 0 && rtl >= ltr
+}
+
+// tokenKind classifies a space-separated token of an RTL line.
+type tokenKind int
+
+const (
+	tokenRTL tokenKind = iota
+	tokenLTR
+	tokenNumber
+)
+
+func classify(token string) tokenKind {
+	hasLTR, hasDigit := false, false
+	for _, r := range token {
+		switch {
+		case isRTLLetter(r):
+			return tokenRTL
+		case unicode.IsLetter(r):
+			hasLTR = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	switch {
+	case hasLTR:
+		return tokenLTR
+	case hasDigit:
+		return tokenNumber
+	}
+	return tokenRTL
+}
+
+// Apply marks up one subtitle line according to mode. Lines that are not
+// predominantly right-to-left are returned unchanged. Inside an RTL line,
+// runs of left-to-right words (brand names, model numbers such as
+// "iPhone 15") are marked so their own punctuation stays with them.
+func Apply(line string, mode Mode) string {
+	if mode == None || !IsRTL(line) {
+		return line
+	}
+	lineOpen, lineClose, runOpen, runClose := mode.marks()
+
+	tokens := strings.Split(line, " ")
+	var sb strings.Builder
+	sb.WriteString(lineOpen)
+	for i := 0; i < len(tokens); i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		if classify(tokens[i]) != tokenLTR {
+			sb.WriteString(tokens[i])
+			continue
+		}
+
+		// Extend the run over following LTR words and the numbers after them.
+		j := i
+		for j+1 < len(tokens) && classify(tokens[j+1]) != tokenRTL {
+			j++
+		}
+		sb.WriteString(runOpen)
+		sb.WriteString(strings.Join(tokens[i:j+1], " "))
+		sb.WriteString(runClose)
+		i = j
+	}
+	sb.WriteString(lineClose)
+	return sb.String()
+}
+
+// ApplyLines applies mode to every line of a multi-line cue text.
+func ApplyLines(text string, mode Mode) string {
+	if mode == None {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = Apply(line, mode)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,84 @@
+package bidi
+
+import "testing"
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Mode
+		wantErr bool
+	}{
+		{"", None, false},
+		{"none", None, false},
+		{"RLM", RLM, false},
+		{"embed", Embed, false},
+		{"isolate", Isolate, false},
+		{"auto", None, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMode(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMode(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestIsRTL(t *testing.T) {
+	tests := []struct {
+		line string
+		want bool
+	}{
+		{"\u0645\u0631\u062d\u0628\u0627 \u0628\u0627\u0644\u0639\u0627\u0644\u0645", true}, // مرحبا بالعالم
+		{"\u05e9\u05dc\u05d5\u05dd \u05e2\u05d5\u05dc\u05dd", true},                         // שלום עולם
+		{"Hello world", false},
+		{"iPhone 15 \u0647\u0648 \u0627\u0644\u0623\u0641\u0636\u0644", true}, // iPhone 15 هو الأفضل
+		{"123", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsRTL(tt.line); got != tt.want {
+			t.Errorf("IsRTL(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	// هذا iPhone 15 جديد؟ — "this is a new iPhone 15?"
+	line := "\u0647\u0630\u0627 iPhone 15 \u062c\u062f\u064a\u062f\u061f"
+
+	tests := []struct {
+		mode Mode
+		want string
+	}{
+		{None, line},
+		{RLM, rlm + "\u0647\u0630\u0627 iPhone 15" + lrm + " \u062c\u062f\u064a\u062f\u061f"},
+		{Embed, rle + "\u0647\u0630\u0627 " + lre + "iPhone 15" + pdf + " \u062c\u062f\u064a\u062f\u061f" + pdf},
+		{Isolate, rli + "\u0647\u0630\u0627 " + lri + "iPhone 15" + pdi + " \u062c\u062f\u064a\u062f\u061f" + pdi},
+	}
+
+	for _, tt := range tests {
+		if got := Apply(line, tt.mode); got != tt.want {
+			t.Errorf("Apply(%v) = %q, want %q", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestApply_LeavesLTRLinesAlone(t *testing.T) {
+	line := "Hello world!"
+	if got := Apply(line, RLM); got != line {
+		t.Errorf("Apply(LTR line) = %q, want unchanged", got)
+	}
+}
+
+func TestApplyLines(t *testing.T) {
+	text := "\u05e9\u05dc\u05d5\u05dd\nHello" // שלום\nHello
+	want := rlm + "\u05e9\u05dc\u05d5\u05dd\nHello"
+	if got := ApplyLines(text, RLM); got != want {
+		t.Errorf("ApplyLines = %q, want %q", got, want)
+	}
+}
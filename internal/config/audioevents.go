@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// AudioEventPolicy controls how audio events such as "(laughter)" are
+// filtered and labelled. It is loaded from a JSON file:
+//
+//	{
+//	  "drop": ["breathing", "coughing"],
+//	  "labels": {"laughter": "笑", "music": "音楽"},
+//	  "brackets": "fullwidth",
+//	  "uppercase": false,
+//	  "min_duration": 0.5,
+//	  "attach": "stack",
+//	  "attach_within": 1.0
+//	}
+//
+// Categories are event labels without brackets, compared case-insensitively.
+type AudioEventPolicy struct {
+	// Drop lists the categories removed from the output.
+	Drop []string `json:"drop"`
+	// Labels maps a category to the text shown instead of the recogniser's
+	// wording, without brackets.
+	Labels map[string]string `json:"labels"`
+	// Brackets is round, square, fullwidth, lenticular, none, or a literal
+	// pair of opening and closing characters such as "<>". Empty keeps the
+	// recogniser's brackets.
+	Brackets string `json:"brackets"`
+	// Uppercase writes labels in capitals, as SDH style guides often ask.
+	Uppercase bool `json:"uppercase"`
+	// MinDuration drops events shorter than this many seconds.
+	MinDuration float64 `json:"min_duration"`
+	// Attach joins events to the nearest speech cue within AttachWithin
+	// seconds instead of giving them a cue of their own: merge puts the label
+	// in the speech line, stack puts it on a line above.
+	Attach       string  `json:"attach"`
+	AttachWithin float64 `json:"attach_within"`
+
+	drop   map[string]bool
+	labels map[string]string
+	open   string
+	close  string
+	attach AudioOverlap
+}
+
+// bracketStyles maps named bracket styles to their opening and closing text.
+var bracketStyles = map[string][2]string{
+	"round":      {"(", ")"},
+	"square":     {"[", "]"},
+	"fullwidth":  {"\uff08", "\uff09"}, // （）
+	"lenticular": {"\u3010", "\u3011"}, // 【】
+	"none":       {"", ""},
+}
+
+// defaultAttachWithin is used when Attach is set without AttachWithin.
+const defaultAttachWithin = 1.0
+
+// LoadAudioEventPolicy reads and validates an audio-event policy file.
+func LoadAudioEventPolicy(path string) (*AudioEventPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read audio event policy: %w", err)
+	}
+
+	var p AudioEventPolicy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parse audio event policy %s: %w", path, err)
+	}
+	if err := p.init(); err != nil {
+		return nil, fmt.Errorf("audio event policy %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// init validates the policy and builds its lookup tables.
+func (p *AudioEventPolicy) init() error {
+	p.drop = make(map[string]bool, len(p.Drop))
+	for _, c := range p.Drop {
+		p.drop[EventCategory(c)] = true
+	}
+	p.labels = make(map[string]string, len(p.Labels))
+	for c, label := range p.Labels {
+		p.labels[EventCategory(c)] = label
+	}
+
+	switch style, ok := bracketStyles[strings.ToLower(p.Brackets)]; {
+	case p.Brackets == "":
+	case ok:
+		p.open, p.close = style[0], style[1]
+	case utf8.RuneCountInString(p.Brackets) == 2:
+		r, size := utf8.DecodeRuneInString(p.Brackets)
+		p.open, p.close = string(r), p.Brackets[size:]
+	default:
+		return fmt.Errorf("unknown bracket style %q (want round, square, fullwidth, lenticular, none or a character pair)", p.Brackets)
+	}
+
+	if p.MinDuration < 0 {
+		return fmt.Errorf("min_duration must not be negative")
+	}
+
+	switch strings.ToLower(p.Attach) {
+	case "":
+	case "merge":
+		p.attach = OverlapMerge
+	case "stack":
+		p.attach = OverlapStack
+	default:
+		return fmt.Errorf("unknown attach mode %q (want merge or stack)", p.Attach)
+	}
+	if p.Attach != "" && p.AttachWithin <= 0 {
+		p.AttachWithin = defaultAttachWithin
+	}
+	return nil
+}
+
+// eventBrackets are the bracket pairs recognised around event labels.
+var eventBrackets = [][2]string{
+	{"(", ")"},
+	{"[", "]"},
+	{"\uff08", "\uff09"}, // （）
+	{"\u3010", "\u3011"}, // 【】
+}
+
+// splitBrackets separates an event label from the brackets around it.
+func splitBrackets(label string) (open, text, close string) {
+	label = strings.TrimSpace(label)
+	for _, b := range eventBrackets {
+		if strings.HasPrefix(label, b[0]) && strings.HasSuffix(label, b[1]) && len(label) >= len(b[0])+len(b[1]) {
+			return b[0], strings.TrimSpace(label[len(b[0]) : len(label)-len(b[1])]), b[1]
+		}
+	}
+	return "", label, ""
+}
+
+// EventCategory returns the category of an event label: its text without
+// brackets, lower-cased.
+func EventCategory(label string) string {
+	_, text, _ := splitBrackets(label)
+	return strings.ToLower(text)
+}
+
+// Apply returns the text to show for an event with the given label and
+// duration, or false when the policy drops it.
+func (p *AudioEventPolicy) Apply(label string, duration float64) (string, bool) {
+	open, text, close := splitBrackets(label)
+	category := strings.ToLower(text)
+
+	if p.drop[category] || duration < p.MinDuration {
+		return "", false
+	}
+	if l, ok := p.labels[category]; ok {
+		text = l
+	}
+	if p.Uppercase {
+		text = strings.ToUpper(text)
+	}
+	if p.Brackets != "" {
+		open, close = p.open, p.close
+	}
+	return open + text + close, true
+}
+
+// AttachMode reports how events are attached to neighbouring speech cues,
+// and false when they keep cues of their own.
+func (p *AudioEventPolicy) AttachMode() (AudioOverlap, bool) {
+	if p == nil || p.Attach == "" {
+		return 0, false
+	}
+	return p.attach, true
+}
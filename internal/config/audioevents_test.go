@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAudioEventPolicy_Apply(t *testing.T) {
+	path := writePolicy(t, `{
+		"drop": ["Breathing"],
+		"labels": {"laughter": "\u7b11"},
+		"brackets": "fullwidth",
+		"min_duration": 0.3
+	}`)
+	p, err := LoadAudioEventPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadAudioEventPolicy() error = %v", err)
+	}
+
+	tests := []struct {
+		label    string
+		duration float64
+		want     string
+		wantOK   bool
+	}{
+		{"(laughter)", 1, "\uff08\u7b11\uff09", true}, // （笑）
+		{"[Laughter]", 1, "\uff08\u7b11\uff09", true},
+		{"(music)", 1, "\uff08music\uff09", true}, // （music）
+		{"(breathing)", 1, "", false},
+		{"(music)", 0.2, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := p.Apply(tt.label, tt.duration)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("Apply(%q, %v) = %q, %v; want %q, %v", tt.label, tt.duration, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAudioEventPolicy_SDHStyle(t *testing.T) {
+	p, err := LoadAudioEventPolicy(writePolicy(t, `{"brackets": "square", "uppercase": true}`))
+	if err != nil {
+		t.Fatalf("LoadAudioEventPolicy() error = %v", err)
+	}
+	if got, _ := p.Apply("(music)", 2); got != "[MUSIC]" {
+		t.Errorf("Apply() = %q, want [MUSIC]", got)
+	}
+	if _, ok := p.AttachMode(); ok {
+		t.Error("expected no attach mode")
+	}
+}
+
+func TestAudioEventPolicy_Attach(t *testing.T) {
+	p, err := LoadAudioEventPolicy(writePolicy(t, `{"attach": "merge"}`))
+	if err != nil {
+		t.Fatalf("LoadAudioEventPolicy() error = %v", err)
+	}
+	mode, ok := p.AttachMode()
+	if !ok || mode != OverlapMerge {
+		t.Errorf("AttachMode() = %v, %v; want merge, true", mode, ok)
+	}
+	if p.AttachWithin != defaultAttachWithin {
+		t.Errorf("AttachWithin = %v, want %v", p.AttachWithin, defaultAttachWithin)
+	}
+}
+
+func TestLoadAudioEventPolicy_Invalid(t *testing.T) {
+	for _, content := range []string{
+		`{"brackets": "curly"}`,
+		`{"attach": "drop"}`,
+		`{"min_duration": -1}`,
+		`not json`,
+	} {
+		if _, err := LoadAudioEventPolicy(writePolicy(t, content)); err == nil {
+			t.Errorf("LoadAudioEventPolicy(%s) succeeded, want error", content)
+		}
+	}
+}
@@ -1,5 +1,15 @@
 package config
 
+import (
+	"scribe2srt/internal/bidi"
+	"scribe2srt/internal/glossary"
+	"scribe2srt/internal/profanity"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/textwidth"
+	"scribe2srt/internal/timecode"
+	"scribe2srt/internal/zhconv"
+)
+
 // SubtitleSettings holds all subtitle generation parameters.
 type SubtitleSettings struct {
 	MinSubtitleDuration float64
@@ -9,6 +19,82 @@ type SubtitleSettings struct {
 	LatinCPS            float64
 	CJKCharsPerLine     int
 	LatinCharsPerLine   int
+	// LanguageLimits lists the limits above that follow the language
+	// registry: ForLanguage replaces them with the defaults of the
+	// transcript language.
+	LanguageLimits LanguageLimits
+
+	// WidthMode selects how line length and reading speed are measured.
+	WidthMode textwidth.Mode
+	// RTLMode selects the directional marks written around right-to-left lines.
+	RTLMode bidi.Mode
+
+	// FrameRate enables frame-accurate timing when non-zero. The frame
+	// counts override MinSubtitleGap and MinSubtitleDuration when set; cues
+	// less than ChainFrames apart are closed up to exactly the minimum gap.
+	FrameRate         timecode.Rate
+	MinGapFrames      int
+	MinDurationFrames int
+	ChainFrames       int
+
+	// ShotChanges holds the sorted shot-change times, in seconds, detected in
+	// the input video. Cue boundaries within ShotSnapWindow seconds of a cut
+	// are moved onto it.
+	ShotChanges    []float64
+	ShotSnapWindow float64
+
+	// AudioOverlap decides what happens to audio events that overlap speech.
+	AudioOverlap AudioOverlap
+	// AudioEvents filters, relabels and attaches audio events; nil keeps
+	// them as recognised.
+	AudioEvents *AudioEventPolicy
+	// Glossary corrects recurring recognition errors before sentence
+	// splitting; nil disables it.
+	Glossary *glossary.Glossary
+
+	// CleanVerbatim removes filler words and collapses stutters and
+	// repetitions. Fillers replaces the built-in filler list of each language
+	// it has an entry for, keyed by ISO 639-1 code.
+	CleanVerbatim bool
+	Fillers       map[string][]string
+
+	// Profanity masks offensive words; nil disables it.
+	Profanity *profanity.Filter
+
+	// ZhConverter converts Chinese transcripts to a script and regional
+	// variant before layout; nil keeps the recognised characters.
+	ZhConverter *zhconv.Converter
+
+	// Translation translates the merged cues; nil disables it.
+	Translation *Translation
+
+	// Format is the subtitle file format written. Karaoke adds the timing
+	// of each word inside the cues of the formats that support it.
+	Format  OutputFormat
+	Karaoke bool
+	// LRCEvents decides whether audio events appear in LRC output.
+	LRCEvents LRCEvents
+	// TTMLTiming selects the time expressions of TTML and DFXP output.
+	TTMLTiming subtitle.TTMLTiming
+	// STL holds the header fields and display options of EBU STL output.
+	STL STLSettings
+
+	// Title and Artist describe the programme for the formats that carry
+	// metadata. They default to the tags of the input file.
+	Title  string
+	Artist string
+}
+
+// MaxRowChars returns the width of a row of the output format, or 0 for
+// formats without fixed-width rows. No line may be longer.
+func (s *SubtitleSettings) MaxRowChars() int {
+	switch s.Format {
+	case FormatSTL:
+		return s.STL.Row()
+	case FormatSCC:
+		return subtitle.SCCColumns
+	}
+	return 0
 }
 
 // Config holds the full application configuration.
@@ -19,6 +105,13 @@ type Config struct {
 	MaxConcurrentChunks int
 	MaxRetries          int
 	APIRateLimitPerMin  int
+	// ShotThreshold is the ffmpeg scene score (0-1) above which a frame is
+	// treated as a shot change.
+	ShotThreshold float64
+	// TranslateContext and TranslateBatch are the defaults for
+	// Translation.Context and Translation.BatchSize.
+	TranslateContext int
+	TranslateBatch   int
 }
 
 // Default returns a Config with hardcoded defaults matching the Python version.
@@ -32,10 +125,17 @@ func Default() *Config {
 			LatinCPS:            15,
 			CJKCharsPerLine:     25,
 			LatinCharsPerLine:   42,
+			MinGapFrames:        2,
+			ChainFrames:         12,
+			ShotSnapWindow:      0.5,
+			STL:                 STLSettings{CodePage: 850},
 		},
 		SplitDurationMin:    90,
 		MaxConcurrentChunks: 3,
 		MaxRetries:            3,
 		APIRateLimitPerMin:    30,
+		ShotThreshold:       0.4,
+		TranslateContext:    2,
+		TranslateBatch:      20,
 	}
 }
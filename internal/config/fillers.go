@@ -0,0 +1,35 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"scribe2srt/internal/lang"
+)
+
+// LoadFillers reads per-language filler word lists for clean verbatim from a
+// JSON file such as {"en": ["um", "uh"], "ja": ["えーと"]}. Languages may be
+// given by any code the registry accepts; the result is keyed by the
+// shortest code.
+func LoadFillers(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read filler list: %w", err)
+	}
+
+	var raw map[string][]string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse filler list %s: %w", path, err)
+	}
+
+	fillers := make(map[string][]string, len(raw))
+	for code, list := range raw {
+		l, ok := lang.Lookup(code)
+		if !ok {
+			return nil, fmt.Errorf("filler list %s: unknown language %q", path, code)
+		}
+		fillers[l.Code()] = append(fillers[l.Code()], list...)
+	}
+	return fillers, nil
+}
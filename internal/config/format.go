@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFormat selects the subtitle file format the pipeline writes.
+type OutputFormat int
+
+const (
+	// FormatSRT writes SubRip subtitles.
+	FormatSRT OutputFormat = iota
+	// FormatASS writes an Advanced SubStation Alpha script.
+	FormatASS
+	// FormatVTT writes WebVTT.
+	FormatVTT
+	// FormatLRC writes LRC lyrics; with karaoke, enhanced LRC.
+	FormatLRC
+	// FormatTTML writes an IMSC1 Text profile TTML document.
+	FormatTTML
+	// FormatDFXP writes a TTML1 DFXP document for legacy systems.
+	FormatDFXP
+	// FormatSTL writes a binary EBU STL (Tech 3264) file.
+	FormatSTL
+	// FormatSCC writes Scenarist SCC (CEA-608 line-21) pop-on captions.
+	FormatSCC
+)
+
+// ParseOutputFormat parses a --format value.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "srt":
+		return FormatSRT, nil
+	case "ass":
+		return FormatASS, nil
+	case "vtt", "webvtt":
+		return FormatVTT, nil
+	case "lrc":
+		return FormatLRC, nil
+	case "ttml", "imsc", "imsc1":
+		return FormatTTML, nil
+	case "dfxp":
+		return FormatDFXP, nil
+	case "stl", "ebu-stl":
+		return FormatSTL, nil
+	case "scc":
+		return FormatSCC, nil
+	}
+	return FormatSRT, fmt.Errorf("unknown output format %q (want srt, ass, vtt, lrc, ttml, dfxp, stl or scc)", s)
+}
+
+func (f OutputFormat) String() string {
+	switch f {
+	case FormatASS:
+		return "ass"
+	case FormatVTT:
+		return "vtt"
+	case FormatLRC:
+		return "lrc"
+	case FormatTTML:
+		return "ttml"
+	case FormatDFXP:
+		return "dfxp"
+	case FormatSTL:
+		return "stl"
+	case FormatSCC:
+		return "scc"
+	default:
+		return "srt"
+	}
+}
+
+// Extension returns the file extension of the format, with the dot.
+func (f OutputFormat) Extension() string {
+	return "." + f.String()
+}
+
+// LRCEvents selects how audio events appear in LRC lyrics, which have no
+// room for captions such as [laughter].
+type LRCEvents int
+
+const (
+	// LRCDropEvents leaves audio events out.
+	LRCDropEvents LRCEvents = iota
+	// LRCBreakMarkers shows each audio event as an instrumental-break line.
+	LRCBreakMarkers
+)
+
+// ParseLRCEvents parses an --lrc-events value.
+func ParseLRCEvents(s string) (LRCEvents, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "drop":
+		return LRCDropEvents, nil
+	case "marker":
+		return LRCBreakMarkers, nil
+	}
+	return LRCDropEvents, fmt.Errorf("unknown LRC event handling %q (want drop or marker)", s)
+}
+
+func (e LRCEvents) String() string {
+	if e == LRCBreakMarkers {
+		return "marker"
+	}
+	return "drop"
+}
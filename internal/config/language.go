@@ -1,36 +1,52 @@
 package config
 
-// CJK language codes (first 3 chars of the code).
-var cjkCodes = map[string]bool{
-	"zho": true,
-	"jpn": true,
-	"kor": true,
-	"chi": true,
-	"zh":  true,
-	"ja":  true,
-	"ko":  true,
-}
+import "scribe2srt/internal/lang"
 
 // IsCJK returns true if the language code represents Chinese, Japanese, or Korean.
+// Both ISO 639-1 and ISO 639-3 codes are accepted, with or without a region
+// subtag.
 func IsCJK(langCode string) bool {
-	if len(langCode) > 3 {
-		langCode = langCode[:3]
-	}
-	return cjkCodes[langCode]
+	l, ok := lang.Lookup(langCode)
+	return ok && l.IsCJK()
 }
 
-// CPSForLang returns the default CPS limit for the given language.
-func CPSForLang(langCode string) float64 {
-	if IsCJK(langCode) {
-		return 11
-	}
-	return 15
-}
+// LanguageLimits is a set of the CPS and CPL limits of SubtitleSettings.
+type LanguageLimits uint8
 
-// CPLForLang returns the default characters-per-line limit for the given language.
-func CPLForLang(langCode string) int {
-	if IsCJK(langCode) {
-		return 25
+const (
+	LatinCPSLimit LanguageLimits = 1 << iota
+	LatinCPLLimit
+	CJKCPSLimit
+	CJKCPLLimit
+
+	AllLimits = LatinCPSLimit | LatinCPLLimit | CJKCPSLimit | CJKCPLLimit
+)
+
+// ForLanguage returns the settings with the limits in s.LanguageLimits for
+// the script of langCode replaced by the registry defaults of the language,
+// so that Thai, for example, is laid out at its own CPS and CPL rather than
+// the general Latin ones. s is returned unchanged when there is nothing to
+// replace.
+func (s *SubtitleSettings) ForLanguage(langCode string) *SubtitleSettings {
+	l, ok := lang.Lookup(langCode)
+	if !ok || s.LanguageLimits == 0 {
+		return s
+	}
+	out := *s
+	if l.IsCJK() {
+		if s.LanguageLimits&CJKCPSLimit != 0 {
+			out.CJKCPS = l.CPS
+		}
+		if s.LanguageLimits&CJKCPLLimit != 0 {
+			out.CJKCharsPerLine = l.CPL
+		}
+	} else {
+		if s.LanguageLimits&LatinCPSLimit != 0 {
+			out.LatinCPS = l.CPS
+		}
+		if s.LanguageLimits&LatinCPLLimit != 0 {
+			out.LatinCharsPerLine = l.CPL
+		}
 	}
-	return 42
+	return &out
 }
@@ -0,0 +1,26 @@
+package config
+
+import "testing"
+
+func TestForLanguage(t *testing.T) {
+	settings := &Default().SubtitleSettings
+	if settings.ForLanguage("th") != settings {
+		t.Error("settings without LanguageLimits were changed")
+	}
+
+	settings.LanguageLimits = AllLimits &^ LatinCPLLimit
+	settings.LatinCharsPerLine = 30
+	th := settings.ForLanguage("th")
+	if th.LatinCPS != 12 || th.LatinCharsPerLine != 30 {
+		t.Errorf("Thai CPS/CPL = %g/%d, want 12 and the explicit 30", th.LatinCPS, th.LatinCharsPerLine)
+	}
+	if th.CJKCPS != 11 || th.CJKCharsPerLine != 25 {
+		t.Errorf("CJK limits changed for Thai: %g/%d", th.CJKCPS, th.CJKCharsPerLine)
+	}
+	if settings.LatinCPS != 15 {
+		t.Error("ForLanguage changed its receiver")
+	}
+	if en := settings.ForLanguage("en"); en.LatinCPS != 15 || en.LatinCharsPerLine != 30 {
+		t.Errorf("English CPS/CPL = %g/%d", en.LatinCPS, en.LatinCharsPerLine)
+	}
+}
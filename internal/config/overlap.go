@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AudioOverlap selects what happens to an audio event, such as [laughter],
+// that overlaps a speech cue.
+type AudioOverlap int
+
+const (
+	// OverlapSeparate keeps the event as a cue of its own; the timing pass
+	// moves it clear of the speech.
+	OverlapSeparate AudioOverlap = iota
+	// OverlapStack shows the event on its own line above the speech.
+	OverlapStack
+	// OverlapMerge joins the event into the speech line.
+	OverlapMerge
+	// OverlapDrop removes the event.
+	OverlapDrop
+)
+
+// ParseAudioOverlap parses an --audio-overlap value.
+func ParseAudioOverlap(s string) (AudioOverlap, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "separate":
+		return OverlapSeparate, nil
+	case "stack":
+		return OverlapStack, nil
+	case "merge":
+		return OverlapMerge, nil
+	case "drop":
+		return OverlapDrop, nil
+	}
+	return OverlapSeparate, fmt.Errorf("unknown audio overlap policy %q (want separate, drop, merge or stack)", s)
+}
+
+func (p AudioOverlap) String() string {
+	switch p {
+	case OverlapMerge:
+		return "merge"
+	case OverlapDrop:
+		return "drop"
+	case OverlapStack:
+		return "stack"
+	default:
+		return "separate"
+	}
+}
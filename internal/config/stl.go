@@ -0,0 +1,30 @@
+package config
+
+import "scribe2srt/internal/subtitle"
+
+// STLSettings are the EBU STL options not shared with other formats. The
+// programme title is SubtitleSettings.Title.
+type STLSettings struct {
+	// CodePage encodes the text fields of the GSI header.
+	CodePage subtitle.STLCodePage
+	// Charset is the character code table of the subtitle text; nil picks
+	// it from the script of the language written.
+	Charset *subtitle.STLCharset
+	Display subtitle.STLDisplay
+	// RowChars is the width of a row, usually 37 to 40 characters; lines
+	// are laid out to fit it. 0 means the usual width of Display.
+	RowChars int
+
+	EpisodeTitle string
+	Publisher    string
+	// Country is the ISO 3166 alpha-3 code of the country of origin.
+	Country string
+}
+
+// Row returns the row width in characters.
+func (s STLSettings) Row() int {
+	if s.RowChars > 0 {
+		return s.RowChars
+	}
+	return s.Display.RowChars()
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"scribe2srt/internal/translate"
+)
+
+// TranslationUnit selects what the translation stage sends as one line.
+type TranslationUnit int
+
+const (
+	// TranslateCues translates the merged cues one by one and keeps their
+	// timing.
+	TranslateCues TranslationUnit = iota
+	// TranslateSentences translates whole sentences and re-segments each
+	// translation into cues over the sentence's time span. It suits
+	// language pairs whose word order differs, such as Japanese to English.
+	TranslateSentences
+)
+
+// ParseTranslationUnit parses a --translate-unit value.
+func ParseTranslationUnit(s string) (TranslationUnit, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "cue":
+		return TranslateCues, nil
+	case "sentence":
+		return TranslateSentences, nil
+	}
+	return TranslateCues, fmt.Errorf("unknown translation unit %q (want cue or sentence)", s)
+}
+
+func (u TranslationUnit) String() string {
+	if u == TranslateSentences {
+		return "sentence"
+	}
+	return "cue"
+}
+
+// Translation configures the optional translation stage.
+type Translation struct {
+	Translator translate.Translator
+	// Target is the language code translated into.
+	Target string
+	Unit   TranslationUnit
+	// Bilingual keeps the source text above the translation instead of
+	// replacing it.
+	Bilingual bool
+	// Context is the number of neighbouring cues sent on each side of a
+	// batch, and BatchSize the number of cues translated per request.
+	Context   int
+	BatchSize int
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -17,6 +18,22 @@ import (
 type MediaInfo struct {
 	Duration float64
 	Codec    string
+	// Tags holds the container metadata, such as title and artist, with
+	// lower-case keys.
+	Tags map[string]string
+}
+
+// Tag returns the first non-empty metadata value among keys, or "".
+func (m *MediaInfo) Tag(keys ...string) string {
+	if m == nil {
+		return ""
+	}
+	for _, k := range keys {
+		if v := strings.TrimSpace(m.Tags[k]); v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // Available returns true if ffmpeg is on the PATH.
@@ -28,7 +45,8 @@ func Available() bool {
 // probeOutput mirrors ffprobe JSON structure.
 type probeOutput struct {
 	Format struct {
-		Duration string `json:"duration"`
+		Duration string            `json:"duration"`
+		Tags     map[string]string `json:"tags"`
 	} `json:"format"`
 	Streams []struct {
 		CodecName string `json:"codec_name"`
@@ -45,7 +63,7 @@ func ProbeMedia(ctx context.Context, path string) (*MediaInfo, error) {
 		"ffprobe",
 		"-v", "error",
 		"-select_streams", "a:0",
-		"-show_entries", "stream=codec_name:format=duration",
+		"-show_entries", "stream=codec_name:format=duration:format_tags",
 		"-of", "json",
 		path,
 	)
@@ -54,7 +72,11 @@ func ProbeMedia(ctx context.Context, path string) (*MediaInfo, error) {
 	if err != nil {
 		return nil, fmt.Errorf("ffprobe failed: %w", err)
 	}
+	return parseProbe(out)
+}
 
+// parseProbe reads the JSON output of ffprobe.
+func parseProbe(out []byte) (*MediaInfo, error) {
 	var probe probeOutput
 	if err := json.Unmarshal(out, &probe); err != nil {
 		return nil, fmt.Errorf("ffprobe JSON parse error: %w", err)
@@ -67,7 +89,12 @@ func ProbeMedia(ctx context.Context, path string) (*MediaInfo, error) {
 		codec = probe.Streams[0].CodecName
 	}
 
-	return &MediaInfo{Duration: dur, Codec: codec}, nil
+	tags := make(map[string]string, len(probe.Format.Tags))
+	for k, v := range probe.Format.Tags {
+		tags[strings.ToLower(k)] = v
+	}
+
+	return &MediaInfo{Duration: dur, Codec: codec, Tags: tags}, nil
 }
 
 // ExtractAudio extracts the audio stream from a video file using ffmpeg -vn -c:a copy.
@@ -122,6 +149,55 @@ func SplitAudio(ctx context.Context, audioPath string, outputDir string, segment
 	return matches, nil
 }
 
+// showinfoTime matches the frame timestamp in an ffmpeg showinfo log line.
+var showinfoTime = regexp.MustCompile(`pts_time:\s*(-?[0-9.]+)`)
+
+// DetectShots runs ffmpeg scene-change detection on a video and returns the
+// times, in seconds, of frames whose scene score exceeds threshold (0-1).
+func DetectShots(ctx context.Context, videoPath string, threshold float64) ([]float64, error) {
+	slog.Info("detecting shot changes", "input", filepath.Base(videoPath), "threshold", threshold)
+
+	filter := fmt.Sprintf("select='gt(scene,%s)',showinfo", strconv.FormatFloat(threshold, 'f', -1, 64))
+	cmd := exec.CommandContext(ctx,
+		"ffmpeg", "-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-an", "-sn",
+		"-vf", filter,
+		"-f", "null", "-",
+	)
+
+	// showinfo logs to stderr.
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg scene detection failed: %w\n%s", err, string(out))
+	}
+
+	shots := parseShowinfo(string(out))
+	slog.Info("shot changes detected", "count", len(shots))
+	return shots, nil
+}
+
+// parseShowinfo extracts the sorted frame times from showinfo log output.
+func parseShowinfo(log string) []float64 {
+	var times []float64
+	for _, line := range strings.Split(log, "\n") {
+		if !strings.Contains(line, "showinfo") {
+			continue
+		}
+		m := showinfoTime.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		t, err := strconv.ParseFloat(m[1], 64)
+		if err != nil || t < 0 {
+			continue
+		}
+		times = append(times, t)
+	}
+	sort.Float64s(times)
+	return times
+}
+
 // IsVideoExtension returns true for common video file extensions.
 func IsVideoExtension(ext string) bool {
 	switch strings.ToLower(ext) {
@@ -0,0 +1,51 @@
+package ffmpeg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseShowinfo(t *testing.T) {
+	log := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'input.mp4':
+  Duration: 00:00:30.03, start: 0.000000, bitrate: 1205 kb/s
+[Parsed_showinfo_1 @ 0x600003a4c000] config in time_base: 1/24000, frame_rate: 24000/1001
+[Parsed_showinfo_1 @ 0x600003a4c000] n:   0 pts: 125125 pts_time:5.21354 duration:   1001 duration_time:0.0417083 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x600003a4c000] n:   1 pts: 300300 pts_time:12.5125 duration:   1001 duration_time:0.0417083 fmt:yuv420p
+[Parsed_showinfo_1 @ 0x600003a4c000] n:   2 pts:  48048 pts_time:2.002   duration:   1001 duration_time:0.0417083 fmt:yuv420p
+[out#0/null @ 0x600003d48000] video:1kB audio:0kB subtitle:0kB`
+
+	got := parseShowinfo(log)
+	want := []float64{2.002, 5.21354, 12.5125}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseShowinfo() = %v, want %v", got, want)
+	}
+}
+
+func TestParseProbe(t *testing.T) {
+	out := []byte(`{
+    "programs": [],
+    "streams": [{"codec_name": "aac"}],
+    "format": {
+        "duration": "215.340000",
+        "tags": {"major_brand": "M4A ", "TITLE": "Blue Skies", "album_artist": "The Band"}
+    }
+}`)
+
+	info, err := parseProbe(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Duration != 215.34 || info.Codec != "aac" {
+		t.Errorf("info = %+v", info)
+	}
+	if got := info.Tag("title"); got != "Blue Skies" {
+		t.Errorf("title = %q", got)
+	}
+	if got := info.Tag("artist", "album_artist"); got != "The Band" {
+		t.Errorf("artist = %q", got)
+	}
+	var none *MediaInfo
+	if none.Tag("title") != "" {
+		t.Error("nil info has tags")
+	}
+}
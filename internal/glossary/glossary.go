@@ -0,0 +1,297 @@
+// Package glossary applies recurring corrections, such as names and product
+// terms the recogniser misspells, to transcript text.
+//
+// A glossary is a tab-separated file with one rule per line:
+//
+//	pattern<TAB>replacement[<TAB>flags]
+//
+// Blank lines and lines starting with # are ignored. Flags is a
+// comma-separated list:
+//
+//	case   match case-sensitively (the default ignores case)
+//	regex  pattern is a regular expression; replacement may use $1 etc.
+//
+// Literal patterns match whole words only. The word-boundary check is skipped
+// at an edge of the pattern written in a script without spaces between words
+// (Chinese, Japanese, Thai, ...), so CJK terms also match inside running
+// text. Whitespace in a literal pattern matches any run of whitespace, so a
+// rule can span several recognised words.
+package glossary
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule is one glossary entry.
+type Rule struct {
+	Pattern     string
+	Replacement string
+	// Line is the line number in the glossary file.
+	Line          int
+	Regex         bool
+	CaseSensitive bool
+
+	re *regexp.Regexp
+}
+
+// Glossary is an ordered list of rules. When matches overlap, the one that
+// starts first wins, then the longer one, then the earlier rule.
+type Glossary struct {
+	Rules []Rule
+}
+
+// Match is one substitution found in a text. Start and End are byte offsets.
+type Match struct {
+	Start       int
+	End         int
+	Original    string
+	Replacement string
+	Rule        *Rule
+}
+
+// Load reads a glossary file.
+func Load(path string) (*Glossary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open glossary: %w", err)
+	}
+	defer f.Close()
+
+	g, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("glossary %s: %w", path, err)
+	}
+	return g, nil
+}
+
+// Parse reads glossary rules from r.
+func Parse(r io.Reader) (*Glossary, error) {
+	g := &Glossary{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" || strings.HasPrefix(strings.TrimSpace(text), "#") {
+			continue
+		}
+
+		fields := strings.Split(text, "\t")
+		if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+			return nil, fmt.Errorf("line %d: want pattern<TAB>replacement[<TAB>flags]", line)
+		}
+
+		rule := Rule{Pattern: fields[0], Replacement: fields[1], Line: line}
+		if len(fields) == 3 {
+			for _, flag := range strings.Split(fields[2], ",") {
+				switch strings.ToLower(strings.TrimSpace(flag)) {
+				case "":
+				case "case":
+					rule.CaseSensitive = true
+				case "regex":
+					rule.Regex = true
+				default:
+					return nil, fmt.Errorf("line %d: unknown flag %q (want case or regex)", line, flag)
+				}
+			}
+		}
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		g.Rules = append(g.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (r *Rule) compile() error {
+	expr := r.Pattern
+	if !r.Regex {
+		words := strings.Fields(r.Pattern)
+		for i, w := range words {
+			words[i] = regexp.QuoteMeta(w)
+		}
+		expr = strings.Join(words, `\s+`)
+	}
+	if !r.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	r.re = re
+	return nil
+}
+
+// Find returns the non-overlapping substitutions to apply to text, in order.
+func (g *Glossary) Find(text string) []Match {
+	if g == nil {
+		return nil
+	}
+
+	var candidates []Match
+	for i := range g.Rules {
+		rule := &g.Rules[i]
+		for _, loc := range rule.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if start == end || !rule.Regex && !isWholeWord(text, start, end) {
+				continue
+			}
+			original := text[start:end]
+			candidates = append(candidates, Match{
+				Start:       start,
+				End:         end,
+				Original:    original,
+				Replacement: rule.replacement(text, original, loc),
+				Rule:        rule,
+			})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.End-a.Start > b.End-b.Start
+	})
+
+	var matches []Match
+	end := 0
+	for _, m := range candidates {
+		if m.Start < end {
+			continue
+		}
+		matches = append(matches, m)
+		end = m.End
+	}
+	return matches
+}
+
+// Replace applies the glossary to text and returns the result.
+func (g *Glossary) Replace(text string) string {
+	matches := g.Find(text)
+	if len(matches) == 0 {
+		return text
+	}
+	var sb strings.Builder
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(text[last:m.Start])
+		sb.WriteString(m.Replacement)
+		last = m.End
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
+
+// Terms returns the replacement texts of the literal rules, the spellings the
+// glossary corrects towards, without duplicates.
+func (g *Glossary) Terms() []string {
+	if g == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var terms []string
+	for _, r := range g.Rules {
+		t := strings.TrimSpace(r.Replacement)
+		if r.Regex || t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// replacement returns the text that replaces original. Regex rules expand
+// submatch references. Case-insensitive literal rules follow the case of the
+// matched text: an all-capitals match gives an all-capitals replacement, and
+// a capitalised match capitalises a lower-case replacement.
+func (r *Rule) replacement(text, original string, loc []int) string {
+	if r.Regex {
+		return string(r.re.ExpandString(nil, r.Replacement, text, loc))
+	}
+	if r.CaseSensitive {
+		return r.Replacement
+	}
+	switch {
+	case isAllUpper(original):
+		return strings.ToUpper(r.Replacement)
+	case startsUpper(original) && !hasUpper(r.Replacement):
+		first, size := utf8.DecodeRuneInString(r.Replacement)
+		return string(unicode.ToUpper(first)) + r.Replacement[size:]
+	}
+	return r.Replacement
+}
+
+// isWholeWord reports whether text[start:end] is not part of a longer word.
+// Edges written in a script without spaces are always boundaries.
+func isWholeWord(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(text[start:])
+	if start > 0 && isWordRune(first) && !isScriptless(first) {
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		if isWordRune(before) && !isScriptless(before) {
+			return false
+		}
+	}
+	last, _ := utf8.DecodeLastRuneInString(text[:end])
+	if end < len(text) && isWordRune(last) && !isScriptless(last) {
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if isWordRune(after) && !isScriptless(after) {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
+
+// isScriptless reports whether r belongs to a script written without spaces
+// between words.
+func isScriptless(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana,
+		unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
+
+// isAllUpper reports whether s has at least two letters and all of them are
+// upper case.
+func isAllUpper(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			return false
+		}
+		letters++
+	}
+	return letters >= 2
+}
+
+func startsUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return unicode.IsUpper(r)
+		}
+	}
+	return false
+}
+
+func hasUpper(s string) bool {
+	return strings.IndexFunc(s, unicode.IsUpper) >= 0
+}
@@ -0,0 +1,101 @@
+package glossary
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, src string) *Glossary {
+	t.Helper()
+	g, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return g
+}
+
+func TestParse(t *testing.T) {
+	g := mustParse(t, "# names\n\nopen ai\tOpenAI\nfoo\tbar\tcase,regex\n")
+	if len(g.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(g.Rules))
+	}
+	if r := g.Rules[1]; !r.CaseSensitive || !r.Regex || r.Line != 4 {
+		t.Errorf("second rule = %+v, want case and regex flags on line 4", r)
+	}
+
+	for _, src := range []string{
+		"no tab here\n",
+		"a\tb\tshout\n",
+		"(\tx\tregex\n",
+	} {
+		if _, err := Parse(strings.NewReader(src)); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", src)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	g := mustParse(t, strings.Join([]string{
+		"open ai\tOpenAI",
+		"colour\tcolor",
+		"cat\tdog",
+		"Go\tGolang\tcase",
+		`(\d+) percent` + "\t$1%\tregex",
+		"\u4e09\u661f\t\u5409\u5229", // 三星 → 吉利
+	}, "\n"))
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"We use open  ai tools.", "We use OpenAI tools."},
+		{"Colour and COLOUR and colour.", "Color and COLOR and color."},
+		{"The cat sat on the concatenation.", "The dog sat on the concatenation."},
+		{"Go, go, GO!", "Golang, go, GO!"},
+		{"Up 15 percent.", "Up 15%."},
+		{"\u6211\u7684\u4e09\u661f\u624b\u6a5f", "\u6211\u7684\u5409\u5229\u624b\u6a5f"}, // 我的三星手機 → 我的吉利手機
+	}
+
+	for _, tt := range tests {
+		if got := g.Replace(tt.in); got != tt.want {
+			t.Errorf("Replace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestReplace_RegexCase(t *testing.T) {
+	g := mustParse(t, strings.Join([]string{
+		"chat ?gpt\tChatGPT\tregex",
+		`(\d+) pct` + "\t$1%\tregex,case",
+	}, "\n"))
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Ask Chat GPT or chatgpt.", "Ask ChatGPT or ChatGPT."},
+		{"Up 15 pct, not 15 PCT.", "Up 15%, not 15 PCT."},
+	}
+	for _, tt := range tests {
+		if got := g.Replace(tt.in); got != tt.want {
+			t.Errorf("Replace(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFind_LongestMatchWins(t *testing.T) {
+	g := mustParse(t, "new york\tNew York\nnew york city\tNYC\n")
+	matches := g.Find("in new york city today")
+	if len(matches) != 1 || matches[0].Replacement != "NYC" {
+		t.Errorf("Find() = %+v, want the longer rule", matches)
+	}
+}
+
+func TestTerms(t *testing.T) {
+	g := mustParse(t, "open ai\tOpenAI\nopenai\tOpenAI\n(\\d+) pct\t$1%\tregex\num\t\njon\tJohn\n")
+	got := g.Terms()
+	want := []string{"OpenAI", "John"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Terms() = %v, want %v", got, want)
+	}
+}
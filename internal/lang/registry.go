@@ -0,0 +1,304 @@
+// Package lang is the registry of languages accepted by the speech-to-text
+// model, with the layout defaults the subtitle pipeline needs for each.
+package lang
+
+import (
+	"sort"
+	"strings"
+)
+
+// Script identifies the writing system a language is subtitled in.
+type Script string
+
+const (
+	Latin      Script = "Latin"
+	Cyrillic   Script = "Cyrillic"
+	Greek      Script = "Greek"
+	Armenian   Script = "Armenian"
+	Georgian   Script = "Georgian"
+	Arabic     Script = "Arabic"
+	Hebrew     Script = "Hebrew"
+	Devanagari Script = "Devanagari"
+	Bengali    Script = "Bengali"
+	Gurmukhi   Script = "Gurmukhi"
+	Gujarati   Script = "Gujarati"
+	Oriya      Script = "Oriya"
+	Tamil      Script = "Tamil"
+	Telugu     Script = "Telugu"
+	Kannada    Script = "Kannada"
+	Malayalam  Script = "Malayalam"
+	Ethiopic   Script = "Ethiopic"
+	Thai       Script = "Thai"
+	Lao        Script = "Lao"
+	Khmer      Script = "Khmer"
+	Myanmar    Script = "Myanmar"
+	Han        Script = "Han"
+	Japanese   Script = "Japanese"
+	Hangul     Script = "Hangul"
+)
+
+// Default layout limits, matching config.Default.
+const (
+	defaultCPS    = 15
+	defaultCPL    = 42
+	cjkCPS        = 11
+	cjkCPL        = 25
+	southeastCPS  = 12
+	southeastCPL  = 35
+	latinFinal    = ".!?"
+	cjkFinal      = "\u3002\uff01\uff1f" // 。！？
+	arabicFinal   = ".!\u061f"           // .!؟
+	urduFinal     = "\u06d4!\u061f"      // ۔!؟
+	indicFinal    = "\u0964!?"           // ।!?
+	ethiopicFinal = "\u1362!?"           // ።!?
+	armenianFinal = "\u0589\u055c\u055e" // ։՜՞
+	greekFinal    = ".!;"                // Greek uses ; as its question mark
+	burmeseFinal  = "\u104b"             // ။
+	khmerFinal    = "\u17d4\u17d5"       // ។៕
+)
+
+// Language describes one language supported by the speech-to-text model.
+type Language struct {
+	// Code1 is the ISO 639-1 code; empty when the language has none.
+	Code1 string
+	// Code3 is the ISO 639-3 code.
+	Code3 string
+	// Aliases are further codes accepted for the language, such as the
+	// ISO 639-2/B bibliographic forms.
+	Aliases []string
+	Name    string
+	Script  Script
+	// SpaceDelimited is false for scripts written without spaces between
+	// words.
+	SpaceDelimited bool
+	// CPS and CPL are the default reading speed and line length limits.
+	CPS float64
+	CPL int
+	// SentenceFinal lists the punctuation that ends a sentence.
+	SentenceFinal string
+	// RTL is true for right-to-left scripts.
+	RTL bool
+}
+
+// Code returns the shortest code for the language.
+func (l Language) Code() string {
+	if l.Code1 != "" {
+		return l.Code1
+	}
+	return l.Code3
+}
+
+// IsCJK reports whether the language is Chinese, Japanese or Korean.
+func (l Language) IsCJK() bool {
+	switch l.Script {
+	case Han, Japanese, Hangul:
+		return true
+	}
+	return false
+}
+
+// latin builds a space-delimited Latin-script language with default limits.
+func latin(code1, code3, name string) Language {
+	return Language{
+		Code1: code1, Code3: code3, Name: name, Script: Latin,
+		SpaceDelimited: true, CPS: defaultCPS, CPL: defaultCPL, SentenceFinal: latinFinal,
+	}
+}
+
+// other builds a space-delimited language in a non-Latin script.
+func other(code1, code3, name string, script Script, final string) Language {
+	l := latin(code1, code3, name)
+	l.Script = script
+	l.SentenceFinal = final
+	return l
+}
+
+// rtl builds a right-to-left language.
+func rtl(code1, code3, name string, script Script, final string) Language {
+	l := other(code1, code3, name, script, final)
+	l.RTL = true
+	return l
+}
+
+// cjk builds a Chinese, Japanese or Korean language.
+func cjk(code1, code3, name string, script Script) Language {
+	return Language{
+		Code1: code1, Code3: code3, Name: name, Script: script,
+		SpaceDelimited: script == Hangul, CPS: cjkCPS, CPL: cjkCPL, SentenceFinal: cjkFinal,
+	}
+}
+
+// southeast builds a Southeast Asian language written without spaces between
+// words.
+func southeast(code1, code3, name string, script Script, final string) Language {
+	return Language{
+		Code1: code1, Code3: code3, Name: name, Script: script,
+		CPS: southeastCPS, CPL: southeastCPL, SentenceFinal: final,
+	}
+}
+
+func withAliases(l Language, aliases ...string) Language {
+	l.Aliases = aliases
+	return l
+}
+
+// registry lists every language the speech-to-text model accepts.
+var registry = []Language{
+	latin("af", "afr", "Afrikaans"),
+	other("am", "amh", "Amharic", Ethiopic, ethiopicFinal),
+	rtl("ar", "ara", "Arabic", Arabic, arabicFinal),
+	withAliases(other("hy", "hye", "Armenian", Armenian, armenianFinal), "arm"),
+	other("as", "asm", "Assamese", Bengali, indicFinal),
+	latin("", "ast", "Asturian"),
+	latin("az", "aze", "Azerbaijani"),
+	other("be", "bel", "Belarusian", Cyrillic, latinFinal),
+	other("bn", "ben", "Bengali", Bengali, indicFinal),
+	latin("bs", "bos", "Bosnian"),
+	other("bg", "bul", "Bulgarian", Cyrillic, latinFinal),
+	withAliases(southeast("my", "mya", "Burmese", Myanmar, burmeseFinal), "bur"),
+	cjk("", "yue", "Cantonese", Han),
+	latin("ca", "cat", "Catalan"),
+	latin("", "ceb", "Cebuano"),
+	latin("ny", "nya", "Chichewa"),
+	withAliases(cjk("zh", "zho", "Chinese", Han), "chi", "cmn"),
+	latin("hr", "hrv", "Croatian"),
+	withAliases(latin("cs", "ces", "Czech"), "cze"),
+	latin("da", "dan", "Danish"),
+	withAliases(latin("nl", "nld", "Dutch"), "dut"),
+	latin("en", "eng", "English"),
+	latin("et", "est", "Estonian"),
+	latin("", "fil", "Filipino"),
+	latin("fi", "fin", "Finnish"),
+	withAliases(latin("fr", "fra", "French"), "fre"),
+	latin("ff", "ful", "Fulah"),
+	latin("gl", "glg", "Galician"),
+	latin("lg", "lug", "Ganda"),
+	withAliases(other("ka", "kat", "Georgian", Georgian, latinFinal), "geo"),
+	withAliases(latin("de", "deu", "German"), "ger"),
+	withAliases(other("el", "ell", "Greek", Greek, greekFinal), "gre"),
+	other("gu", "guj", "Gujarati", Gujarati, indicFinal),
+	latin("ha", "hau", "Hausa"),
+	rtl("he", "heb", "Hebrew", Hebrew, latinFinal),
+	other("hi", "hin", "Hindi", Devanagari, indicFinal),
+	latin("hu", "hun", "Hungarian"),
+	withAliases(latin("is", "isl", "Icelandic"), "ice"),
+	latin("ig", "ibo", "Igbo"),
+	latin("id", "ind", "Indonesian"),
+	latin("ga", "gle", "Irish"),
+	latin("it", "ita", "Italian"),
+	cjk("ja", "jpn", "Japanese", Japanese),
+	latin("jv", "jav", "Javanese"),
+	latin("", "kea", "Kabuverdianu"),
+	other("kn", "kan", "Kannada", Kannada, latinFinal),
+	other("kk", "kaz", "Kazakh", Cyrillic, latinFinal),
+	southeast("km", "khm", "Khmer", Khmer, khmerFinal),
+	cjk("ko", "kor", "Korean", Hangul),
+	latin("ku", "kur", "Kurdish"),
+	other("ky", "kir", "Kyrgyz", Cyrillic, latinFinal),
+	southeast("lo", "lao", "Lao", Lao, ""),
+	latin("lv", "lav", "Latvian"),
+	latin("ln", "lin", "Lingala"),
+	latin("lt", "lit", "Lithuanian"),
+	latin("", "luo", "Luo"),
+	latin("lb", "ltz", "Luxembourgish"),
+	withAliases(other("mk", "mkd", "Macedonian", Cyrillic, latinFinal), "mac"),
+	withAliases(latin("ms", "msa", "Malay"), "may"),
+	other("ml", "mal", "Malayalam", Malayalam, latinFinal),
+	latin("mt", "mlt", "Maltese"),
+	withAliases(latin("mi", "mri", "Maori"), "mao"),
+	other("mr", "mar", "Marathi", Devanagari, indicFinal),
+	other("mn", "mon", "Mongolian", Cyrillic, latinFinal),
+	other("ne", "nep", "Nepali", Devanagari, indicFinal),
+	latin("", "nso", "Northern Sotho"),
+	withAliases(latin("no", "nor", "Norwegian"), "nob", "nno"),
+	latin("oc", "oci", "Occitan"),
+	other("or", "ori", "Odia", Oriya, indicFinal),
+	rtl("ps", "pus", "Pashto", Arabic, arabicFinal),
+	withAliases(rtl("fa", "fas", "Persian", Arabic, arabicFinal), "per"),
+	latin("pl", "pol", "Polish"),
+	latin("pt", "por", "Portuguese"),
+	other("pa", "pan", "Punjabi", Gurmukhi, indicFinal),
+	withAliases(latin("ro", "ron", "Romanian"), "rum"),
+	other("ru", "rus", "Russian", Cyrillic, latinFinal),
+	other("sr", "srp", "Serbian", Cyrillic, latinFinal),
+	latin("sn", "sna", "Shona"),
+	rtl("sd", "snd", "Sindhi", Arabic, arabicFinal),
+	withAliases(latin("sk", "slk", "Slovak"), "slo"),
+	latin("sl", "slv", "Slovenian"),
+	latin("so", "som", "Somali"),
+	latin("es", "spa", "Spanish"),
+	latin("sw", "swa", "Swahili"),
+	latin("sv", "swe", "Swedish"),
+	other("ta", "tam", "Tamil", Tamil, latinFinal),
+	other("tg", "tgk", "Tajik", Cyrillic, latinFinal),
+	other("te", "tel", "Telugu", Telugu, latinFinal),
+	southeast("th", "tha", "Thai", Thai, ""),
+	latin("tr", "tur", "Turkish"),
+	other("uk", "ukr", "Ukrainian", Cyrillic, latinFinal),
+	latin("", "umb", "Umbundu"),
+	rtl("ur", "urd", "Urdu", Arabic, urduFinal),
+	latin("uz", "uzb", "Uzbek"),
+	latin("vi", "vie", "Vietnamese"),
+	withAliases(latin("cy", "cym", "Welsh"), "wel"),
+	latin("wo", "wol", "Wolof"),
+	latin("xh", "xho", "Xhosa"),
+	latin("zu", "zul", "Zulu"),
+}
+
+// byCode indexes the registry by every accepted code.
+var byCode map[string]Language
+
+func init() {
+	byCode = make(map[string]Language, len(registry)*2)
+	for _, l := range registry {
+		if l.Code1 != "" {
+			byCode[l.Code1] = l
+		}
+		byCode[l.Code3] = l
+		for _, alias := range l.Aliases {
+			byCode[alias] = l
+		}
+	}
+}
+
+// Normalize lower-cases a language code and strips any region or script
+// subtag, so "zh-TW" and "pt_BR" become "zh" and "pt".
+func Normalize(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
+
+// Lookup finds a language by ISO 639-1, ISO 639-3 or alias code.
+func Lookup(code string) (Language, bool) {
+	l, ok := byCode[Normalize(code)]
+	return l, ok
+}
+
+// BCP47 returns the shortest language tag for code, as used by xml:lang: the
+// ISO 639-1 code when the language has one, else the ISO 639-3 code.
+// Unknown codes are returned normalized.
+func BCP47(code string) string {
+	if l, ok := Lookup(code); ok {
+		return l.Code()
+	}
+	return Normalize(code)
+}
+
+// IsAuto reports whether code asks the model to detect the language.
+func IsAuto(code string) bool {
+	code = Normalize(code)
+	return code == "" || code == "auto"
+}
+
+// All returns every registered language, sorted by name.
+func All() []Language {
+	all := make([]Language, len(registry))
+	copy(all, registry)
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+	return all
+}
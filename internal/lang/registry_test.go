@@ -0,0 +1,97 @@
+package lang
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		code     string
+		wantCode string
+		wantOK   bool
+	}{
+		{"ja", "jpn", true},
+		{"jpn", "jpn", true},
+		{"JA", "jpn", true},
+		{"zh-TW", "zho", true},
+		{"pt_BR", "por", true},
+		{"chi", "zho", true},
+		{"yue", "yue", true},
+		{"fil", "fil", true},
+		{"xx", "", false},
+		{"auto", "", false},
+	}
+
+	for _, tt := range tests {
+		l, ok := Lookup(tt.code)
+		if ok != tt.wantOK {
+			t.Errorf("Lookup(%q) ok = %v, want %v", tt.code, ok, tt.wantOK)
+			continue
+		}
+		if l.Code3 != tt.wantCode {
+			t.Errorf("Lookup(%q).Code3 = %q, want %q", tt.code, l.Code3, tt.wantCode)
+		}
+	}
+}
+
+func TestLanguageProperties(t *testing.T) {
+	ja, _ := Lookup("ja")
+	if !ja.IsCJK() || ja.SpaceDelimited {
+		t.Errorf("ja: IsCJK = %v, SpaceDelimited = %v", ja.IsCJK(), ja.SpaceDelimited)
+	}
+
+	ko, _ := Lookup("ko")
+	if !ko.IsCJK() || !ko.SpaceDelimited {
+		t.Errorf("ko: IsCJK = %v, SpaceDelimited = %v", ko.IsCJK(), ko.SpaceDelimited)
+	}
+
+	ar, _ := Lookup("ar")
+	if !ar.RTL || ar.Script != Arabic {
+		t.Errorf("ar: RTL = %v, Script = %v", ar.RTL, ar.Script)
+	}
+
+	th, _ := Lookup("th")
+	if th.SpaceDelimited || th.IsCJK() {
+		t.Errorf("th: SpaceDelimited = %v, IsCJK = %v", th.SpaceDelimited, th.IsCJK())
+	}
+}
+
+func TestRegistryCodesUnique(t *testing.T) {
+	seen := make(map[string]string)
+	for _, l := range registry {
+		codes := append([]string{l.Code1, l.Code3}, l.Aliases...)
+		for _, c := range codes {
+			if c == "" {
+				continue
+			}
+			if prev, ok := seen[c]; ok {
+				t.Errorf("code %q used by both %s and %s", c, prev, l.Name)
+			}
+			seen[c] = l.Name
+		}
+	}
+}
+
+func TestIsAuto(t *testing.T) {
+	for _, code := range []string{"", "auto", "AUTO"} {
+		if !IsAuto(code) {
+			t.Errorf("IsAuto(%q) = false, want true", code)
+		}
+	}
+	if IsAuto("en") {
+		t.Error("IsAuto(\"en\") = true, want false")
+	}
+}
+
+func TestBCP47(t *testing.T) {
+	tests := map[string]string{
+		"eng":   "en",
+		"ja":    "ja",
+		"yue":   "yue",
+		"zh-TW": "zh",
+		"xx-YY": "xx",
+	}
+	for in, want := range tests {
+		if got := BCP47(in); got != want {
+			t.Errorf("BCP47(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
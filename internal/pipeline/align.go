@@ -0,0 +1,381 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ScriptAlignment summarises how a reference script was aligned to the
+// recognised words.
+type ScriptAlignment struct {
+	// Matched script tokens were recognised exactly; Substituted ones were
+	// aligned to a differently recognised token; Inserted ones have no
+	// recognised counterpart and were given interpolated times. Deleted
+	// counts recognised tokens that are not in the script.
+	Matched     int
+	Substituted int
+	Inserted    int
+	Deleted     int
+}
+
+// alignToken is one unit of the alignment: a word, or a single character
+// of a script written without spaces.
+type alignToken struct {
+	Text  string
+	Norm  string
+	runes []rune
+	Start float64
+	End   float64
+	// Source is the index of the recognised word the token came from, or
+	// was aligned to; -1 when it has none.
+	Source int
+}
+
+// Alignment costs. A substitution of two unrelated tokens costs as much as
+// an insertion plus a deletion, so a misrecognised word is kept in place
+// rather than skipped.
+const (
+	alignGapCost = 1.0
+	alignSubCost = 2.0
+	// alignBand is the minimum half-width of the band of the alignment
+	// matrix that is searched around its diagonal.
+	alignBand = 100
+)
+
+// normalizeAlignToken lower-cases text and removes punctuation and symbols,
+// so "Hello," matches "hello".
+func normalizeAlignToken(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// scriptTokens tokenizes a reference script. Punctuation-only tokens, such
+// as a standalone full stop, are attached to the token before them.
+func scriptTokens(script string) []alignToken {
+	var tokens []alignToken
+	for _, text := range tokenizeTranslation(script) {
+		norm := normalizeAlignToken(text)
+		if norm == "" && len(tokens) > 0 {
+			tokens[len(tokens)-1].Text += text
+			continue
+		}
+		tokens = append(tokens, alignToken{Text: text, Norm: norm, runes: []rune(norm), Source: -1})
+	}
+	return tokens
+}
+
+// recognisedTokens splits recognised words into alignment tokens, timing
+// the characters of words without spaces by their share of the word.
+func recognisedTokens(words []Word) []alignToken {
+	var tokens []alignToken
+	for i, w := range words {
+		if w.Type != "word" {
+			continue
+		}
+		for _, part := range distributeText(w.Text, w.Start, w.End) {
+			norm := normalizeAlignToken(part.Text)
+			if norm == "" {
+				continue
+			}
+			tokens = append(tokens, alignToken{Text: part.Text, Norm: norm, runes: []rune(norm), Start: part.Start, End: part.End, Source: i})
+		}
+	}
+	return tokens
+}
+
+// similarity scores pairs of tokens, reusing its buffers between calls.
+type similarity struct {
+	prev, curr []int
+}
+
+// score returns 1 for equal tokens, falling to 0 as their edit distance
+// approaches the length of the longer one.
+func (s *similarity) score(a, b []rune) float64 {
+	longer := max(len(a), len(b))
+	if longer == 0 {
+		return 1
+	}
+	if cap(s.prev) < len(b)+1 {
+		s.prev = make([]int, len(b)+1)
+		s.curr = make([]int, len(b)+1)
+	}
+	prev, curr := s.prev[:len(b)+1], s.curr[:len(b)+1]
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(b)])/float64(longer)
+}
+
+// Backtracking steps of the alignment.
+const (
+	stepDiag uint8 = iota // match or substitution
+	stepUp                // script token inserted
+	stepLeft              // recognised token deleted
+)
+
+// alignSequences aligns script to recognised tokens with a banded edit
+// distance and returns, for every script token, the index of the recognised
+// token it is aligned to, or -1.
+func alignSequences(script, recognised []alignToken) []int {
+	n, m := len(script), len(recognised)
+	band := alignBand + (n+m)/100
+	var sim similarity
+
+	// Row i covers columns lo[i]..hi[i] around the scaled diagonal.
+	lo := make([]int, n+1)
+	hi := make([]int, n+1)
+	for i := 0; i <= n; i++ {
+		center := 0
+		if n > 0 {
+			center = i * m / n
+		}
+		lo[i] = max(center-band, 0)
+		hi[i] = min(center+band, m)
+	}
+
+	inf := math.Inf(1)
+	cost := func(row []float64, i, j int) float64 {
+		if j < lo[i] || j > hi[i] {
+			return inf
+		}
+		return row[j-lo[i]]
+	}
+
+	steps := make([][]uint8, n+1)
+	prev := make([]float64, hi[0]-lo[0]+1)
+	steps[0] = make([]uint8, len(prev))
+	for j := range prev {
+		prev[j] = float64(lo[0]+j) * alignGapCost
+		steps[0][j] = stepLeft
+	}
+
+	for i := 1; i <= n; i++ {
+		row := make([]float64, hi[i]-lo[i]+1)
+		steps[i] = make([]uint8, len(row))
+		for j := lo[i]; j <= hi[i]; j++ {
+			best, step := cost(prev, i-1, j)+alignGapCost, stepUp
+			if j > 0 {
+				sub := 0.0
+				if script[i-1].Norm != recognised[j-1].Norm {
+					sub = alignSubCost * (1 - sim.score(script[i-1].runes, recognised[j-1].runes))
+				}
+				if c := cost(prev, i-1, j-1) + sub; c <= best {
+					best, step = c, stepDiag
+				}
+				if j > lo[i] {
+					if c := row[j-1-lo[i]] + alignGapCost; c < best {
+						best, step = c, stepLeft
+					}
+				}
+			}
+			row[j-lo[i]] = best
+			steps[i][j-lo[i]] = step
+		}
+		prev = row
+	}
+
+	aligned := make([]int, n)
+	for i := range aligned {
+		aligned[i] = -1
+	}
+	i, j := n, m
+	if j > hi[n] {
+		j = hi[n]
+	}
+	for i > 0 || j > 0 {
+		switch {
+		case i == 0:
+			j--
+		case j < lo[i] || j > hi[i]:
+			i--
+		default:
+			switch steps[i][j-lo[i]] {
+			case stepDiag:
+				aligned[i-1] = j - 1
+				i, j = i-1, j-1
+			case stepUp:
+				i--
+			default:
+				j--
+			}
+		}
+	}
+	return aligned
+}
+
+// AlignScript replaces the recognised words of transcript with the words
+// of a reference script, timed by aligning the script to the recognised
+// words. Script words aligned to a recognised word take its timing, even if
+// it was misrecognised; the others are spread by character share over the
+// time between their aligned neighbours. Audio events are kept.
+func AlignScript(transcript *TranscriptResponse, script string) (*TranscriptResponse, ScriptAlignment, error) {
+	var stats ScriptAlignment
+
+	tokens := scriptTokens(script)
+	if len(tokens) == 0 {
+		return nil, stats, fmt.Errorf("script is empty")
+	}
+	recognised := recognisedTokens(transcript.Words)
+	if len(recognised) == 0 {
+		return nil, stats, fmt.Errorf("no words were recognised to align the script to")
+	}
+
+	aligned := alignSequences(tokens, recognised)
+	used := 0
+	for i, j := range aligned {
+		if j < 0 {
+			stats.Inserted++
+			continue
+		}
+		used++
+		r := recognised[j]
+		tokens[i].Start, tokens[i].End, tokens[i].Source = r.Start, r.End, r.Source
+		if tokens[i].Norm == r.Norm {
+			stats.Matched++
+		} else {
+			stats.Substituted++
+		}
+	}
+	stats.Deleted = len(recognised) - used
+	if used == 0 {
+		return nil, stats, fmt.Errorf("the script could not be aligned to the recognised words")
+	}
+
+	interpolateUnaligned(tokens, aligned, transcript.Words)
+
+	result := &TranscriptResponse{
+		LanguageCode: transcript.LanguageCode,
+		Text:         strings.TrimSpace(script),
+	}
+	result.Words = mergeScriptTokens(tokens, transcript.Words)
+	for _, w := range transcript.Words {
+		if w.Type == "audio_event" {
+			result.Words = append(result.Words, w)
+		}
+	}
+	sort.SliceStable(result.Words, func(i, j int) bool {
+		return result.Words[i].Start < result.Words[j].Start
+	})
+	return result, stats, nil
+}
+
+// interpolateUnaligned times each run of unaligned tokens over the gap
+// between the aligned tokens around it. Runs at the start or end of the
+// script get the average time per character of the aligned tokens.
+func interpolateUnaligned(tokens []alignToken, aligned []int, words []Word) {
+	var alignedChars int
+	var alignedTime float64
+	for i, j := range aligned {
+		if j >= 0 {
+			alignedChars += tokenChars(tokens[i])
+			alignedTime += tokens[i].End - tokens[i].Start
+		}
+	}
+	perChar := alignedTime / float64(max(alignedChars, 1))
+
+	var mediaEnd float64
+	for _, w := range words {
+		mediaEnd = max(mediaEnd, w.End)
+	}
+
+	for i := 0; i < len(tokens); {
+		if aligned[i] >= 0 {
+			i++
+			continue
+		}
+		k := i
+		chars := 0
+		for k < len(tokens) && aligned[k] < 0 {
+			chars += tokenChars(tokens[k])
+			k++
+		}
+		estimate := float64(chars) * perChar
+
+		var start, end float64
+		switch {
+		case i > 0 && k < len(tokens):
+			start, end = tokens[i-1].End, tokens[k].Start
+		case k < len(tokens):
+			end = tokens[k].Start
+			start = max(end-estimate, 0)
+		default:
+			start = tokens[i-1].End
+			end = min(start+estimate, max(mediaEnd, start))
+		}
+		if end < start {
+			end = start
+		}
+
+		done := 0
+		for t := i; t < k; t++ {
+			tokens[t].Start = start + (end-start)*float64(done)/float64(chars)
+			done += tokenChars(tokens[t])
+			tokens[t].End = start + (end-start)*float64(done)/float64(chars)
+			if t > 0 {
+				tokens[t].Source = tokens[t-1].Source
+			}
+		}
+		i = k
+	}
+}
+
+// mergeScriptTokens turns tokens into words, keeping the speaker of the
+// recognised word each was aligned to. Consecutive characters of a script
+// without spaces that belong to the same recognised word are joined again,
+// so line breaking still sees the recogniser's word boundaries.
+func mergeScriptTokens(tokens []alignToken, recognised []Word) []Word {
+	words := make([]Word, 0, len(tokens))
+	var prevSource int
+	for i, t := range tokens {
+		if i > 0 && t.Source >= 0 && t.Source == prevSource {
+			last := &words[len(words)-1]
+			if !strings.HasSuffix(last.Text, " ") && startsWithNoSpaceRune(t.Text) && endsWithNoSpaceRune(last.Text) {
+				last.Text += t.Text
+				last.End = max(last.End, t.End)
+				continue
+			}
+		}
+		w := Word{Text: t.Text, Start: t.Start, End: t.End, Type: "word"}
+		if t.Source >= 0 {
+			w.SpeakerID = recognised[t.Source].SpeakerID
+		}
+		words = append(words, w)
+		prevSource = t.Source
+	}
+	return words
+}
+
+// tokenChars is the number of characters a token counts for when time is
+// shared by character.
+func tokenChars(t alignToken) int {
+	return max(utf8.RuneCountInString(t.Norm), 1)
+}
+
+func startsWithNoSpaceRune(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return isNoSpaceRune(r)
+}
+
+func endsWithNoSpaceRune(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimRight(s, " "))
+	return isNoSpaceRune(r)
+}
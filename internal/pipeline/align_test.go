@@ -0,0 +1,141 @@
+package pipeline
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// recognised builds a transcript of one-second words separated by spacing.
+func recognised(lang string, texts ...string) *TranscriptResponse {
+	t := &TranscriptResponse{LanguageCode: lang}
+	for i, text := range texts {
+		start := float64(i)
+		if i > 0 {
+			t.Words = append(t.Words, Word{Text: " ", Start: start - 0.1, End: start, Type: "spacing"})
+		}
+		t.Words = append(t.Words, Word{Text: text, Start: start, End: start + 0.9, Type: "word"})
+	}
+	return t
+}
+
+func TestAlignScript_KeepsTimingOfMisrecognisedWords(t *testing.T) {
+	transcript := recognised("en", "the", "colour", "of", "magick", "is", "read")
+	script := "The color of magic is red."
+
+	got, stats, err := AlignScript(transcript, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if joined := strings.TrimSpace(joinWords(got.Words)); joined != script {
+		t.Errorf("text = %q, want %q", joined, script)
+	}
+	if len(got.Words) != 6 {
+		t.Fatalf("got %d words, want 6: %+v", len(got.Words), got.Words)
+	}
+	for i, w := range got.Words {
+		if w.Start != float64(i) {
+			t.Errorf("word %q starts at %g, want %d", w.Text, w.Start, i)
+		}
+	}
+	if stats.Matched != 3 || stats.Substituted != 3 || stats.Inserted != 0 || stats.Deleted != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAlignScript_InterpolatesMissingWords(t *testing.T) {
+	transcript := recognised("en", "one", "two", "five", "six")
+	// "three four" were not recognised: "two" ends at 1.9 and "five"
+	// starts at 4.
+	transcript.Words[len(transcript.Words)-3].Start = 4
+	transcript.Words[len(transcript.Words)-3].End = 4.9
+	transcript.Words[len(transcript.Words)-1].Start = 5
+	transcript.Words[len(transcript.Words)-1].End = 5.9
+
+	got, stats, err := AlignScript(transcript, "one two three four five six")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Inserted != 2 || stats.Matched != 4 {
+		t.Errorf("stats = %+v", stats)
+	}
+	three, four := got.Words[2], got.Words[3]
+	if three.Text != "three " || four.Text != "four " {
+		t.Fatalf("words = %+v", got.Words)
+	}
+	// The gap from 1.9 to 4 is shared by character count: 5 and 4.
+	if math.Abs(three.Start-1.9) > 1e-9 || math.Abs(four.End-4) > 1e-9 {
+		t.Errorf("interpolated span = %g..%g, want 1.9..4", three.Start, four.End)
+	}
+	if math.Abs(three.End-(1.9+2.1*5/9)) > 1e-9 || three.End != four.Start {
+		t.Errorf("split at %g/%g", three.End, four.Start)
+	}
+}
+
+func TestAlignScript_SkipsUnscriptedWords(t *testing.T) {
+	transcript := recognised("en", "so", "um", "hello", "there")
+	got, stats, err := AlignScript(transcript, "So, hello there.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Deleted != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got.Words[1].Text != "hello " || got.Words[1].Start != 2 {
+		t.Errorf("words = %+v", got.Words)
+	}
+}
+
+func TestAlignScript_CharactersOfNoSpaceScripts(t *testing.T) {
+	// 今日は / いい / 電気 (笑): "天気" was misrecognised as "電気".
+	transcript := &TranscriptResponse{LanguageCode: "ja", Words: []Word{
+		{Text: "\u4eca\u65e5\u306f", Start: 0, End: 0.6, Type: "word"},
+		{Text: "\u3044\u3044", Start: 0.6, End: 1, Type: "word"},
+		{Text: "\u96fb\u6c17", Start: 1, End: 1.5, Type: "word"},
+		{Text: "(\u7b11)", Start: 2, End: 3, Type: "audio_event"},
+	}}
+	// 今日はいい天気。
+	script := "\u4eca\u65e5\u306f\u3044\u3044\u5929\u6c17\u3002"
+
+	got, _, err := AlignScript(transcript, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 今日は, いい, 天気。, (笑)
+	want := []string{"\u4eca\u65e5\u306f", "\u3044\u3044", "\u5929\u6c17\u3002", "(\u7b11)"}
+	if len(got.Words) != len(want) {
+		t.Fatalf("words = %+v", got.Words)
+	}
+	for i, w := range got.Words {
+		if w.Text != want[i] {
+			t.Errorf("word %d = %q, want %q", i, w.Text, want[i])
+		}
+	}
+	if got.Words[2].Start != 1 || got.Words[2].End != 1.5 {
+		t.Errorf("substituted word timed %g..%g, want 1..1.5", got.Words[2].Start, got.Words[2].End)
+	}
+	if got.Words[3].Type != "audio_event" {
+		t.Error("audio event was dropped")
+	}
+}
+
+func TestAlignScript_Errors(t *testing.T) {
+	if _, _, err := AlignScript(recognised("en", "hello"), " \n "); err == nil {
+		t.Error("empty script: want error")
+	}
+	if _, _, err := AlignScript(&TranscriptResponse{}, "hello"); err == nil {
+		t.Error("no recognised words: want error")
+	}
+}
+
+func TestAlignScript_RunsThroughPipeline(t *testing.T) {
+	transcript := recognised("en", "hello", "word", "how", "are", "you")
+	aligned, _, err := AlignScript(transcript, "Hello world. How are you?")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srt := Process(aligned, defaultSettings())
+	if !strings.Contains(srt, "Hello world.") || !strings.Contains(srt, "How are you?") {
+		t.Errorf("srt = %q", srt)
+	}
+}
@@ -0,0 +1,176 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/textwidth"
+)
+
+// DualOptions configures CombineDual.
+type DualOptions struct {
+	// MinOverlap is the share of the shorter of two cues that must overlap
+	// the other for them to be paired. Small overlaps from drifting timings
+	// are ignored.
+	MinOverlap float64
+	// CPLA and CPLB are the line lengths of each file's text; 0 chooses the
+	// CJK or Latin limit of settings from the dominant script of each cue.
+	CPLA, CPLB int
+}
+
+// DualCue is one combined cue. A and B hold the laid-out lines of each
+// file's text; either may be empty when a cue has no counterpart.
+type DualCue struct {
+	Start float64
+	End   float64
+	A     []string
+	B     []string
+}
+
+// CombineDual aligns the cues of two subtitle files by time overlap and
+// combines them into dual-language cues. One cue of a may be paired with
+// several of b, or several of a with one of b, but pairing is not
+// transitive: pairs are made from the largest overlap down, and a pair that
+// would put more than one cue of each file into a combined cue is skipped.
+// Files whose boundaries are offset therefore give cues of one or two
+// lines per language rather than a single cue running through them all.
+func CombineDual(a, b []subtitle.Cue, settings *config.SubtitleSettings, opts DualOptions) []DualCue {
+	// Union-find over the cues of a (0..len(a)-1) and b (len(a)...),
+	// counting the cues of each file in every group.
+	parent := make([]int, len(a)+len(b))
+	countA := make([]int, len(parent))
+	countB := make([]int, len(parent))
+	for i := range parent {
+		parent[i] = i
+		if i < len(a) {
+			countA[i] = 1
+		} else {
+			countB[i] = 1
+		}
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	type pair struct {
+		i, j    int
+		overlap float64
+	}
+	var pairs []pair
+	for i, ca := range a {
+		for j, cb := range b {
+			if cb.Start >= ca.End || cb.End <= ca.Start {
+				continue
+			}
+			overlap := min(ca.End, cb.End) - max(ca.Start, cb.Start)
+			shorter := min(ca.End-ca.Start, cb.End-cb.Start)
+			if shorter <= 0 || overlap >= opts.MinOverlap*shorter {
+				pairs = append(pairs, pair{i, len(a) + j, overlap})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(x, y int) bool { return pairs[x].overlap > pairs[y].overlap })
+	for _, p := range pairs {
+		ra, rb := find(p.i), find(p.j)
+		if ra == rb {
+			continue
+		}
+		na, nb := countA[ra]+countA[rb], countB[ra]+countB[rb]
+		if na > 1 && nb > 1 {
+			continue
+		}
+		parent[rb] = ra
+		countA[ra], countB[ra] = na, nb
+	}
+
+	type group struct {
+		start, end float64
+		a, b       []string
+	}
+	groups := map[int]*group{}
+	var order []*group
+	add := func(root int, c subtitle.Cue, isA bool) {
+		g, ok := groups[root]
+		if !ok {
+			g = &group{start: c.Start, end: c.End}
+			groups[root] = g
+			order = append(order, g)
+		}
+		g.start, g.end = min(g.start, c.Start), max(g.end, c.End)
+		text := strings.Join(strings.Fields(c.Text), " ")
+		if isA {
+			g.a = append(g.a, text)
+		} else {
+			g.b = append(g.b, text)
+		}
+	}
+	for i, c := range a {
+		add(find(i), c, true)
+	}
+	for j, c := range b {
+		add(find(len(a)+j), c, false)
+	}
+	sort.SliceStable(order, func(i, j int) bool { return order[i].start < order[j].start })
+
+	limits := newScriptLimits(settings, false)
+	layout := func(texts []string, cpl int) []string {
+		if len(texts) == 0 {
+			return nil
+		}
+		text := texts[0]
+		for _, t := range texts[1:] {
+			text = joinTexts(text, t)
+		}
+		if cpl <= 0 {
+			cpl = limits.cpl(text)
+		}
+		return wrapLines(text, cpl, settings.WidthMode)
+	}
+
+	cues := make([]DualCue, 0, len(order))
+	for i, g := range order {
+		// Combined cues may still overlap through pairs below MinOverlap;
+		// end each one a minimum gap before the next.
+		end := g.end
+		if i+1 < len(order) {
+			if next := order[i+1].start - settings.MinSubtitleGap; end > next {
+				end = max(next, g.start)
+			}
+		}
+		cues = append(cues, DualCue{
+			Start: g.start,
+			End:   end,
+			A:     layout(g.a, opts.CPLA),
+			B:     layout(g.b, opts.CPLB),
+		})
+	}
+	return cues
+}
+
+// wrapLines breaks text into as many lines as needed to keep each within
+// maxCPL, using the same break rules as the two-line layout.
+func wrapLines(text string, maxCPL int, mode textwidth.Mode) []string {
+	var lines []string
+	remaining := strings.TrimSpace(text)
+	for remaining != "" {
+		if mode.Width(remaining) <= maxCPL {
+			lines = append(lines, remaining)
+			break
+		}
+		runes := []rune(remaining)
+		pos := findSplitPosition(remaining, maxCPL, mode)
+		if pos <= 0 || pos >= len(runes) {
+			lines = append(lines, remaining)
+			break
+		}
+		lines = append(lines, strings.TrimSpace(string(runes[:pos])))
+		remaining = strings.TrimSpace(string(runes[pos:]))
+	}
+	return lines
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/textwidth"
+)
+
+func TestCombineDual_OneToOneWithDrift(t *testing.T) {
+	a := []subtitle.Cue{
+		{Start: 0, End: 2, Text: "Hello."},
+		{Start: 2.1, End: 4, Text: "How are you?"},
+	}
+	b := []subtitle.Cue{
+		{Start: 0.2, End: 2.3, Text: "Bonjour."},
+		{Start: 2.4, End: 4.2, Text: "Comment vas-tu ?"},
+	}
+	cues := CombineDual(a, b, defaultSettings(), DualOptions{MinOverlap: 0.3})
+	if len(cues) != 2 {
+		t.Fatalf("got %d cues, want 2: %+v", len(cues), cues)
+	}
+	if cues[0].Start != 0 || cues[0].End != 2.1-0.083 {
+		t.Errorf("first cue spans %g-%g, want it to end a minimum gap before the second", cues[0].Start, cues[0].End)
+	}
+	if cues[1].A[0] != "How are you?" || cues[1].B[0] != "Comment vas-tu ?" {
+		t.Errorf("second cue = %+v", cues[1])
+	}
+}
+
+func TestCombineDual_OneToMany(t *testing.T) {
+	a := []subtitle.Cue{{Start: 0, End: 4, Text: "A long line that the vendor split in two."}}
+	b := []subtitle.Cue{
+		{Start: 0, End: 2, Text: "Une longue ligne"},
+		{Start: 2, End: 4, Text: "coup\u00e9e en deux."},
+	}
+	cues := CombineDual(a, b, defaultSettings(), DualOptions{MinOverlap: 0.3})
+	if len(cues) != 1 {
+		t.Fatalf("got %d cues, want 1", len(cues))
+	}
+	if got := strings.Join(cues[0].B, " "); got != "Une longue ligne coup\u00e9e en deux." {
+		t.Errorf("B = %q", cues[0].B)
+	}
+}
+
+func TestCombineDual_Unpaired(t *testing.T) {
+	a := []subtitle.Cue{{Start: 0, End: 1, Text: "Only in A"}}
+	b := []subtitle.Cue{{Start: 5, End: 6, Text: "Only in B"}}
+	cues := CombineDual(a, b, defaultSettings(), DualOptions{MinOverlap: 0.3})
+	if len(cues) != 2 || cues[0].B != nil || cues[1].A != nil {
+		t.Errorf("cues = %+v", cues)
+	}
+}
+
+func TestCombineDual_PerLanguageCPL(t *testing.T) {
+	// 今日はとても良い天気ですね、散歩に行きましょう。
+	a := []subtitle.Cue{{Start: 0, End: 4, Text: "\u4eca\u65e5\u306f\u3068\u3066\u3082\u826f\u3044\u5929\u6c17\u3067\u3059\u306d\u3001\u6563\u6b69\u306b\u884c\u304d\u307e\u3057\u3087\u3046\u3002"}}
+	b := []subtitle.Cue{{Start: 0, End: 4, Text: "The weather is lovely today, let's go for a walk."}}
+
+	cues := CombineDual(a, b, defaultSettings(), DualOptions{MinOverlap: 0.3, CPLA: 12, CPLB: 20})
+	for _, line := range cues[0].A {
+		// A closing full stop may hang past the limit (burasagari).
+		if textwidth.Runes.Width(strings.TrimSuffix(line, "\u3002")) > 12 { // 。
+			t.Errorf("A line longer than 12: %q", line)
+		}
+	}
+	for _, line := range cues[0].B {
+		if len(line) > 20 {
+			t.Errorf("B line longer than 20: %q", line)
+		}
+	}
+	if len(cues[0].B) < 3 {
+		t.Errorf("expected B wrapped onto at least 3 lines, got %q", cues[0].B)
+	}
+}
+
+func TestCombineDual_HalfOffset(t *testing.T) {
+	// Ten cues in each file, the second file's boundaries half a cue later.
+	var a, b []subtitle.Cue
+	for i := range 10 {
+		start := float64(2 * i)
+		a = append(a, subtitle.Cue{Start: start, End: start + 2, Text: fmt.Sprintf("a%d", i)})
+		b = append(b, subtitle.Cue{Start: start + 1, End: start + 3, Text: fmt.Sprintf("b%d", i)})
+	}
+	settings := defaultSettings()
+	cues := CombineDual(a, b, settings, DualOptions{MinOverlap: 0.3})
+	if len(cues) < 5 {
+		t.Fatalf("got %d cues, want the files kept apart: %+v", len(cues), cues)
+	}
+	seen := 0
+	for _, c := range cues {
+		if c.End-c.Start > settings.MaxSubtitleDuration {
+			t.Errorf("cue spans %g-%g", c.Start, c.End)
+		}
+		if len(c.A) > 2 || len(c.B) > 2 {
+			t.Errorf("cue %+v has more than two lines per file", c)
+		}
+		seen += len(strings.Fields(strings.Join(c.A, " "))) + len(strings.Fields(strings.Join(c.B, " ")))
+	}
+	if seen != 20 {
+		t.Errorf("%d of the 20 cues shown", seen)
+	}
+}
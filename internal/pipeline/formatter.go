@@ -4,7 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
-	"unicode/utf8"
+
+	"scribe2srt/internal/textwidth"
 )
 
 // formatSRTTime converts seconds to SRT time format HH:MM:SS,mmm.
@@ -20,27 +21,43 @@ func formatSRTTime(seconds float64) string {
 
 // optimizeTextDisplay returns text on a single line if it fits within maxCPL,
 // otherwise splits it into at most two lines.
-func optimizeTextDisplay(text string, maxCPL int) string {
+func optimizeTextDisplay(text string, maxCPL int, mode textwidth.Mode) string {
 	text = strings.TrimSpace(text)
 	if text == "" {
 		return text
 	}
-	if utf8.RuneCountInString(text) <= maxCPL {
+	if mode.Width(text) <= maxCPL {
 		return text
 	}
-	return splitTextIntoLines(text, maxCPL)
+	return splitTextIntoLines(text, maxCPL, mode)
+}
+
+// optimizeEntryDisplay lays out the text of entry like optimizeTextDisplay,
+// additionally allowing line breaks at the boundaries between its words.
+func optimizeEntryDisplay(entry SubtitleEntry, maxCPL int, mode textwidth.Mode) string {
+	text := strings.TrimSpace(entry.Text)
+	if text == "" || mode.Width(text) <= maxCPL {
+		return text
+	}
+	return splitTextIntoLinesAt(text, maxCPL, mode, wordBoundaries(text, entry.Words))
 }
 
 // splitTextIntoLines splits text into a maximum of two lines using
 // findSplitPosition for intelligent break points.
-func splitTextIntoLines(text string, maxCPL int) string {
+func splitTextIntoLines(text string, maxCPL int, mode textwidth.Mode) string {
+	return splitTextIntoLinesAt(strings.TrimSpace(text), maxCPL, mode, nil)
+}
+
+// splitTextIntoLinesAt is splitTextIntoLines with word boundary offsets as
+// extra break candidates; see findSplitPositionAt.
+func splitTextIntoLinesAt(text string, maxCPL int, mode textwidth.Mode, wordBreaks []int) string {
 	text = strings.TrimSpace(text)
 	runes := []rune(text)
-	if len(runes) <= maxCPL {
+	if mode.Width(text) <= maxCPL {
 		return text
 	}
 
-	splitPos := findSplitPosition(text, maxCPL)
+	splitPos := findSplitPositionAt(text, maxCPL, mode, wordBreaks)
 
 	firstLine := strings.TrimSpace(string(runes[:splitPos]))
 	remaining := strings.TrimSpace(string(runes[splitPos:]))
@@ -2,6 +2,8 @@ package pipeline
 
 import (
 	"testing"
+
+	"scribe2srt/internal/textwidth"
 )
 
 func TestFormatSRTTime(t *testing.T) {
@@ -28,14 +30,14 @@ func TestFormatSRTTime(t *testing.T) {
 
 func TestOptimizeTextDisplay_ShortText(t *testing.T) {
 	// Text shorter than maxCPL should be returned as-is.
-	result := optimizeTextDisplay("Hello world", 42)
+	result := optimizeTextDisplay("Hello world", 42, textwidth.Runes)
 	if result != "Hello world" {
 		t.Errorf("got %q, want 'Hello world'", result)
 	}
 }
 
 func TestOptimizeTextDisplay_Empty(t *testing.T) {
-	result := optimizeTextDisplay("", 42)
+	result := optimizeTextDisplay("", 42, textwidth.Runes)
 	if result != "" {
 		t.Errorf("got %q, want empty string", result)
 	}
@@ -43,7 +45,7 @@ func TestOptimizeTextDisplay_Empty(t *testing.T) {
 
 func TestOptimizeTextDisplay_LongText(t *testing.T) {
 	text := "This is a very long subtitle text that definitely exceeds the maximum characters per line limit"
-	result := optimizeTextDisplay(text, 42)
+	result := optimizeTextDisplay(text, 42, textwidth.Runes)
 
 	// Should contain a newline for line splitting.
 	if len(result) == 0 {
@@ -62,7 +64,7 @@ func TestOptimizeTextDisplay_LongText(t *testing.T) {
 }
 
 func TestSplitTextIntoLines_ShortText(t *testing.T) {
-	result := splitTextIntoLines("Hello", 42)
+	result := splitTextIntoLines("Hello", 42, textwidth.Runes)
 	if result != "Hello" {
 		t.Errorf("got %q, want 'Hello'", result)
 	}
@@ -71,7 +73,7 @@ func TestSplitTextIntoLines_ShortText(t *testing.T) {
 func TestSplitTextIntoLines_SplitsAtSpace(t *testing.T) {
 	// "Hello world foo bar baz" with maxCPL=12 should split around position 12.
 	text := "Hello world foo bar baz"
-	result := splitTextIntoLines(text, 12)
+	result := splitTextIntoLines(text, 12, textwidth.Runes)
 
 	if result == text {
 		t.Error("expected text to be split, but got original")
@@ -0,0 +1,74 @@
+package pipeline
+
+import "scribe2srt/internal/timecode"
+
+// frameRules returns the minimum gap, minimum duration and chaining threshold
+// in frames. Unset frame counts are derived from the second-based settings,
+// rounded up to whole frames.
+func (m *IntelligentMerger) frameRules() (gap, minDuration, chain int64) {
+	gap = int64(m.MinGapFrames)
+	if gap <= 0 {
+		gap = m.FrameRate.CeilFrames(m.MinSubtitleGap)
+	}
+	minDuration = int64(m.MinDurationFrames)
+	if minDuration <= 0 {
+		minDuration = m.FrameRate.CeilFrames(m.MinSubtitleDuration)
+	}
+	chain = max(int64(m.ChainFrames), gap)
+	return gap, minDuration, chain
+}
+
+// snapToFrames moves every cue boundary onto a frame boundary of
+// m.FrameRate and applies the frame-based timing rules: cues last at least
+// the minimum duration, and a cue followed by another less than the chaining
+// threshold away ends exactly the minimum gap before it. When the gap and the
+// minimum duration conflict, the minimum duration wins, as in
+// OptimizeMergedEntries.
+func (m *IntelligentMerger) snapToFrames(entries []SubtitleEntry) []SubtitleEntry {
+	rate := m.FrameRate
+	gap, minDuration, chain := m.frameRules()
+	maxDuration := rate.ToFrames(m.MaxSubtitleDuration)
+
+	starts := make([]int64, len(entries))
+	ends := make([]int64, len(entries))
+	for i, e := range entries {
+		starts[i] = rate.ToFrames(e.Start)
+		ends[i] = max(rate.ToFrames(e.End), starts[i]+minDuration)
+	}
+
+	for i := 0; i+1 < len(entries); i++ {
+		next := starts[i+1]
+		if next-ends[i] >= chain {
+			continue
+		}
+		end := max(next-gap, starts[i]+minDuration)
+		if end > ends[i] {
+			// Chaining only closes small gaps; it never stretches a cue
+			// beyond the maximum duration.
+			end = min(end, max(ends[i], starts[i]+maxDuration))
+		}
+		ends[i] = end
+	}
+
+	snapped := make([]SubtitleEntry, len(entries))
+	for i, e := range entries {
+		e.Start = rate.ToSeconds(starts[i])
+		e.End = rate.ToSeconds(ends[i])
+		snapped[i] = e
+	}
+	return snapped
+}
+
+// snapEntries rounds the start and end of each entry to the nearest frame,
+// keeping every entry at least one frame long.
+func snapEntries(entries []SubtitleEntry, rate timecode.Rate) {
+	if rate.IsZero() {
+		return
+	}
+	for i := range entries {
+		start := rate.ToFrames(entries[i].Start)
+		end := max(rate.ToFrames(entries[i].End), start+1)
+		entries[i].Start = rate.ToSeconds(start)
+		entries[i].End = rate.ToSeconds(end)
+	}
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"math"
+	"testing"
+
+	"scribe2srt/internal/timecode"
+)
+
+func frameMerger(rate timecode.Rate) *IntelligentMerger {
+	m := defaultMerger()
+	m.FrameRate = rate
+	m.MinGapFrames = 2
+	m.ChainFrames = 12
+	return m
+}
+
+func assertOnFrame(t *testing.T, rate timecode.Rate, seconds float64) {
+	t.Helper()
+	frames := seconds * rate.FPS()
+	if math.Abs(frames-math.Round(frames)) > 1e-6 {
+		t.Errorf("%v is not on a frame boundary at %s fps", seconds, rate)
+	}
+}
+
+func TestSnapToFrames_Boundaries(t *testing.T) {
+	rate := timecode.Rate23976
+	m := frameMerger(rate)
+	entries := []SubtitleEntry{
+		{Text: "One", Start: 0.013, End: 1.517},
+		{Text: "Two", Start: 5.001, End: 7.29},
+	}
+
+	got := m.snapToFrames(entries)
+	for _, e := range got {
+		assertOnFrame(t, rate, e.Start)
+		assertOnFrame(t, rate, e.End)
+	}
+}
+
+func TestSnapToFrames_ChainsSmallGaps(t *testing.T) {
+	rate := timecode.Rate25
+	m := frameMerger(rate)
+	entries := []SubtitleEntry{
+		{Text: "One", Start: 0, End: 1.0},
+		{Text: "Two", Start: 1.2, End: 2.4}, // 5 frames after the first
+		{Text: "Three", Start: 5, End: 6},   // well apart
+	}
+
+	got := m.snapToFrames(entries)
+	if gap := rate.ToFrames(got[1].Start) - rate.ToFrames(got[0].End); gap != 2 {
+		t.Errorf("gap between chained cues = %d frames, want 2", gap)
+	}
+	if got[1].End != 2.4 {
+		t.Errorf("cue followed by a large gap should keep its end, got %v", got[1].End)
+	}
+}
+
+func TestSnapToFrames_MinimumDuration(t *testing.T) {
+	rate := timecode.Rate25
+	m := frameMerger(rate)
+	m.MinDurationFrames = 20
+	entries := []SubtitleEntry{
+		{Text: "Hi", Start: 1, End: 1.2},
+	}
+
+	got := m.snapToFrames(entries)
+	if frames := rate.ToFrames(got[0].End) - rate.ToFrames(got[0].Start); frames != 20 {
+		t.Errorf("duration = %d frames, want 20", frames)
+	}
+}
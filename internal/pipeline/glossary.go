@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"sort"
+	"strings"
+
+	"scribe2srt/internal/glossary"
+)
+
+// Substitution records one glossary replacement applied to the transcript.
+type Substitution struct {
+	Start       float64
+	End         float64
+	Original    string
+	Replacement string
+	// Line is the glossary line of the rule that matched.
+	Line int
+}
+
+// applyGlossary runs the glossary over the transcript text formed by words
+// and rewrites the words a match touches. A match spanning several words
+// collapses them into one word that starts with the first and ends with the
+// last, so the corrected term keeps the timing of everything it replaced.
+func applyGlossary(words []Word, g *glossary.Glossary) ([]Word, []Substitution) {
+	if g == nil || len(words) == 0 {
+		return words, nil
+	}
+
+	text, offsets := joinWordText(words)
+	matches := g.Find(text)
+	if len(matches) == 0 {
+		return words, nil
+	}
+
+	spans := make([]textSpan, len(matches))
+	subs := make([]Substitution, len(matches))
+	for i, m := range matches {
+		spans[i] = textSpan{Start: m.Start, End: m.End, Replacement: m.Replacement}
+		subs[i] = Substitution{
+			Start:       words[wordAt(offsets, m.Start)].Start,
+			End:         words[wordAt(offsets, m.End-1)].End,
+			Original:    m.Original,
+			Replacement: m.Replacement,
+			Line:        m.Rule.Line,
+		}
+	}
+	return rewriteWords(words, text, offsets, spans), subs
+}
+
+// textSpan replaces the bytes Start to End of the text joined from words.
+type textSpan struct {
+	Start       int
+	End         int
+	Replacement string
+}
+
+// joinWordText returns the transcript text formed by words and the byte
+// offset of each word in it; offsets[len(words)] is the length of the text.
+func joinWordText(words []Word) (string, []int) {
+	offsets := make([]int, len(words)+1)
+	var sb strings.Builder
+	for i, w := range words {
+		offsets[i] = sb.Len()
+		sb.WriteString(w.Text)
+	}
+	offsets[len(words)] = sb.Len()
+	return sb.String(), offsets
+}
+
+// wordAt returns the index of the word containing byte offset pos.
+func wordAt(offsets []int, pos int) int {
+	return sort.Search(len(offsets)-1, func(i int) bool { return offsets[i+1] > pos })
+}
+
+// rewriteWords applies spans, sorted and not overlapping, to the text
+// joined from words and rewrites the words they touch. A span covering
+// several words collapses them into one word that starts with the first and
+// ends with the last, so the new text keeps the timing of everything it
+// replaced.
+func rewriteWords(words []Word, text string, offsets []int, spans []textSpan) []Word {
+	result := make([]Word, 0, len(words))
+	next := 0
+	for i := 0; i < len(spans); {
+		// Group the spans that touch the same words.
+		first, last := wordAt(offsets, spans[i].Start), wordAt(offsets, spans[i].End-1)
+		j := i + 1
+		for j < len(spans) && wordAt(offsets, spans[j].Start) <= last {
+			last = max(last, wordAt(offsets, spans[j].End-1))
+			j++
+		}
+
+		result = append(result, words[next:first]...)
+
+		var rewritten strings.Builder
+		pos := offsets[first]
+		for _, sp := range spans[i:j] {
+			rewritten.WriteString(text[pos:sp.Start])
+			rewritten.WriteString(sp.Replacement)
+			pos = sp.End
+		}
+		rewritten.WriteString(text[pos:offsets[last+1]])
+
+		w := words[first]
+		w.Text = rewritten.String()
+		w.End = words[last].End
+		if strings.TrimSpace(w.Text) != "" {
+			result = append(result, w)
+		}
+		next = last + 1
+		i = j
+	}
+	return append(result, words[next:]...)
+}
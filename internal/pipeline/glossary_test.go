@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/glossary"
+)
+
+func testGlossary(t *testing.T, src string) *glossary.Glossary {
+	t.Helper()
+	g, err := glossary.Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("glossary.Parse() error = %v", err)
+	}
+	return g
+}
+
+func TestApplyGlossary_MultiWord(t *testing.T) {
+	g := testGlossary(t, "open ai\tOpenAI\n")
+	words := []Word{
+		{Text: "We ", Start: 0, End: 0.2, Type: "word"},
+		{Text: "love ", Start: 0.2, End: 0.5, Type: "word"},
+		{Text: "open ", Start: 0.5, End: 0.8, Type: "word"},
+		{Text: "ai.", Start: 0.8, End: 1.2, Type: "word"},
+	}
+
+	got, subs := applyGlossary(words, g)
+	if len(got) != 3 {
+		t.Fatalf("got %d words, want 3: %+v", len(got), got)
+	}
+	if got[2].Text != "OpenAI." || got[2].Start != 0.5 || got[2].End != 1.2 {
+		t.Errorf("merged word = %+v, want OpenAI. from 0.5 to 1.2", got[2])
+	}
+	if len(subs) != 1 || subs[0].Original != "open ai" || subs[0].Start != 0.5 || subs[0].End != 1.2 {
+		t.Errorf("substitutions = %+v", subs)
+	}
+}
+
+func TestApplyGlossary_SeveralMatchesInOneWord(t *testing.T) {
+	// 三星 → 吉利, 手機 → 電話
+	g := testGlossary(t, "\u4e09\u661f\t\u5409\u5229\n\u624b\u6a5f\t\u96fb\u8a71\n")
+	words := []Word{
+		{Text: "\u4e09\u661f\u624b\u6a5f", Start: 0, End: 1, Type: "word"}, // 三星手機
+		{Text: "\u3002", Start: 1, End: 1.1, Type: "word"},                 // 。
+	}
+
+	got, subs := applyGlossary(words, g)
+	if len(got) != 2 || got[0].Text != "\u5409\u5229\u96fb\u8a71" {
+		t.Errorf("got %+v, want both terms replaced in the first word", got)
+	}
+	if len(subs) != 2 {
+		t.Errorf("got %d substitutions, want 2", len(subs))
+	}
+}
+
+func TestApplyGlossary_Deletion(t *testing.T) {
+	g := testGlossary(t, "you know\t\n")
+	words := []Word{
+		{Text: "It ", Start: 0, End: 0.2, Type: "word"},
+		{Text: "you ", Start: 0.2, End: 0.4, Type: "word"},
+		{Text: "know ", Start: 0.4, End: 0.6, Type: "word"},
+		{Text: "works.", Start: 0.6, End: 1, Type: "word"},
+	}
+
+	got, _ := applyGlossary(words, g)
+	var texts []string
+	for _, w := range got {
+		texts = append(texts, w.Text)
+	}
+	if joined := strings.Join(texts, ""); joined != "It works." {
+		t.Errorf("text after deletion = %q, want %q", joined, "It works.")
+	}
+}
+
+func TestProcessWithReport_Glossary(t *testing.T) {
+	transcript := &TranscriptResponse{
+		LanguageCode: "en",
+		Words: []Word{
+			{Text: "Hello", Start: 0, End: 0.4, Type: "word"},
+			{Text: " ", Start: 0.4, End: 0.5, Type: "spacing"},
+			{Text: "jon", Start: 0.5, End: 1.0, Type: "word"},
+			{Text: ".", Start: 1.0, End: 1.1, Type: "word"},
+		},
+	}
+	settings := defaultSettings()
+	settings.Glossary = testGlossary(t, "jon\tJohn\n")
+
+	srt, report, err := ProcessWithReport(transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(srt, "Hello John") {
+		t.Errorf("expected corrected text, got:\n%s", srt)
+	}
+	if len(report.Substitutions) != 1 || report.Substitutions[0].Line != 1 {
+		t.Errorf("report = %+v, want one substitution from line 1", report)
+	}
+}
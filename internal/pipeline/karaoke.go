@@ -0,0 +1,118 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scribe2srt/internal/subtitle"
+)
+
+// wordMark is the position of a timed word in the laid-out text of a cue.
+type wordMark struct {
+	// Offset is the byte offset of the word's first character.
+	Offset     int
+	Start, End float64
+	Speaker    string
+}
+
+// skippable reports whether r may appear in laid-out text around or inside
+// a word without being part of it: spaces, the line breaks added by layout
+// and the directional marks added for right-to-left lines.
+func skippable(r rune) bool {
+	return unicode.IsSpace(r) || unicode.Is(unicode.Bidi_Control, r)
+}
+
+// wordMarks finds each word in text, the laid-out source lines of a cue.
+// Words carry the trailing spacing and the CJK punctuation merged into them
+// by preprocessWords, so the marks fall on the first character of each word
+// and spaces stay with the word before. ok is false when a word cannot be
+// found, for example because a later stage rewrote the text.
+func wordMarks(text string, words []Word) (marks []wordMark, ok bool) {
+	pos := 0
+	skip := func() {
+		for pos < len(text) {
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			if !skippable(r) {
+				return
+			}
+			pos += size
+		}
+	}
+
+	for _, w := range words {
+		token := strings.TrimSpace(w.Text)
+		if token == "" {
+			continue
+		}
+		skip()
+		mark := wordMark{Offset: pos, Start: w.Start, End: w.End, Speaker: w.SpeakerID}
+		for _, want := range token {
+			if skippable(want) {
+				continue
+			}
+			skip()
+			r, size := utf8.DecodeRuneInString(text[pos:])
+			if pos >= len(text) || r != want {
+				return nil, false
+			}
+			pos += size
+		}
+		marks = append(marks, mark)
+	}
+	return marks, true
+}
+
+// karaokeText inserts tags[i] before the word of marks[i] and passes the
+// text around them through escape.
+func karaokeText(text string, marks []wordMark, tags []string, escape func(string) string) string {
+	var sb strings.Builder
+	prev := 0
+	for i, m := range marks {
+		sb.WriteString(escape(text[prev:m.Offset]))
+		sb.WriteString(tags[i])
+		prev = m.Offset
+	}
+	sb.WriteString(escape(text[prev:]))
+	return sb.String()
+}
+
+// assKaraokeTags returns {\kNN} tags in centiseconds for words shown from
+// start to end. Each word is highlighted for its own duration; the silence
+// before it gets a tag of its own with no text, so the highlight waits.
+func assKaraokeTags(marks []wordMark, start, end float64) []string {
+	cs := func(t float64) int {
+		return int(math.Round(min(max(t, start), end) * 100))
+	}
+	tags := make([]string, len(marks))
+	at := cs(start)
+	for i, m := range marks {
+		s := max(cs(m.Start), at)
+		e := max(cs(m.End), s)
+		if s > at {
+			tags[i] = fmt.Sprintf(`{\k%d}`, s-at)
+		}
+		tags[i] += fmt.Sprintf(`{\k%d}`, e-s)
+		at = e
+	}
+	return tags
+}
+
+// vttKaraokeTags returns WebVTT timestamp tags for words shown from start to
+// end. A word starting with the cue needs none, and timestamps that would
+// not lie strictly inside the cue or after the previous one are left out,
+// as WebVTT requires.
+func vttKaraokeTags(marks []wordMark, start, end float64) []string {
+	ms := func(t float64) int64 { return int64(math.Round(t * 1000)) }
+	tags := make([]string, len(marks))
+	last := ms(start)
+	for i, m := range marks {
+		if t := ms(m.Start); t > last && t < ms(end) {
+			tags[i] = "<" + subtitle.FormatVTTTime(m.Start) + ">"
+			last = t
+		}
+	}
+	return tags
+}
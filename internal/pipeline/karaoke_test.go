@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+)
+
+func TestWordMarks_SpacingAndLineBreaks(t *testing.T) {
+	words := []Word{
+		{Text: "Hello ", Start: 1, End: 1.4, Type: "word"},
+		{Text: "big ", Start: 1.5, End: 1.8, Type: "word"},
+		{Text: "world.", Start: 2, End: 2.5, Type: "word"},
+	}
+	marks, ok := wordMarks("Hello big\nworld.", words)
+	if !ok {
+		t.Fatal("words not found")
+	}
+	var offsets []int
+	for _, m := range marks {
+		offsets = append(offsets, m.Offset)
+	}
+	if !reflect.DeepEqual(offsets, []int{0, 6, 10}) {
+		t.Errorf("offsets = %v, want [0 6 10]", offsets)
+	}
+}
+
+func TestWordMarks_CJKPunctuationAndBreakInsideWord(t *testing.T) {
+	// 今日は / いい天気 / です。, laid out with a break inside いい天気.
+	words := []Word{
+		{Text: "\u4eca\u65e5\u306f", Start: 0, End: 0.6, Type: "word"},
+		{Text: "\u3044\u3044\u5929\u6c17", Start: 0.6, End: 1.2, Type: "word"},
+		{Text: "\u3067\u3059\u3002", Start: 1.2, End: 1.6, Type: "word"},
+	}
+	// 今日はいい\n天気です。
+	text := "\u4eca\u65e5\u306f\u3044\u3044\n\u5929\u6c17\u3067\u3059\u3002"
+	marks, ok := wordMarks(text, words)
+	if !ok || len(marks) != 3 {
+		t.Fatalf("marks = %v, ok = %v", marks, ok)
+	}
+	if marks[1].Offset != 9 || marks[2].Offset != 22 {
+		t.Errorf("offsets = %d, %d; want 9, 22", marks[1].Offset, marks[2].Offset)
+	}
+}
+
+func TestWordMarks_Mismatch(t *testing.T) {
+	words := []Word{{Text: "colour", Start: 0, End: 1, Type: "word"}}
+	if _, ok := wordMarks("color", words); ok {
+		t.Error("want no marks for text that does not match the words")
+	}
+}
+
+func TestASSKaraokeTags(t *testing.T) {
+	marks := []wordMark{
+		{Start: 1.2, End: 1.5},
+		{Start: 1.5, End: 1.9},
+		{Start: 2.3, End: 3.5},
+	}
+	got := assKaraokeTags(marks, 1, 3)
+	// A 0.2s lead-in, two adjacent words, a 0.4s pause, and the last word
+	// cut off at the cue end.
+	want := []string{`{\k20}{\k30}`, `{\k40}`, `{\k40}{\k70}`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %q, want %q", got, want)
+	}
+}
+
+func TestVTTKaraokeTags(t *testing.T) {
+	marks := []wordMark{
+		{Start: 1, End: 1.5},
+		{Start: 1.25, End: 1.9},
+		{Start: 1.25, End: 2},
+		{Start: 3, End: 3.5},
+	}
+	got := vttKaraokeTags(marks, 1, 3)
+	want := []string{"", "<00:00:01.250>", "", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %q, want %q", got, want)
+	}
+}
+
+func karaokeTranscript() *TranscriptResponse {
+	return &TranscriptResponse{
+		LanguageCode: "en",
+		Words: []Word{
+			{Text: "Tom", Start: 1, End: 1.4, Type: "word"},
+			{Text: " ", Start: 1.4, End: 1.5, Type: "spacing"},
+			{Text: "&", Start: 1.5, End: 1.7, Type: "word"},
+			{Text: " ", Start: 1.7, End: 1.8, Type: "spacing"},
+			{Text: "Jerry.", Start: 1.8, End: 2.5, Type: "word"},
+		},
+	}
+}
+
+func TestProcess_ASSKaraoke(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatASS
+	settings.Karaoke = true
+
+	ass := Process(karaokeTranscript(), settings)
+	want := `Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,{\k40}Tom {\k10}{\k20}& {\k10}{\k70}Jerry.` + "\n"
+	if !strings.Contains(ass, want) {
+		t.Errorf("ASS missing %q:\n%s", want, ass)
+	}
+}
+
+func TestProcess_VTTKaraoke(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatVTT
+	settings.Karaoke = true
+
+	vtt := Process(karaokeTranscript(), settings)
+	want := "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nTom <00:00:01.500>&amp; <00:00:01.800>Jerry.\n"
+	if vtt != want {
+		t.Errorf("VTT =\n%q\nwant\n%q", vtt, want)
+	}
+}
+
+func TestProcess_VTTWithoutKaraoke(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatVTT
+
+	vtt := Process(karaokeTranscript(), settings)
+	if !strings.HasSuffix(vtt, "\nTom &amp; Jerry.\n") {
+		t.Errorf("VTT = %q", vtt)
+	}
+}
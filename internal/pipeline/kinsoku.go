@@ -0,0 +1,100 @@
+package pipeline
+
+import "unicode"
+
+// noLineStart is the set of characters that must not begin a line
+// (gyoutou kinsoku): closing brackets, sentence and clause punctuation,
+// small kana, iteration marks and the prolonged sound mark.
+var noLineStart = map[rune]struct{}{
+	// Closing brackets and quotes.
+	')': {}, ']': {}, '}': {}, '\u3009': {}, '\u300b': {}, '\u300d': {}, '\u300f': {}, '\u3011': {}, // 〉》」』】
+	'\u3015': {}, '\u3017': {}, '\u3019': {}, '\u301f': {}, '\uff09': {}, '\uff3d': {}, '\uff5d': {}, // 〕〗〙〟）］｝
+	'\uff60': {}, '\u00bb': {}, '\u2019': {}, '\u201d': {}, // ｠»’”
+
+	// Sentence and clause punctuation.
+	'.': {}, ',': {}, '!': {}, '?': {}, ':': {}, ';': {},
+	'\u3001': {}, '\u3002': {}, '\uff0c': {}, '\uff0e': {}, '\u30fb': {}, // 、。，．・
+	'\uff1a': {}, '\uff1b': {}, '\uff01': {}, '\uff1f': {}, // ：；！？
+	'\u203c': {}, '\u2047': {}, '\u2048': {}, '\u2049': {}, // ‼⁇⁈⁉
+	'\u2026': {}, '\u2025': {}, // …‥
+	'\u060c': {}, '\u061b': {}, '\u061f': {}, '\u06d4': {}, // ،؛؟۔
+
+	// Small hiragana.
+	'\u3041': {}, '\u3043': {}, '\u3045': {}, '\u3047': {}, '\u3049': {}, // ぁぃぅぇぉ
+	'\u3063': {}, '\u3083': {}, '\u3085': {}, '\u3087': {}, '\u308e': {}, // っゃゅょゎ
+	'\u3095': {}, '\u3096': {}, // ゕゖ
+
+	// Small katakana (full-width and half-width).
+	'\u30a1': {}, '\u30a3': {}, '\u30a5': {}, '\u30a7': {}, '\u30a9': {}, // ァィゥェォ
+	'\u30c3': {}, '\u30e3': {}, '\u30e5': {}, '\u30e7': {}, '\u30ee': {}, // ッャュョヮ
+	'\u30f5': {}, '\u30f6': {}, // ヵヶ
+	'\uff67': {}, '\uff68': {}, '\uff69': {}, '\uff6a': {}, '\uff6b': {}, // ｧｨｩｪｫ
+	'\uff6c': {}, '\uff6d': {}, '\uff6e': {}, '\uff6f': {}, // ｬｭｮｯ
+
+	// Prolonged sound mark, iteration marks, voicing marks and dashes.
+	'\u30fc': {}, '\uff70': {}, // ーｰ
+	'\u3005': {}, '\u303b': {}, '\u309d': {}, '\u309e': {}, '\u30fd': {}, '\u30fe': {}, // 々〻ゝゞヽヾ
+	'\u309b': {}, '\u309c': {}, '\uff9e': {}, '\uff9f': {}, // ゛゜ﾞﾟ
+	'\u301c': {}, '\uff5e': {}, '\u30a0': {}, '\u2010': {}, '\u2013': {}, // 〜～゠‐–
+}
+
+// noLineEnd is the set of characters that must not end a line
+// (gyoumatsu kinsoku): opening brackets and quotes.
+var noLineEnd = map[rune]struct{}{
+	'(': {}, '[': {}, '{': {}, '\u3008': {}, '\u300a': {}, '\u300c': {}, '\u300e': {}, '\u3010': {}, // 〈《「『【
+	'\u3014': {}, '\u3016': {}, '\u3018': {}, '\u301d': {}, '\uff08': {}, '\uff3b': {}, '\uff5b': {}, // 〔〖〘〝（［｛
+	'\uff5f': {}, '\u00ab': {}, '\u2018': {}, '\u201c': {}, // ｟«‘“
+}
+
+// breakAfterParticles are grammatical particles after which a CJK line may be
+// broken when no punctuation or space is available.
+var breakAfterParticles = map[rune]struct{}{
+	// Japanese: は が を に へ で も
+	'\u306f': {}, '\u304c': {}, '\u3092': {}, '\u306b': {}, '\u3078': {}, '\u3067': {}, '\u3082': {},
+	// Chinese: 的 了 着 过 吗 呢 吧
+	'\u7684': {}, '\u4e86': {}, '\u7740': {}, '\u8fc7': {}, '\u5417': {}, '\u5462': {}, '\u5427': {},
+}
+
+func isNoLineStart(r rune) bool {
+	_, ok := noLineStart[r]
+	return ok
+}
+
+func isNoLineEnd(r rune) bool {
+	_, ok := noLineEnd[r]
+	return ok
+}
+
+func isBreakAfterParticle(r rune) bool {
+	_, ok := breakAfterParticles[r]
+	return ok
+}
+
+// canBreakAt reports whether a line break before runes[pos] satisfies the
+// kinsoku rules. Whitespace around the break is ignored, since both lines are
+// trimmed when rendered.
+func canBreakAt(runes []rune, pos int) bool {
+	if pos <= 0 || pos >= len(runes) {
+		return true
+	}
+
+	prev := pos - 1
+	for prev >= 0 && unicode.IsSpace(runes[prev]) {
+		prev--
+	}
+	next := pos
+	for next < len(runes) && unicode.IsSpace(runes[next]) {
+		next++
+	}
+	if prev < 0 || next >= len(runes) {
+		return true
+	}
+
+	if isNoLineEnd(runes[prev]) {
+		return false
+	}
+	if isNoLineStart(runes[next]) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"strings"
+
+	"scribe2srt/internal/bidi"
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+)
+
+// lrcClearGap is the silence after a line, in seconds, from which an empty
+// line clears it. LRC lines have no end time, so shorter gaps keep a line
+// on screen until the next one.
+const lrcClearGap = 1.0
+
+// lrcBreakMarker is the text of an instrumental-break line.
+const lrcBreakMarker = "\u266a" // ♪
+
+// generateLRC writes entries as LRC lyrics, one line per cue. With karaoke
+// it writes enhanced LRC, with a <mm:ss.xx> timestamp before each word and
+// one after the last. Bilingual cues get a source and a translation line at
+// the same time.
+func generateLRC(entries []SubtitleEntry, opts outputOptions) string {
+	var lines []subtitle.LRCLine
+	var shownUntil float64
+	add := func(entry SubtitleEntry, texts ...string) {
+		if len(lines) > 0 && entry.Start-shownUntil >= lrcClearGap {
+			lines = append(lines, subtitle.LRCLine{Time: shownUntil})
+		}
+		for _, text := range texts {
+			lines = append(lines, subtitle.LRCLine{Time: entry.Start, Text: text})
+		}
+		shownUntil = entry.End
+	}
+
+	for _, entry := range entries {
+		if entry.IsAudioEvent {
+			if opts.LRCEvents == config.LRCBreakMarkers {
+				add(entry, lrcBreakMarker)
+			}
+			continue
+		}
+
+		source := bidi.Apply(strings.TrimSpace(entry.Text), opts.RTL)
+		if opts.Karaoke {
+			if marks, ok := wordMarks(source, entry.Words); ok && len(marks) > 0 {
+				source = karaokeText(source, marks, lrcWordTags(marks, entry.Start, entry.End), plainText) +
+					" <" + subtitle.FormatLRCTime(lrcWordsEnd(marks, entry.Start, entry.End)) + ">"
+			}
+		}
+		switch {
+		case entry.Translation == "":
+			add(entry, source)
+		case opts.Bilingual:
+			add(entry, source, bidi.Apply(entry.Translation, opts.RTL))
+		default:
+			add(entry, bidi.Apply(entry.Translation, opts.RTL))
+		}
+	}
+	if len(lines) > 0 {
+		lines = append(lines, subtitle.LRCLine{Time: shownUntil})
+	}
+	return subtitle.FormatLRC(opts.Title, opts.Artist, lines)
+}
+
+// lrcWordTags returns an enhanced-LRC timestamp for every word, kept within
+// the cue and in order.
+func lrcWordTags(marks []wordMark, start, end float64) []string {
+	tags := make([]string, len(marks))
+	at := start
+	for i, m := range marks {
+		at = max(min(m.Start, end), at)
+		tags[i] = "<" + subtitle.FormatLRCTime(at) + ">"
+	}
+	return tags
+}
+
+// lrcWordsEnd is the end of the last word, kept within the cue.
+func lrcWordsEnd(marks []wordMark, start, end float64) float64 {
+	last := marks[len(marks)-1]
+	return max(min(last.End, end), min(last.Start, end), start)
+}
@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"testing"
+
+	"scribe2srt/internal/config"
+)
+
+func lrcEntries() []SubtitleEntry {
+	return []SubtitleEntry{
+		{Text: "(music)", Start: 0, End: 4, IsAudioEvent: true},
+		{Text: "Blue skies", Start: 4, End: 5.5, Words: []Word{
+			{Text: "Blue ", Start: 4, End: 4.5, Type: "word"},
+			{Text: "skies", Start: 4.75, End: 5.5, Type: "word"},
+		}},
+		{Text: "smiling at me", Start: 5.6, End: 7},
+		{Text: "nothing but", Start: 9, End: 10},
+	}
+}
+
+func TestGenerateLRC(t *testing.T) {
+	lrc := mustGenerate(t, lrcEntries(), outputOptions{Format: config.FormatLRC, Title: "Blue Skies", Artist: "Irving Berlin"})
+	want := "[ti:Blue Skies]\n[ar:Irving Berlin]\n" +
+		"[00:04.00]Blue skies\n" +
+		"[00:05.60]smiling at me\n" +
+		"[00:07.00]\n" + // cleared before the two-second gap
+		"[00:09.00]nothing but\n" +
+		"[00:10.00]\n"
+	if lrc != want {
+		t.Errorf("LRC =\n%s\nwant\n%s", lrc, want)
+	}
+}
+
+func TestGenerateLRC_BreakMarkers(t *testing.T) {
+	lrc := mustGenerate(t, lrcEntries()[:2], outputOptions{Format: config.FormatLRC, LRCEvents: config.LRCBreakMarkers})
+	// ♪ marks the instrumental break.
+	want := "[00:00.00]\u266a\n[00:04.00]Blue skies\n[00:05.50]\n"
+	if lrc != want {
+		t.Errorf("LRC =\n%s\nwant\n%s", lrc, want)
+	}
+}
+
+func TestGenerateLRC_Enhanced(t *testing.T) {
+	lrc := mustGenerate(t, lrcEntries()[1:2], outputOptions{Format: config.FormatLRC, Karaoke: true})
+	want := "[00:04.00]<00:04.00>Blue <00:04.75>skies <00:05.50>\n[00:05.50]\n"
+	if lrc != want {
+		t.Errorf("LRC =\n%s\nwant\n%s", lrc, want)
+	}
+}
+
+func TestGenerateLRC_Bilingual(t *testing.T) {
+	entries := []SubtitleEntry{{Text: "Blue skies", Translation: "Cielos azules", Start: 4, End: 5.5}}
+	lrc := mustGenerate(t, entries, outputOptions{Format: config.FormatLRC, Bilingual: true})
+	want := "[00:04.00]Blue skies\n[00:04.00]Cielos azules\n[00:05.50]\n"
+	if lrc != want {
+		t.Errorf("LRC =\n%s\nwant\n%s", lrc, want)
+	}
+}
@@ -3,13 +3,19 @@ package pipeline
 import (
 	"math"
 	"strings"
-	"unicode"
 	"unicode/utf8"
 
 	"scribe2srt/internal/config"
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/textwidth"
+	"scribe2srt/internal/timecode"
 )
 
 // IntelligentMerger implements Stage 2 of the subtitle pipeline.
+//
+// MaxCPS and MaxCharsPerLine are the limits for the transcript language. Each
+// cue is checked against the limits of its own dominant script, so English
+// phrases in a Chinese transcript are measured with the Latin limits.
 type IntelligentMerger struct {
 	Language           string
 	IsCJK              bool
@@ -18,56 +24,80 @@ type IntelligentMerger struct {
 	MinSubtitleGap     float64
 	MaxCPS             float64
 	MaxCharsPerLine    int
+	WidthMode          textwidth.Mode
+
+	// FrameRate enables frame-accurate timing when non-zero; see snapToFrames.
+	FrameRate         timecode.Rate
+	MinGapFrames      int
+	MinDurationFrames int
+	ChainFrames       int
+
+	// ShotChanges are sorted cut times; see snapToShots.
+	ShotChanges    []float64
+	ShotSnapWindow float64
+
+	// AttachEvents joins audio events that overlap no speech to the nearest
+	// speech cue within AttachWithin seconds, using AttachMode.
+	AttachEvents bool
+	AttachMode   config.AudioOverlap
+	AttachWithin float64
+
+	limits scriptLimits
 }
 
 // NewIntelligentMerger creates a merger from subtitle settings and language code.
 func NewIntelligentMerger(langCode string, settings *config.SubtitleSettings) *IntelligentMerger {
-	lang := langCode
-	if len(lang) > 3 {
-		lang = lang[:3]
-	}
-	isCJK := config.IsCJK(lang)
+	code := lang.Normalize(langCode)
+	isCJK := config.IsCJK(code)
 
 	m := &IntelligentMerger{
-		Language:           lang,
+		Language:           code,
 		IsCJK:              isCJK,
 		MinSubtitleDuration: settings.MinSubtitleDuration,
 		MaxSubtitleDuration: settings.MaxSubtitleDuration,
 		MinSubtitleGap:     settings.MinSubtitleGap,
+		WidthMode:          settings.WidthMode,
+		FrameRate:          settings.FrameRate,
+		MinGapFrames:       settings.MinGapFrames,
+		MinDurationFrames:  settings.MinDurationFrames,
+		ChainFrames:        settings.ChainFrames,
+		ShotChanges:        settings.ShotChanges,
+		ShotSnapWindow:     settings.ShotSnapWindow,
+		limits:             newScriptLimits(settings, isCJK),
 	}
 
 	if isCJK {
-		m.MaxCPS = settings.CJKCPS
-		m.MaxCharsPerLine = settings.CJKCharsPerLine
+		m.MaxCPS = m.limits.CJKCPS
+		m.MaxCharsPerLine = m.limits.CJKCPL
 	} else {
-		m.MaxCPS = settings.LatinCPS
-		m.MaxCharsPerLine = settings.LatinCharsPerLine
+		m.MaxCPS = m.limits.LatinCPS
+		m.MaxCharsPerLine = m.limits.LatinCPL
 	}
 
+	if mode, ok := settings.AudioEvents.AttachMode(); ok {
+		m.AttachEvents = true
+		m.AttachMode = mode
+		m.AttachWithin = settings.AudioEvents.AttachWithin
+	}
 	return m
 }
 
-// stripWhitespaceCount returns the number of non-whitespace runes in text.
-func stripWhitespaceCount(text string) int {
-	count := 0
-	for _, r := range text {
-		if !unicode.IsSpace(r) {
-			count++
-		}
-	}
-	return count
+// stripWhitespaceCount returns the width of the non-whitespace characters in
+// text, as measured by mode.
+func stripWhitespaceCount(text string, mode textwidth.Mode) int {
+	return mode.WidthNoSpace(text)
 }
 
 func (m *IntelligentMerger) calculateCPS(text string, duration float64) float64 {
 	if duration <= 0 {
 		return math.Inf(1)
 	}
-	return float64(stripWhitespaceCount(text)) / duration
+	return float64(stripWhitespaceCount(text, m.WidthMode)) / duration
 }
 
 func (m *IntelligentMerger) getDynamicCPSLimit(text string) float64 {
-	base := m.MaxCPS
-	textLen := stripWhitespaceCount(text)
+	base := m.limits.cps(text)
+	textLen := stripWhitespaceCount(text, m.WidthMode)
 
 	if textLen <= 3 {
 		return base * 3.0
@@ -79,23 +109,37 @@ func (m *IntelligentMerger) getDynamicCPSLimit(text string) float64 {
 	return base
 }
 
-func (m *IntelligentMerger) calculateDisplayLines(text string) int {
+// calculateDisplayLines counts the lines text takes when laid out like
+// optimizeEntryDisplay does, breaking at the boundaries between words as
+// well as at spaces and punctuation.
+func (m *IntelligentMerger) calculateDisplayLines(text string, words []Word) int {
 	if text == "" {
 		return 0
 	}
 
 	remaining := strings.TrimSpace(text)
+	maxCPL := m.limits.cpl(remaining)
+	wordBreaks := wordBoundaries(remaining, words)
 	lines := 0
 
 	for remaining != "" {
 		lines++
-		runes := []rune(remaining)
-		if len(runes) <= m.MaxCharsPerLine {
+		if m.WidthMode.Width(remaining) <= maxCPL {
 			break
 		}
-		splitPos := findSplitPosition(remaining, m.MaxCharsPerLine)
-		splitRunes := []rune(remaining)
-		remaining = strings.TrimSpace(string(splitRunes[splitPos:]))
+		splitPos := findSplitPositionAt(remaining, maxCPL, m.WidthMode, wordBreaks)
+		rest := string([]rune(remaining)[splitPos:])
+		remaining = strings.TrimSpace(rest)
+
+		// Word breaks are rune offsets, so they move with the line start.
+		shift := splitPos + utf8.RuneCountInString(rest) - utf8.RuneCountInString(remaining)
+		var next []int
+		for _, b := range wordBreaks {
+			if b > shift {
+				next = append(next, b-shift)
+			}
+		}
+		wordBreaks = next
 	}
 	return lines
 }
@@ -113,7 +157,7 @@ func (m *IntelligentMerger) canMerge(e1, e2 SubtitleEntry) (bool, string) {
 		return false, "gap too large"
 	}
 
-	mergedText := e1.Text + " " + e2.Text
+	mergedText := joinTexts(strings.TrimSpace(e1.Text), strings.TrimSpace(e2.Text))
 	mergedDuration := e2.End - e1.Start
 
 	maxAllowed := math.Min(m.MaxSubtitleDuration, 6.0)
@@ -127,12 +171,12 @@ func (m *IntelligentMerger) canMerge(e1, e2 SubtitleEntry) (bool, string) {
 		return false, "CPS too high"
 	}
 
-	mergedLines := m.calculateDisplayLines(mergedText)
+	mergedLines := m.calculateDisplayLines(mergedText, append(append([]Word(nil), e1.Words...), e2.Words...))
 	if mergedLines > 2 {
 		return false, "too many lines"
 	}
 
-	if mergedLines == 1 && utf8.RuneCountInString(mergedText) > m.MaxCharsPerLine {
+	if mergedLines == 1 && m.WidthMode.Width(mergedText) > m.limits.cpl(mergedText) {
 		return false, "single line too long"
 	}
 
@@ -187,14 +231,7 @@ func (m *IntelligentMerger) mergeTwoEntries(e1, e2 SubtitleEntry) SubtitleEntry
 	t1 := strings.TrimSpace(e1.Text)
 	t2 := strings.TrimSpace(e2.Text)
 
-	var mergedText string
-	if t1 != "" && endsWithJoinPunctuation(t1) {
-		mergedText = t1 + t2
-	} else if m.IsCJK {
-		mergedText = t1 + t2
-	} else {
-		mergedText = t1 + " " + t2
-	}
+	mergedText := joinTexts(t1, t2)
 
 	words := make([]Word, 0, len(e1.Words)+len(e2.Words))
 	words = append(words, e1.Words...)
@@ -207,7 +244,7 @@ func (m *IntelligentMerger) mergeTwoEntries(e1, e2 SubtitleEntry) SubtitleEntry
 		Words:        words,
 		IsAudioEvent: e1.IsAudioEvent || e2.IsAudioEvent,
 		WordCount:    e1.WordCount + e2.WordCount,
-		CharCount:    stripWhitespaceCount(mergedText),
+		CharCount:    stripWhitespaceCount(mergedText, m.WidthMode),
 	}
 }
 
@@ -274,6 +311,13 @@ func (m *IntelligentMerger) OptimizeMergedEntries(entries []SubtitleEntry) []Sub
 		optimized = append(optimized, e)
 	}
 
+	if len(m.ShotChanges) > 0 && m.ShotSnapWindow > 0 {
+		optimized = m.snapToShots(optimized)
+	}
+	if !m.FrameRate.IsZero() {
+		optimized = m.snapToFrames(optimized)
+	}
+
 	return optimized
 }
 
@@ -297,7 +341,7 @@ func (m *IntelligentMerger) optimizeSingle(entry SubtitleEntry) SubtitleEntry {
 	cps := m.calculateCPS(e.Text, duration)
 	dynamicLimit := m.getDynamicCPSLimit(e.Text)
 	if cps > dynamicLimit {
-		required := float64(stripWhitespaceCount(e.Text)) / dynamicLimit
+		required := float64(stripWhitespaceCount(e.Text, m.WidthMode)) / dynamicLimit
 		required = math.Min(required, m.MaxSubtitleDuration)
 		e.End = e.Start + required
 	}
@@ -2,9 +2,11 @@ package pipeline
 
 import (
 	"math"
+	"strings"
 	"testing"
 
 	"scribe2srt/internal/config"
+	"scribe2srt/internal/textwidth"
 )
 
 func defaultMerger() *IntelligentMerger {
@@ -71,7 +73,7 @@ func TestStripWhitespaceCount(t *testing.T) {
 		{"\t\n", 0},
 	}
 	for _, tt := range tests {
-		got := stripWhitespaceCount(tt.text)
+		got := stripWhitespaceCount(tt.text, textwidth.Runes)
 		if got != tt.want {
 			t.Errorf("stripWhitespaceCount(%q) = %d, want %d", tt.text, got, tt.want)
 		}
@@ -137,13 +139,31 @@ func TestCalculateDisplayLines(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := m.calculateDisplayLines(tt.text)
+		got := m.calculateDisplayLines(tt.text, nil)
 		if got != tt.want {
 			t.Errorf("calculateDisplayLines(%q) = %d, want %d", tt.text, got, tt.want)
 		}
 	}
 }
 
+func TestCalculateDisplayLines_WordBreaks(t *testing.T) {
+	m := defaultMerger() // CJKCharsPerLine = 25
+
+	// Three katakana words of 20, 20 and 10 runes. Broken at the limit the
+	// text fits two lines; broken between words, as the output is, it takes
+	// three.
+	a, b, c := strings.Repeat("\u30a2", 20), strings.Repeat("\u30a4", 20), strings.Repeat("\u30a6", 10) // ア, イ, ウ
+	words := []Word{{Text: a, Type: "word"}, {Text: b, Type: "word"}, {Text: c, Type: "word"}}
+	text := a + b + c
+
+	if got := m.calculateDisplayLines(text, nil); got != 2 {
+		t.Errorf("calculateDisplayLines without words = %d, want 2", got)
+	}
+	if got := m.calculateDisplayLines(text, words); got != 3 {
+		t.Errorf("calculateDisplayLines with words = %d, want 3", got)
+	}
+}
+
 func TestCanMerge_AudioEvents(t *testing.T) {
 	m := defaultMerger()
 
@@ -409,3 +429,21 @@ func TestOptimizeMergedEntries_Empty(t *testing.T) {
 		t.Errorf("expected nil for empty input, got %v", result)
 	}
 }
+
+func TestNewIntelligentMerger_EastAsianWidth(t *testing.T) {
+	settings := defaultSettings()
+	settings.WidthMode = textwidth.EastAsian
+
+	m := NewIntelligentMerger("ja", settings)
+	if m.MaxCharsPerLine != 50 {
+		t.Errorf("MaxCharsPerLine = %d, want 50 half-width units", m.MaxCharsPerLine)
+	}
+	if m.MaxCPS != 22 {
+		t.Errorf("MaxCPS = %f, want 22", m.MaxCPS)
+	}
+
+	m = NewIntelligentMerger("en", settings)
+	if m.MaxCharsPerLine != 42 {
+		t.Errorf("MaxCharsPerLine = %d, want 42 for Latin", m.MaxCharsPerLine)
+	}
+}
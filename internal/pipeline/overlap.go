@@ -0,0 +1,164 @@
+package pipeline
+
+import (
+	"sort"
+
+	"scribe2srt/internal/config"
+)
+
+// ResolveTiming is the final timing stage. It combines the speech cues with
+// the audio-event entries, handles events that overlap speech according to
+// policy, attaches the remaining ones to nearby speech when m.AttachEvents is
+// set, and guarantees that the result is sorted, non-overlapping and
+// separated by the minimum gap.
+func (m *IntelligentMerger) ResolveTiming(speech, events []SubtitleEntry, policy config.AudioOverlap) []SubtitleEntry {
+	speech = append([]SubtitleEntry(nil), speech...)
+	events = mergeOverlappingEvents(events)
+
+	all := make([]SubtitleEntry, 0, len(speech)+len(events))
+	for _, ev := range events {
+		mode := policy
+		i := mostOverlapping(speech, ev)
+		if i < 0 && m.AttachEvents {
+			if i = nearestWithin(speech, ev, m.AttachWithin); i >= 0 {
+				mode = m.AttachMode
+			}
+		}
+		if i < 0 {
+			all = append(all, ev)
+			continue
+		}
+		switch mode {
+		case config.OverlapDrop:
+		case config.OverlapMerge:
+			speech[i] = attachEventInline(speech[i], ev)
+		case config.OverlapStack:
+			// An event line above speech that already fills two lines would
+			// make a third; the event keeps its own cue instead.
+			if m.calculateDisplayLines(speech[i].Text, speech[i].Words) >= 2 {
+				all = append(all, ev)
+			} else {
+				speech[i] = stackEvent(speech[i], ev)
+			}
+		default:
+			all = append(all, ev)
+		}
+	}
+	all = append(all, speech...)
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Start < all[j].Start
+	})
+	return m.removeOverlaps(all)
+}
+
+// removeOverlaps makes entries, sorted by start, strictly sequential. A cue
+// that runs into the next one is cut back to the minimum gap before it, but
+// not below the minimum duration; if that is not enough, the next cue starts
+// later instead. Its end is kept unless that would leave it shorter than the
+// minimum duration, so a dense run of cues does not drift later.
+func (m *IntelligentMerger) removeOverlaps(entries []SubtitleEntry) []SubtitleEntry {
+	gap, minDuration := m.timingRules()
+
+	for i := range entries {
+		e := &entries[i]
+		if e.End <= e.Start {
+			e.End = e.Start + minDuration
+		}
+		if i == 0 {
+			continue
+		}
+
+		prev := &entries[i-1]
+		if e.Start-prev.End >= gap-1e-9 {
+			continue
+		}
+		prev.End = max(e.Start-gap, prev.Start+minDuration)
+		if start := prev.End + gap; e.Start < start {
+			e.Start = start
+			e.End = max(e.End, e.Start+minDuration)
+		}
+	}
+	return entries
+}
+
+// mergeOverlappingEvents sorts audio events and joins events that overlap
+// each other into one entry.
+func mergeOverlappingEvents(events []SubtitleEntry) []SubtitleEntry {
+	if len(events) == 0 {
+		return nil
+	}
+	sorted := append([]SubtitleEntry(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	merged := []SubtitleEntry{sorted[0]}
+	for _, ev := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if ev.Start >= last.End {
+			merged = append(merged, ev)
+			continue
+		}
+		last.Text = joinEventText(last.Text, ev.Text)
+		last.End = max(last.End, ev.End)
+		last.Words = append(last.Words, ev.Words...)
+		last.CharCount += ev.CharCount
+	}
+	return merged
+}
+
+// mostOverlapping returns the index of the speech cue that shares the most
+// time with ev, or -1 if none overlaps it.
+func mostOverlapping(speech []SubtitleEntry, ev SubtitleEntry) int {
+	best, bestOverlap := -1, 0.0
+	for i, s := range speech {
+		overlap := min(s.End, ev.End) - max(s.Start, ev.Start)
+		if overlap > bestOverlap {
+			best, bestOverlap = i, overlap
+		}
+	}
+	return best
+}
+
+// nearestWithin returns the index of the speech cue closest to ev, or -1 if
+// none is within maxDistance seconds of it.
+func nearestWithin(speech []SubtitleEntry, ev SubtitleEntry, maxDistance float64) int {
+	best, bestDistance := -1, maxDistance
+	for i, s := range speech {
+		distance := max(s.Start-ev.End, ev.Start-s.End)
+		if distance <= bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	return best
+}
+
+// attachEventInline joins the event text into the speech line, before or
+// after the speech depending on which starts first.
+func attachEventInline(cue, ev SubtitleEntry) SubtitleEntry {
+	if ev.Start <= cue.Start {
+		cue.Text = joinEventText(ev.Text, cue.Text)
+		cue.Words = append(append([]Word(nil), ev.Words...), cue.Words...)
+	} else {
+		cue.Text = joinEventText(cue.Text, ev.Text)
+		cue.Words = append(cue.Words, ev.Words...)
+	}
+	cue.CharCount += ev.CharCount
+	return cue
+}
+
+// stackEvent puts the event text on a line of its own above the speech.
+func stackEvent(cue, ev SubtitleEntry) SubtitleEntry {
+	cue.EventText = joinEventText(cue.EventText, ev.Text)
+	return cue
+}
+
+// joinEventText joins an audio-event label to other text. Unlike joinTexts,
+// the closing bracket of a label does not suppress the space.
+func joinEventText(t1, t2 string) string {
+	if needsJoinSpace(t1, t2) {
+		return t1 + " " + t2
+	}
+	return t1 + t2
+}
@@ -0,0 +1,180 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+)
+
+func assertSequential(t *testing.T, entries []SubtitleEntry, gap float64) {
+	t.Helper()
+	for i, e := range entries {
+		if e.End <= e.Start {
+			t.Errorf("entry %d has End %v <= Start %v", i, e.End, e.Start)
+		}
+		if i > 0 && e.Start-entries[i-1].End < gap-1e-9 {
+			t.Errorf("entry %d starts %v after entry %d ends, want at least %v",
+				i, e.Start-entries[i-1].End, i-1, gap)
+		}
+	}
+}
+
+func TestResolveTiming_SpeechOverlaps(t *testing.T) {
+	m := defaultMerger()
+	speech := []SubtitleEntry{
+		{Text: "One", Start: 0, End: 2.5},
+		{Text: "Two", Start: 2.0, End: 2.4},
+		{Text: "Three", Start: 2.3, End: 4.0},
+	}
+
+	got := m.ResolveTiming(speech, nil, config.OverlapStack)
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3", len(got))
+	}
+	assertSequential(t, got, m.MinSubtitleGap)
+	for i, e := range got {
+		if i > 0 && e.Start < got[i-1].Start {
+			t.Errorf("entry %d is out of order", i)
+		}
+	}
+}
+
+func TestResolveTiming_DenseRunKeepsEnds(t *testing.T) {
+	m := defaultMerger()
+	speech := []SubtitleEntry{
+		{Text: "One", Start: 0, End: 0.9},
+		{Text: "Two", Start: 0.85, End: 1.8},
+		{Text: "Three", Start: 1.75, End: 2.7},
+	}
+
+	got := m.ResolveTiming(speech, nil, config.OverlapSeparate)
+	if len(got) != 3 {
+		t.Fatalf("got %d entries, want 3", len(got))
+	}
+	assertSequential(t, got, m.MinSubtitleGap)
+	ends := []float64{0.9, 1.8, 2.7}
+	for i, e := range got {
+		if e.End > ends[i] {
+			t.Errorf("entry %d ends at %v, want no later than %v", i, e.End, ends[i])
+		}
+		if e.End-e.Start < m.MinSubtitleDuration-1e-9 {
+			t.Errorf("entry %d lasts %v, want at least %v", i, e.End-e.Start, m.MinSubtitleDuration)
+		}
+	}
+}
+
+func TestResolveTiming_AudioEventPolicies(t *testing.T) {
+	speech := []SubtitleEntry{
+		{Text: "Hello there.", Start: 1.0, End: 3.0},
+	}
+	events := []SubtitleEntry{
+		{Text: "(laughs)", Start: 0.8, End: 1.5, IsAudioEvent: true},
+		{Text: "(music)", Start: 5.0, End: 6.0, IsAudioEvent: true},
+	}
+
+	m := defaultMerger()
+
+	got := m.ResolveTiming(speech, events, config.OverlapDrop)
+	if len(got) != 2 || got[0].Text != "Hello there." || got[1].Text != "(music)" {
+		t.Errorf("drop: got %+v", got)
+	}
+
+	got = m.ResolveTiming(speech, events, config.OverlapMerge)
+	if len(got) != 2 || got[0].Text != "(laughs) Hello there." {
+		t.Errorf("merge: got %+v", got)
+	}
+
+	got = m.ResolveTiming(speech, events, config.OverlapStack)
+	if len(got) != 2 || got[0].EventText != "(laughs)" || got[0].Text != "Hello there." {
+		t.Errorf("stack: got %+v", got)
+	}
+	assertSequential(t, got, m.MinSubtitleGap)
+
+	// The default keeps the event as a cue of its own, moved clear of the
+	// speech.
+	got = m.ResolveTiming(speech, events, config.OverlapSeparate)
+	if len(got) != 3 || got[0].Text != "(laughs)" || got[1].Text != "Hello there." || got[1].EventText != "" {
+		t.Errorf("separate: got %+v", got)
+	}
+	assertSequential(t, got, m.MinSubtitleGap)
+
+	// The input slices are left untouched.
+	if speech[0].Text != "Hello there." || speech[0].EventText != "" {
+		t.Errorf("ResolveTiming modified its input: %+v", speech[0])
+	}
+}
+
+func TestResolveTiming_StackKeepsTwoLines(t *testing.T) {
+	m := defaultMerger()
+	speech := []SubtitleEntry{
+		{Text: "This speech is long enough that it already fills both of the lines.", Start: 1.0, End: 5.0},
+	}
+	events := []SubtitleEntry{{Text: "(laughs)", Start: 2.0, End: 2.5, IsAudioEvent: true}}
+
+	got := m.ResolveTiming(speech, events, config.OverlapStack)
+	if len(got) != 2 || got[0].EventText != "" {
+		t.Errorf("got %+v, want the event in a cue of its own", got)
+	}
+	assertSequential(t, got, m.MinSubtitleGap)
+}
+
+func TestResolveTiming_OverlappingEvents(t *testing.T) {
+	m := defaultMerger()
+	events := []SubtitleEntry{
+		{Text: "(music)", Start: 0, End: 3, IsAudioEvent: true},
+		{Text: "(applause)", Start: 2, End: 4, IsAudioEvent: true},
+	}
+
+	got := m.ResolveTiming(nil, events, config.OverlapStack)
+	if len(got) != 1 || got[0].Text != "(music) (applause)" || got[0].End != 4 {
+		t.Errorf("got %+v, want one merged event", got)
+	}
+}
+
+func TestProcess_StackedAudioEvent(t *testing.T) {
+	transcript := &TranscriptResponse{
+		LanguageCode: "en",
+		Words: []Word{
+			{Text: "(laughs)", Start: 0.5, End: 1.2, Type: "audio_event"},
+			{Text: "Hello ", Start: 0.6, End: 1.0, Type: "word"},
+			{Text: "there.", Start: 1.0, End: 1.8, Type: "word"},
+		},
+	}
+
+	settings := defaultSettings()
+	settings.AudioOverlap = config.OverlapStack
+	result := Process(transcript, settings)
+	if !strings.Contains(result, "(laughs)\nHello there.") {
+		t.Errorf("expected the event stacked above the speech, got:\n%s", result)
+	}
+	if strings.Count(result, "-->") != 1 {
+		t.Errorf("expected a single cue, got:\n%s", result)
+	}
+}
+
+func TestResolveTiming_AttachToNearbySpeech(t *testing.T) {
+	m := defaultMerger()
+	m.AttachEvents = true
+	m.AttachMode = config.OverlapStack
+	m.AttachWithin = 1.0
+
+	speech := []SubtitleEntry{
+		{Text: "Hello there.", Start: 1.0, End: 3.0},
+	}
+	events := []SubtitleEntry{
+		{Text: "[MUSIC]", Start: 3.5, End: 4.0, IsAudioEvent: true},
+		{Text: "[APPLAUSE]", Start: 8.0, End: 9.0, IsAudioEvent: true},
+	}
+
+	got := m.ResolveTiming(speech, events, config.OverlapDrop)
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(got), got)
+	}
+	if got[0].EventText != "[MUSIC]" {
+		t.Errorf("EventText = %q, want the nearby event attached", got[0].EventText)
+	}
+	if got[1].Text != "[APPLAUSE]" {
+		t.Errorf("distant event should keep its own cue, got %+v", got[1])
+	}
+}
@@ -26,8 +26,9 @@ type preprocessResult struct {
 
 // preprocessWords separates audio events, drops spacing tokens (appending a
 // space to the previous word), and merges standalone CJK punctuation into the
-// preceding word. This mirrors SrtProcessor._preprocess_words.
-func preprocessWords(raw []Word) preprocessResult {
+// preceding word. This mirrors SrtProcessor._preprocess_words. A non-nil
+// cleaner then removes fillers and repetitions for clean verbatim.
+func preprocessWords(raw []Word, cleaner *verbatimCleaner) preprocessResult {
 	var words []Word
 	var audioEvents []Word
 
@@ -68,5 +69,9 @@ func preprocessWords(raw []Word) preprocessResult {
 		words = append(words, w)
 	}
 
+	if cleaner != nil {
+		words = cleaner.clean(words)
+	}
+
 	return preprocessResult{Words: words, AudioEvents: audioEvents}
 }
@@ -5,7 +5,7 @@ import (
 )
 
 func TestPreprocessWords_Empty(t *testing.T) {
-	result := preprocessWords(nil)
+	result := preprocessWords(nil, nil)
 	if len(result.Words) != 0 {
 		t.Errorf("expected 0 words, got %d", len(result.Words))
 	}
@@ -20,7 +20,7 @@ func TestPreprocessWords_SeparatesAudioEvents(t *testing.T) {
 		{Text: "(laughter)", Start: 1, End: 2, Type: "audio_event"},
 		{Text: "world", Start: 2, End: 3, Type: "word"},
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if len(result.Words) != 2 {
 		t.Fatalf("expected 2 words, got %d", len(result.Words))
@@ -39,7 +39,7 @@ func TestPreprocessWords_SpacingAppendsToPrevious(t *testing.T) {
 		{Text: " ", Start: 1, End: 1, Type: "spacing"},
 		{Text: "world", Start: 1, End: 2, Type: "word"},
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if len(result.Words) != 2 {
 		t.Fatalf("expected 2 words, got %d", len(result.Words))
@@ -58,7 +58,7 @@ func TestPreprocessWords_SpacingNotDuplicated(t *testing.T) {
 		{Text: " ", Start: 1, End: 1, Type: "spacing"},
 		{Text: "world", Start: 1, End: 2, Type: "word"},
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if result.Words[0].Text != "Hello " {
 		t.Errorf("expected 'Hello ' (no double space), got %q", result.Words[0].Text)
@@ -70,7 +70,7 @@ func TestPreprocessWords_MergesCJKPunctuation(t *testing.T) {
 		{Text: "hello", Start: 0, End: 1, Type: "word"},
 		{Text: "\u3002", Start: 1, End: 1.1, Type: "word"}, // 。
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if len(result.Words) != 1 {
 		t.Fatalf("expected 1 word after CJK punct merge, got %d", len(result.Words))
@@ -89,7 +89,7 @@ func TestPreprocessWords_NoDoubleCJKPunctMerge(t *testing.T) {
 		{Text: "hello\u3002", Start: 0, End: 1, Type: "word"}, // hello。
 		{Text: "\uff1f", Start: 1, End: 1.1, Type: "word"},    // ？
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if len(result.Words) != 2 {
 		t.Fatalf("expected 2 words (no double-punct merge), got %d", len(result.Words))
@@ -102,7 +102,7 @@ func TestPreprocessWords_SpacingWithoutPrevWord(t *testing.T) {
 		{Text: " ", Start: 0, End: 0, Type: "spacing"},
 		{Text: "Hello", Start: 0, End: 1, Type: "word"},
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	if len(result.Words) != 1 {
 		t.Fatalf("expected 1 word, got %d", len(result.Words))
@@ -119,7 +119,7 @@ func TestPreprocessWords_NonSpacingTokenPassedThrough(t *testing.T) {
 		{Text: "-", Start: 1, End: 1, Type: "spacing"},
 		{Text: "world", Start: 1, End: 2, Type: "word"},
 	}
-	result := preprocessWords(raw)
+	result := preprocessWords(raw, nil)
 
 	// "-" is not TrimSpace == "", so it's not a pure space; it gets skipped
 	// because of the TrimSpace check, the space won't be appended.
@@ -1,35 +1,75 @@
 package pipeline
 
 import (
+	"context"
 	"fmt"
-	"sort"
 	"strings"
 	"unicode/utf8"
 
+	"scribe2srt/internal/bidi"
 	"scribe2srt/internal/config"
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/subtitle"
+	"scribe2srt/internal/timecode"
 )
 
+// Report describes the corrections the pipeline made to the transcript.
+type Report struct {
+	Substitutions []Substitution
+	// Masked is the number of profanity matches masked by the filter.
+	Masked int
+}
+
 // Process runs the full two-stage subtitle pipeline on a transcript and
-// returns the SRT content string.
+// returns the subtitle file content in settings.Format. It is meant for
+// settings that cannot fail, such as SRT output without translation: if a
+// stage fails the result is empty, so callers using translation or the STL
+// and SCC formats use ProcessWithReport or ProcessContext to get the error.
 func Process(transcript *TranscriptResponse, settings *config.SubtitleSettings) string {
-	langCode := transcript.LanguageCode
-	if len(langCode) > 3 {
-		langCode = langCode[:3]
-	}
+	srt, _, _ := ProcessWithReport(transcript, settings)
+	return srt
+}
+
+// ProcessWithReport is Process that also reports the corrections made and
+// returns the error of a failed stage. Stages that call external services,
+// such as translation, run without a deadline.
+func ProcessWithReport(transcript *TranscriptResponse, settings *config.SubtitleSettings) (string, Report, error) {
+	return ProcessContext(context.Background(), transcript, settings)
+}
+
+// ProcessContext is ProcessWithReport with a context for the stages that
+// call external services, and an error if one of them fails.
+func ProcessContext(ctx context.Context, transcript *TranscriptResponse, settings *config.SubtitleSettings) (string, Report, error) {
+	var report Report
+	langCode := lang.Normalize(transcript.LanguageCode)
 	isCJK := config.IsCJK(langCode)
+	base := settings
+	settings = fitRows(settings.ForLanguage(langCode))
 
-	maxCPL := settings.LatinCharsPerLine
-	if isCJK {
-		maxCPL = settings.CJKCharsPerLine
-	}
+	// Line limits are resolved per cue from the dominant script of its text;
+	// the transcript language only decides text without letters.
+	limits := newScriptLimits(settings, isCJK)
 
-	// Preprocess words.
-	result := preprocessWords(transcript.Words)
+	// Preprocess words. Clean verbatim is opt-in; full verbatim is the
+	// default for legal and transcription work.
+	var cleaner *verbatimCleaner
+	if settings.CleanVerbatim {
+		cleaner = newVerbatimCleaner(langCode, settings.Fillers)
+	}
+	result := preprocessWords(transcript.Words, cleaner)
 
 	if len(result.Words) == 0 && len(result.AudioEvents) == 0 {
-		return ""
+		return "", report, nil
 	}
 
+	// Variant conversion, glossary corrections and profanity masking run
+	// before splitting so that reading speed and layout are measured on the
+	// final text. Conversion comes first so glossary rules are written in
+	// the target variant.
+	result.Words = convertChinese(result.Words, settings.ZhConverter, langCode)
+	result.Words, report.Substitutions = applyGlossary(result.Words, settings.Glossary)
+	result.Words, report.Masked = maskProfanity(result.Words, settings.Profanity, langCode)
+
 	// Stage 1: sentence splitting.
 	var basicEntries []SubtitleEntry
 	if len(result.Words) > 0 {
@@ -39,42 +79,109 @@ func Process(transcript *TranscriptResponse, settings *config.SubtitleSettings)
 	}
 
 	// Audio event entries.
-	audioEntries := createAudioEventEntries(result.AudioEvents)
+	audioEntries := createAudioEventEntries(applyAudioEventPolicy(result.AudioEvents, settings.AudioEvents))
+	snapEntries(audioEntries, settings.FrameRate)
 
-	// Stage 2: intelligent merging.
-	var mergedEntries []SubtitleEntry
-	if len(basicEntries) > 0 {
-		// Build subtitle settings for the merger, matching Python's create_srt logic.
-		mergerSettings := &config.SubtitleSettings{
-			MinSubtitleDuration: settings.MinSubtitleDuration,
-			MaxSubtitleDuration: settings.MaxSubtitleDuration,
-			MinSubtitleGap:      settings.MinSubtitleGap,
-		}
-		if isCJK {
-			mergerSettings.CJKCPS = settings.CJKCPS
-			mergerSettings.LatinCPS = config.CPSForLang("en")
-			mergerSettings.CJKCharsPerLine = settings.CJKCharsPerLine
-			mergerSettings.LatinCharsPerLine = config.CPLForLang("en")
-		} else {
-			mergerSettings.CJKCPS = config.CPSForLang("zh")
-			mergerSettings.LatinCPS = settings.LatinCPS
-			mergerSettings.CJKCharsPerLine = config.CPLForLang("zh")
-			mergerSettings.LatinCharsPerLine = settings.LatinCharsPerLine
+	// Sentence-level translation replaces the sentences with cues in the
+	// target language before merging, so the merger applies the target
+	// language's limits to them.
+	tr := settings.Translation
+	bySentence := tr != nil && tr.Unit == config.TranslateSentences
+	var target *config.SubtitleSettings
+	if tr != nil {
+		target = fitRows(base.ForLanguage(tr.Target))
+	}
+	mergeLang, mergeSettings := langCode, settings
+	var untranslated []SubtitleEntry
+	if bySentence {
+		var err error
+		basicEntries, untranslated, err = translateSentences(ctx, basicEntries, tr, langCode, target)
+		if err != nil {
+			return "", report, err
 		}
+		mergeLang, mergeSettings = tr.Target, target
+	}
 
-		merger := NewIntelligentMerger(langCode, mergerSettings)
+	// Stage 2: intelligent merging. Code-switched transcripts use both the
+	// CJK and the Latin limits, so the merger gets the full settings rather
+	// than only the transcript language's half.
+	merger := NewIntelligentMerger(mergeLang, mergeSettings)
+	var mergedEntries []SubtitleEntry
+	if len(basicEntries) > 0 {
 		mergedEntries = merger.MergeBasicEntries(basicEntries)
 		mergedEntries = merger.OptimizeMergedEntries(mergedEntries)
 	}
+	if bySentence && tr.Bilingual {
+		attachSourceText(mergedEntries, result.Words, untranslated)
+	}
+
+	// Combine with audio events and remove overlaps.
+	all := merger.ResolveTiming(mergedEntries, audioEntries, settings.AudioOverlap)
+
+	// Cue-level translation keeps the timing; the translated text is laid
+	// out with the limits of the target language's script.
+	opts := outputOptions{
+		Limits:     limits,
+		RTL:        settings.RTLMode,
+		Format:     settings.Format,
+		Karaoke:    settings.Karaoke,
+		LRCEvents:  settings.LRCEvents,
+		Title:      settings.Title,
+		Artist:     settings.Artist,
+		Lang:       lang.BCP47(langCode),
+		FrameRate:  settings.FrameRate,
+		TTMLTiming: settings.TTMLTiming,
+		STL:        settings.STL,
+	}
+	if tr != nil {
+		if !bySentence {
+			if err := translateEntries(ctx, all, tr, langCode); err != nil {
+				return "", report, err
+			}
+		}
+		opts.Target = newScriptLimits(target, config.IsCJK(tr.Target))
+		opts.Bilingual = tr.Bilingual
+		if bySentence && !tr.Bilingual {
+			opts.Limits = opts.Target
+		}
+		if !tr.Bilingual {
+			opts.Lang = lang.BCP47(tr.Target)
+		}
+	}
 
-	// Combine and sort.
-	all := append(mergedEntries, audioEntries...)
-	sort.Slice(all, func(i, j int) bool {
-		return all[i].Start < all[j].Start
-	})
+	// Write the subtitle file.
+	content, err := generateOutput(all, opts)
+	return content, report, err
+}
 
-	// Generate SRT.
-	return generateSRT(all, maxCPL)
+// fitRows returns settings with the line lengths capped at the row width of
+// output formats with fixed-width rows.
+func fitRows(settings *config.SubtitleSettings) *config.SubtitleSettings {
+	row := settings.MaxRowChars()
+	if row <= 0 || (settings.LatinCharsPerLine <= row && settings.CJKCharsPerLine <= row) {
+		return settings
+	}
+	fitted := *settings
+	fitted.LatinCharsPerLine = min(fitted.LatinCharsPerLine, row)
+	fitted.CJKCharsPerLine = min(fitted.CJKCharsPerLine, row)
+	return &fitted
+}
+
+// applyAudioEventPolicy drops and relabels audio events according to policy.
+func applyAudioEventPolicy(events []Word, policy *config.AudioEventPolicy) []Word {
+	if policy == nil {
+		return events
+	}
+	kept := make([]Word, 0, len(events))
+	for _, ev := range events {
+		text, ok := policy.Apply(ev.Text, ev.End-ev.Start)
+		if !ok {
+			continue
+		}
+		ev.Text = text
+		kept = append(kept, ev)
+	}
+	return kept
 }
 
 func createAudioEventEntries(events []Word) []SubtitleEntry {
@@ -94,7 +201,87 @@ func createAudioEventEntries(events []Word) []SubtitleEntry {
 	return entries
 }
 
-func generateSRT(entries []SubtitleEntry, maxCPL int) string {
+// outputOptions controls how the cues are laid out, timed and written.
+type outputOptions struct {
+	Limits scriptLimits
+	RTL    bidi.Mode
+	// Target lays out translations. Bilingual writes the source text above
+	// the translation instead of the translation alone.
+	Target    scriptLimits
+	Bilingual bool
+	// Format is the file format written. Karaoke marks the start of each
+	// word of the source lines in ASS and WebVTT cues.
+	Format  config.OutputFormat
+	Karaoke bool
+	// LRCEvents, Title and Artist are used by the LRC writer.
+	LRCEvents     config.LRCEvents
+	Title, Artist string
+	// Lang is the language tag of the text written, FrameRate the frame
+	// rate declared and TTMLTiming the time expressions of TTML output.
+	Lang       string
+	FrameRate  timecode.Rate
+	TTMLTiming subtitle.TTMLTiming
+	// STL holds the header fields and display options of EBU STL output.
+	STL config.STLSettings
+}
+
+// cueText lays out the text of entry: the source lines, the translation
+// below or instead of them and stacked audio events above. escape prepares
+// plain text for the output format. tags, when non-nil, returns the timing
+// tags inserted before each word of the source lines; a cue whose words
+// cannot be found in its text is written without them.
+func (o outputOptions) cueText(entry SubtitleEntry, escape func(string) string, tags func(marks []wordMark, start, end float64) []string) string {
+	source := bidi.ApplyLines(optimizeEntryDisplay(entry, o.Limits.cpl(entry.Text), o.Limits.WidthMode), o.RTL)
+	showSource := entry.Translation == "" || o.Bilingual
+
+	text := escape(source)
+	if tags != nil && showSource {
+		if marks, ok := wordMarks(source, entry.Words); ok && len(marks) > 0 {
+			text = karaokeText(source, marks, tags(marks, entry.Start, entry.End), escape)
+		}
+	}
+	if entry.Translation != "" {
+		translated := optimizeTextDisplay(entry.Translation, o.Target.cpl(entry.Translation), o.Target.WidthMode)
+		translated = escape(bidi.ApplyLines(translated, o.RTL))
+		if showSource {
+			text += "\n" + translated
+		} else {
+			text = translated
+		}
+	}
+	if entry.EventText != "" {
+		text = escape(bidi.ApplyLines(entry.EventText, o.RTL)) + "\n" + text
+	}
+	return text
+}
+
+func plainText(s string) string { return s }
+
+// generateOutput writes entries in the format of opts, or returns "" when
+// there are none. Only broadcast formats whose character sets cannot hold
+// every character fail.
+func generateOutput(entries []SubtitleEntry, opts outputOptions) (string, error) {
+	if len(entries) == 0 {
+		return "", nil
+	}
+	switch opts.Format {
+	case config.FormatASS:
+		return generateASS(entries, opts), nil
+	case config.FormatVTT:
+		return generateVTT(entries, opts), nil
+	case config.FormatLRC:
+		return generateLRC(entries, opts), nil
+	case config.FormatTTML, config.FormatDFXP:
+		return generateTTML(entries, opts), nil
+	case config.FormatSTL:
+		return generateSTL(entries, opts)
+	case config.FormatSCC:
+		return generateSCC(entries, opts)
+	}
+	return generateSRT(entries, opts), nil
+}
+
+func generateSRT(entries []SubtitleEntry, opts outputOptions) string {
 	if len(entries) == 0 {
 		return ""
 	}
@@ -103,7 +290,7 @@ func generateSRT(entries []SubtitleEntry, maxCPL int) string {
 	for i, entry := range entries {
 		startStr := formatSRTTime(entry.Start)
 		endStr := formatSRTTime(entry.End)
-		text := optimizeTextDisplay(entry.Text, maxCPL)
+		text := opts.cueText(entry, plainText, nil)
 
 		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n", i+1, startStr, endStr, text)
 		if i < len(entries)-1 {
@@ -112,3 +299,38 @@ func generateSRT(entries []SubtitleEntry, maxCPL int) string {
 	}
 	return sb.String()
 }
+
+// generateASS writes entries as an ASS script with a single bottom-aligned
+// style. With karaoke, each word is preceded by a {\k} tag; words are shown
+// in the style's secondary colour until they are sung.
+func generateASS(entries []SubtitleEntry, opts outputOptions) string {
+	var tags func([]wordMark, float64, float64) []string
+	if opts.Karaoke {
+		tags = assKaraokeTags
+	}
+	style := subtitle.DefaultASSStyle("Default")
+	events := make([]subtitle.ASSEvent, len(entries))
+	for i, entry := range entries {
+		events[i] = subtitle.ASSEvent{
+			Start: entry.Start,
+			End:   entry.End,
+			Style: style.Name,
+			Text:  opts.cueText(entry, plainText, tags),
+		}
+	}
+	return subtitle.FormatASS("", []subtitle.ASSStyle{style}, events)
+}
+
+// generateVTT writes entries as WebVTT. With karaoke, each word after the
+// first is preceded by a timestamp tag.
+func generateVTT(entries []SubtitleEntry, opts outputOptions) string {
+	var tags func([]wordMark, float64, float64) []string
+	if opts.Karaoke {
+		tags = vttKaraokeTags
+	}
+	cues := make([]subtitle.Cue, len(entries))
+	for i, entry := range entries {
+		cues[i] = subtitle.Cue{Start: entry.Start, End: entry.End, Text: opts.cueText(entry, subtitle.EscapeVTT, tags)}
+	}
+	return subtitle.FormatVTT(cues)
+}
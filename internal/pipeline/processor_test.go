@@ -3,7 +3,9 @@ package pipeline
 import (
 	"strings"
 	"testing"
+	"unicode/utf8"
 
+	"scribe2srt/internal/bidi"
 	"scribe2srt/internal/config"
 )
 
@@ -19,6 +21,16 @@ func defaultSettings() *config.SubtitleSettings {
 	}
 }
 
+// mustGenerate is generateOutput for formats that cannot fail.
+func mustGenerate(t *testing.T, entries []SubtitleEntry, opts outputOptions) string {
+	t.Helper()
+	out, err := generateOutput(entries, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
 func TestProcess_Empty(t *testing.T) {
 	transcript := &TranscriptResponse{
 		LanguageCode: "en",
@@ -162,7 +174,7 @@ func TestProcess_OnlyAudioEvents(t *testing.T) {
 }
 
 func TestGenerateSRT_Empty(t *testing.T) {
-	result := generateSRT(nil, 42)
+	result := generateSRT(nil, outputOptions{Limits: scriptLimits{LatinCPL: 42}, RTL: bidi.None})
 	if result != "" {
 		t.Errorf("expected empty string for nil entries, got %q", result)
 	}
@@ -184,3 +196,45 @@ func TestCreateAudioEventEntries(t *testing.T) {
 		t.Errorf("Text = %q, want '(music)'", entries[0].Text)
 	}
 }
+
+func TestProcess_RTLMarks(t *testing.T) {
+	transcript := &TranscriptResponse{
+		LanguageCode: "ar",
+		Words: []Word{
+			{Text: "\u0645\u0631\u062d\u0628\u0627 ", Start: 0, End: 0.5, Type: "word"}, // مرحبا
+			{Text: "\u0628\u0643\u0645\u061f", Start: 0.5, End: 1.0, Type: "word"},      // بكم؟
+		},
+	}
+
+	settings := defaultSettings()
+	settings.RTLMode = bidi.RLM
+	result := Process(transcript, settings)
+	if !strings.Contains(result, "\u200f\u0645\u0631\u062d\u0628\u0627 \u0628\u0643\u0645\u061f") {
+		t.Errorf("expected the RTL line to start with an RLM, got:\n%q", result)
+	}
+
+	settings.RTLMode = bidi.None
+	result = Process(transcript, settings)
+	if strings.Contains(result, "\u200f") {
+		t.Errorf("expected no directional marks with RTLMode none, got:\n%q", result)
+	}
+}
+
+func TestProcess_LanguageLimits(t *testing.T) {
+	// Twelve Thai words over six seconds, written without spaces.
+	transcript := &TranscriptResponse{LanguageCode: "th"}
+	for i := range 12 {
+		transcript.Words = append(transcript.Words, Word{Text: "\u0e2a\u0e27\u0e31\u0e2a\u0e14\u0e35", Start: float64(i) * 0.5, End: float64(i)*0.5 + 0.45, Type: "word"}) // สวัสดี
+	}
+	settings := defaultSettings()
+	settings.LanguageLimits = config.AllLimits
+
+	_, texts := srtCues(Process(transcript, settings))
+	for _, text := range texts {
+		for _, line := range strings.Split(text, "\n") {
+			if n := utf8.RuneCountInString(line); n > 35 {
+				t.Errorf("line %q has %d characters, more than Thai's 35", line, n)
+			}
+		}
+	}
+}
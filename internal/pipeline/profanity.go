@@ -0,0 +1,25 @@
+package pipeline
+
+import "scribe2srt/internal/profanity"
+
+// maskProfanity masks listed words in the transcript text formed by words
+// and returns the number of matches masked. Matching runs over the joined
+// text, so entries of several words and entries split across recognised
+// words are found; a match spanning several words collapses them into one,
+// as glossary corrections do. It runs before splitting, so line length and
+// reading speed are measured on the masked text.
+func maskProfanity(words []Word, f *profanity.Filter, langCode string) ([]Word, int) {
+	if f == nil || len(words) == 0 {
+		return words, 0
+	}
+	text, offsets := joinWordText(words)
+	matches := f.Find(text, langCode)
+	if len(matches) == 0 {
+		return words, 0
+	}
+	spans := make([]textSpan, len(matches))
+	for i, m := range matches {
+		spans[i] = textSpan{Start: m.Start, End: m.End, Replacement: m.Replacement}
+	}
+	return rewriteWords(words, text, offsets, spans), len(matches)
+}
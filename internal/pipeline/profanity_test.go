@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/profanity"
+)
+
+func TestMaskProfanity(t *testing.T) {
+	f := profanity.New(profanity.FirstLetter, "")
+	f.AddList("en", []string{"damn"})
+	words := []Word{
+		{Text: "Oh ", Start: 0, End: 0.2},
+		{Text: "damn.", Start: 0.2, End: 0.6},
+	}
+
+	got, n := maskProfanity(words, f, "en")
+	if n != 1 || got[1].Text != "d***." {
+		t.Errorf("maskProfanity() = %+v, %d", got, n)
+	}
+	if words[1].Text != "damn." {
+		t.Error("maskProfanity modified its input")
+	}
+}
+
+func TestMaskProfanity_AcrossWords(t *testing.T) {
+	f := profanity.New(profanity.Asterisks, "")
+	f.AddList("", []string{"son of a bitch"})
+	f.AddList("ja", []string{"\u304f\u305d\u91ce\u90ce"}) // くそ野郎
+
+	words := []Word{
+		{Text: "You ", Start: 0, End: 0.2},
+		{Text: "son ", Start: 0.2, End: 0.4},
+		{Text: "of ", Start: 0.4, End: 0.5},
+		{Text: "a ", Start: 0.5, End: 0.6},
+		{Text: "bitch.", Start: 0.6, End: 1.0},
+	}
+	got, n := maskProfanity(words, f, "en")
+	if n != 1 || len(got) != 2 || got[1].Text != "*** ** * *****." || got[1].Start != 0.2 || got[1].End != 1.0 {
+		t.Errorf("maskProfanity() = %+v, %d", got, n)
+	}
+
+	// The recogniser split くそ野郎 into くそ and 野郎.
+	words = []Word{
+		{Text: "\u304f\u305d", Start: 0, End: 0.3},         // くそ
+		{Text: "\u91ce\u90ce\uff01", Start: 0.3, End: 0.8}, // 野郎！
+	}
+	got, n = maskProfanity(words, f, "ja")
+	if n != 1 || len(got) != 1 || got[0].Text != "****\uff01" || got[0].End != 0.8 { // ****！
+		t.Errorf("maskProfanity() = %+v, %d", got, n)
+	}
+}
+
+func TestProcess_ProfanityCountsForLayout(t *testing.T) {
+	// "[bleep]" is longer than the word it replaces, so the masked line no
+	// longer fits in one line.
+	f := profanity.New(profanity.Replace, "[bleep]")
+	f.AddList("", []string{"heck"})
+
+	transcript := &TranscriptResponse{
+		LanguageCode: "en",
+		Words: []Word{
+			{Text: "What ", Start: 0, End: 0.5, Type: "word"},
+			{Text: "the ", Start: 0.5, End: 1.0, Type: "word"},
+			{Text: "heck.", Start: 1.0, End: 3.0, Type: "word"},
+		},
+	}
+	settings := defaultSettings()
+	settings.LatinCharsPerLine = 14
+	settings.Profanity = f
+
+	result, report, err := ProcessWithReport(transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Masked != 1 {
+		t.Errorf("Masked = %d, want 1", report.Masked)
+	}
+	if !strings.Contains(result, "What the\n[bleep].") {
+		t.Errorf("expected the masked text to be wrapped, got:\n%s", result)
+	}
+}
@@ -0,0 +1,110 @@
+package pipeline
+
+import (
+	"strings"
+	"unicode"
+
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/textwidth"
+)
+
+// segmentProfile describes how a script marks the boundaries the splitter can
+// use in addition to punctuation.
+type segmentProfile struct {
+	// PhraseSplits enables splitting at spaces and pauses. Scripts written
+	// without spaces between words (Thai, Lao, Khmer, Burmese) use spaces to
+	// separate phrases and rarely use sentence punctuation.
+	PhraseSplits bool
+	// SpaceMinChars is the number of characters a group needs before a
+	// space ends it.
+	SpaceMinChars int
+	// PauseGap is the silence, in seconds, between two words that ends a
+	// group once it has PauseMinChars characters.
+	PauseGap      float64
+	PauseMinChars int
+	// MaxChars forces a split at the next word boundary once a group is
+	// this long, so an unpunctuated run never becomes one giant cue.
+	MaxChars int
+}
+
+// defaultProfile splits on punctuation only.
+var defaultProfile = segmentProfile{}
+
+// noSpaceProfile is used for Southeast Asian scripts written without spaces
+// between words.
+var noSpaceProfile = segmentProfile{
+	PhraseSplits:  true,
+	SpaceMinChars: 12,
+	PauseGap:      0.4,
+	PauseMinChars: 4,
+	MaxChars:      60,
+}
+
+// profileForLanguage returns the segmentation profile for a language code.
+func profileForLanguage(code string) segmentProfile {
+	l, ok := lang.Lookup(code)
+	if ok && !l.SpaceDelimited && !l.IsCJK() {
+		return noSpaceProfile
+	}
+	return defaultProfile
+}
+
+// isSoutheastAsianRune reports whether r belongs to the Thai, Lao, Khmer or
+// Myanmar script.
+func isSoutheastAsianRune(r rune) bool {
+	return unicode.In(r, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+}
+
+// shouldSplitAtPhrase decides whether to end the current group after word
+// using the phrase rules of the profile. next is the following word, or nil
+// at the end of the transcript.
+func (p segmentProfile) shouldSplitAtPhrase(word Word, next *Word, current []Word) bool {
+	if !p.PhraseSplits {
+		return false
+	}
+
+	chars := 0
+	for _, w := range current {
+		chars += stripWhitespaceCount(w.Text, textwidth.Runes)
+	}
+
+	if chars >= p.MaxChars {
+		return true
+	}
+	if next != nil && next.Start-word.End >= p.PauseGap && chars >= p.PauseMinChars {
+		return true
+	}
+	if strings.HasSuffix(word.Text, " ") && chars >= p.SpaceMinChars {
+		return true
+	}
+	return false
+}
+
+// wordBoundaries returns the rune offsets in text at which one of words ends
+// and the next begins. They are the preferred line break positions for text
+// without spaces between words.
+func wordBoundaries(text string, words []Word) []int {
+	var boundaries []int
+	byteOffset, runeOffset := 0, 0
+
+	for _, w := range words {
+		token := strings.TrimSpace(w.Text)
+		if token == "" {
+			continue
+		}
+		idx := strings.Index(text[byteOffset:], token)
+		if idx < 0 {
+			continue
+		}
+		end := byteOffset + idx + len(token)
+		runeOffset += len([]rune(text[byteOffset:end]))
+		byteOffset = end
+		boundaries = append(boundaries, runeOffset)
+	}
+
+	// The last boundary is the end of the text, not a break position.
+	if n := len(boundaries); n > 0 && boundaries[n-1] >= len([]rune(text)) {
+		boundaries = boundaries[:n-1]
+	}
+	return boundaries
+}
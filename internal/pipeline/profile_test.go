@@ -0,0 +1,116 @@
+package pipeline
+
+import (
+	"reflect"
+	"testing"
+
+	"scribe2srt/internal/textwidth"
+)
+
+func TestProfileForLanguage(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"th", true},
+		{"lo", true},
+		{"khm", true},
+		{"my", true},
+		{"en", false},
+		{"ja", false},
+		{"auto", false},
+	}
+
+	for _, tt := range tests {
+		got := NewSentenceSplitter(tt.code).Profile.PhraseSplits
+		if got != tt.want {
+			t.Errorf("NewSentenceSplitter(%q).Profile.PhraseSplits = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestSentenceSplitter_ThaiSplitsAtPause(t *testing.T) {
+	s := NewSentenceSplitter("th")
+
+	// วันนี้ อากาศ ดี มาก | ไป เที่ยว กัน — a 1s pause before the second phrase.
+	words := []Word{
+		{Text: "\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49", Start: 0, End: 0.4, Type: "word"},
+		{Text: "\u0e2d\u0e32\u0e01\u0e32\u0e28", Start: 0.4, End: 0.8, Type: "word"},
+		{Text: "\u0e14\u0e35", Start: 0.8, End: 1.0, Type: "word"},
+		{Text: "\u0e21\u0e32\u0e01", Start: 1.0, End: 1.3, Type: "word"},
+		{Text: "\u0e44\u0e1b", Start: 2.3, End: 2.5, Type: "word"},
+		{Text: "\u0e40\u0e17\u0e35\u0e48\u0e22\u0e27", Start: 2.5, End: 2.9, Type: "word"},
+		{Text: "\u0e01\u0e31\u0e19", Start: 2.9, End: 3.1, Type: "word"},
+	}
+
+	groups := s.SplitIntoSentenceGroups(words)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups split at the pause, got %d", len(groups))
+	}
+	if len(groups[0]) != 4 {
+		t.Errorf("first group has %d words, want 4", len(groups[0]))
+	}
+
+	// English has no phrase rules, so the same timings stay one group.
+	if got := NewSentenceSplitter("en").SplitIntoSentenceGroups(words); len(got) != 1 {
+		t.Errorf("en: expected 1 group, got %d", len(got))
+	}
+}
+
+func TestSentenceSplitter_ThaiSplitsAtSpace(t *testing.T) {
+	s := NewSentenceSplitter("th")
+
+	// สวัสดีครับทุกคน ยินดีต้อนรับ — the space marks a phrase boundary.
+	words := []Word{
+		{Text: "\u0e2a\u0e27\u0e31\u0e2a\u0e14\u0e35", Start: 0, End: 0.5, Type: "word"},
+		{Text: "\u0e04\u0e23\u0e31\u0e1a", Start: 0.5, End: 0.7, Type: "word"},
+		{Text: "\u0e17\u0e38\u0e01\u0e04\u0e19 ", Start: 0.7, End: 1.0, Type: "word"},
+		{Text: "\u0e22\u0e34\u0e19\u0e14\u0e35", Start: 1.0, End: 1.3, Type: "word"},
+		{Text: "\u0e15\u0e49\u0e2d\u0e19\u0e23\u0e31\u0e1a", Start: 1.3, End: 1.7, Type: "word"},
+	}
+
+	groups := s.SplitIntoSentenceGroups(words)
+	if len(groups) != 2 {
+		t.Fatalf("expected 2 groups split at the space, got %d", len(groups))
+	}
+}
+
+func TestJoinTexts_Thai(t *testing.T) {
+	got := joinTexts("\u0e2a\u0e27\u0e31\u0e2a\u0e14\u0e35\u0e04\u0e23\u0e31\u0e1a", "\u0e17\u0e38\u0e01\u0e04\u0e19") // สวัสดีครับ + ทุกคน
+	if got != "\u0e2a\u0e27\u0e31\u0e2a\u0e14\u0e35\u0e04\u0e23\u0e31\u0e1a\u0e17\u0e38\u0e01\u0e04\u0e19" {
+		t.Errorf("joinTexts = %q, want Thai text joined without a space", got)
+	}
+}
+
+func TestWordBoundaries(t *testing.T) {
+	words := []Word{
+		{Text: "\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49", Type: "word"}, // วันนี้ (6 runes)
+		{Text: "\u0e2d\u0e32\u0e01\u0e32\u0e28", Type: "word"},       // อากาศ (5 runes)
+		{Text: "\u0e14\u0e35", Type: "word"},                         // ดี (2 runes)
+	}
+	got := wordBoundaries("\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49\u0e2d\u0e32\u0e01\u0e32\u0e28\u0e14\u0e35", words)
+	want := []int{6, 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wordBoundaries = %v, want %v", got, want)
+	}
+}
+
+func TestOptimizeEntryDisplay_BreaksBetweenWords(t *testing.T) {
+	entry := SubtitleEntry{
+		Text: "\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49\u0e2d\u0e32\u0e01\u0e32\u0e28\u0e14\u0e35\u0e21\u0e32\u0e01", // วันนี้อากาศดีมาก
+		Words: []Word{
+			{Text: "\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49", Type: "word"},
+			{Text: "\u0e2d\u0e32\u0e01\u0e32\u0e28", Type: "word"},
+			{Text: "\u0e14\u0e35", Type: "word"},
+			{Text: "\u0e21\u0e32\u0e01", Type: "word"},
+		},
+	}
+
+	// A limit of 9 runes falls inside อากาศ; the break moves back to the
+	// end of วันนี้.
+	got := optimizeEntryDisplay(entry, 9, textwidth.Runes)
+	want := "\u0e27\u0e31\u0e19\u0e19\u0e35\u0e49\n\u0e2d\u0e32\u0e01\u0e32\u0e28\u0e14\u0e35\u0e21\u0e32\u0e01"
+	if got != want {
+		t.Errorf("optimizeEntryDisplay = %q, want %q", got, want)
+	}
+}
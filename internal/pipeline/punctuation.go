@@ -3,6 +3,8 @@ package pipeline
 import (
 	"strings"
 	"unicode/utf8"
+
+	"scribe2srt/internal/textwidth"
 )
 
 // Punctuation priority levels.
@@ -16,16 +18,22 @@ const (
 var highPriority = map[rune]struct{}{
 	'.': {}, '!': {}, '?': {},
 	'\u3002': {}, '\uff01': {}, '\uff1f': {}, // 。！？
+	'\u104b': {}, '\u17d4': {}, '\u17d5': {}, // ။ ។ ៕ (Burmese, Khmer)
+	'\u061f': {}, '\u06d4': {}, // ؟ ۔ (Arabic, Urdu)
 }
 
 var mediumPriority = map[rune]struct{}{
 	';': {}, ':': {}, ')': {}, ']': {}, '}': {},
 	'\uff1b': {}, '\uff1a': {}, '\u300b': {}, '\u300d': {}, '\u3011': {}, '\uff09': {}, // ；：》」】）
+	'\u17d6': {}, // ៖ (Khmer)
+	'\u061b': {}, // ؛ (Arabic)
 }
 
 var lowPriority = map[rune]struct{}{
 	',': {}, '(': {}, '[': {}, '{': {}, '-': {},
 	'\uff0c': {}, '\u3001': {}, '\u300a': {}, '\u300c': {}, '\u3010': {}, '\uff08': {}, // ，、《「【（
+	'\u104a': {}, // ၊ (Burmese)
+	'\u060c': {}, // ، (Arabic)
 }
 
 // allPunctuation is the union of all priority sets.
@@ -90,35 +98,105 @@ func wordEndsWithPunctuation(text string) (bool, rune, int) {
 	return false, 0, priorityNone
 }
 
-// findSplitPosition finds the best position to split text at or before maxLen (in runes).
-// Returns a rune-index for the split point.
-func findSplitPosition(text string, maxLen int) int {
+// findSplitPosition finds the best position to split text so that the first
+// line is at most maxLen wide, as measured by mode. Returns a rune-index for
+// the split point. Break candidates are spaces and punctuation; any candidate
+// that would start the second line with a closing mark or small kana, or end
+// the first line with an opening bracket, is rejected (kinsoku shori), as is
+// any position inside a grapheme cluster. When no candidate remains, CJK text
+// is broken after a grammatical particle before falling back to maxLen.
+func findSplitPosition(text string, maxLen int, mode textwidth.Mode) int {
+	return findSplitPositionAt(text, maxLen, mode, nil)
+}
+
+// findSplitPositionAt is findSplitPosition with the rune offsets of the
+// transcript's word boundaries as extra candidates. They are tried after
+// spaces, punctuation and particles, so text written without spaces between
+// words breaks between words rather than at an arbitrary rune.
+func findSplitPositionAt(text string, maxLen int, mode textwidth.Mode, wordBreaks []int) int {
 	runes := []rune(text)
-	if len(runes) <= maxLen {
+	limit := runeLimit(runes, maxLen, mode)
+	if limit >= len(runes) {
 		return len(runes)
 	}
 
-	searchEnd := min(maxLen+1, len(runes))
+	breakable := func(pos int) bool {
+		return mode.IsClusterBoundary(runes, pos) && canBreakAt(runes, pos)
+	}
+
+	searchEnd := min(limit+1, len(runes))
 
 	bestPos := -1
 	for i := searchEnd - 1; i > 0; i-- {
 		r := runes[i]
-		if r == ' ' {
-			bestPos = i
-			break
+		pos := -1
+		switch {
+		case r == ' ':
+			pos = i
+		case isNoLineEnd(r):
+			// Break before an opening bracket so it stays with its content.
+			pos = i
+		case isPunctuation(r):
+			pos = i + 1
 		}
-		if isPunctuation(r) {
-			bestPos = i + 1
+		if pos > 0 && breakable(pos) {
+			bestPos = pos
 			break
 		}
 	}
 
 	if bestPos <= 0 {
-		bestPos = maxLen
+		bestPos = findParticleBreak(runes, limit, breakable)
+	}
+
+	if bestPos <= 0 {
+		for i := len(wordBreaks) - 1; i >= 0; i-- {
+			if pos := wordBreaks[i]; pos > 0 && pos <= limit && breakable(pos) {
+				bestPos = pos
+				break
+			}
+		}
+	}
+
+	if bestPos <= 0 {
+		bestPos = limit
+		for bestPos > 1 && !breakable(bestPos) {
+			bestPos--
+		}
+		if bestPos <= 1 {
+			bestPos = max(limit, 1)
+		}
 	}
 	return bestPos
 }
 
+// runeLimit returns the number of leading runes that fit within maxLen width
+// units, or len(runes) if the whole text fits.
+func runeLimit(runes []rune, maxLen int, mode textwidth.Mode) int {
+	if mode == textwidth.Runes {
+		return min(maxLen, len(runes))
+	}
+	width := 0
+	for i, w := range mode.RuneWidths(runes) {
+		if width+w > maxLen {
+			return i
+		}
+		width += w
+	}
+	return len(runes)
+}
+
+// findParticleBreak returns the position just after the last particle in the
+// second half of runes[:limit], or -1 if there is none.
+func findParticleBreak(runes []rune, limit int, breakable func(int) bool) int {
+	for pos := limit; pos > limit/2; pos-- {
+		if isBreakAfterParticle(runes[pos-1]) && breakable(pos) {
+			return pos
+		}
+	}
+	return -1
+}
+
 // mergeJoinPunctuation contains punctuation characters that suppress space insertion
 // when joining two subtitle texts. Matches the Python set in _merge_two_entries.
 var mergeJoinPunctuation = map[rune]struct{}{
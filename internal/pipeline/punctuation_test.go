@@ -2,6 +2,8 @@ package pipeline
 
 import (
 	"testing"
+
+	"scribe2srt/internal/textwidth"
 )
 
 func TestGetPriority(t *testing.T) {
@@ -118,7 +120,7 @@ func TestFindSplitPosition(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		got := findSplitPosition(tt.text, tt.maxLen)
+		got := findSplitPosition(tt.text, tt.maxLen, textwidth.Runes)
 		if got != tt.want {
 			t.Errorf("findSplitPosition(%q, %d) = %d, want %d", tt.text, tt.maxLen, got, tt.want)
 		}
@@ -145,3 +147,90 @@ func TestEndsWithJoinPunctuation(t *testing.T) {
 		}
 	}
 }
+
+func TestFindSplitPosition_Kinsoku(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		maxLen int
+		want   int
+	}{
+		// あいうえお「かきくけこ」: break before 「 rather than after it.
+		{"opening bracket", "\u3042\u3044\u3046\u3048\u304a\u300c\u304b\u304d\u304f\u3051\u3053\u300d", 6, 5},
+		// あいうえおかっきくけこ: っ must not start the second line.
+		{"small kana", "\u3042\u3044\u3046\u3048\u304a\u304b\u3063\u304d\u304f\u3051\u3053", 6, 5},
+		// あいうえお。」かきく: the closing marks hang on the first line.
+		{"hanging punctuation", "\u3042\u3044\u3046\u3048\u304a\u3002\u300d\u304b\u304d\u304f", 6, 7},
+		// わたしはがっこうにいきます: break after the particle に.
+		{"japanese particle", "\u308f\u305f\u3057\u306f\u304c\u3063\u3053\u3046\u306b\u3044\u304d\u307e\u3059", 10, 9},
+		// 我今天去了北京看朋友: break after 了.
+		{"chinese particle", "\u6211\u4eca\u5929\u53bb\u4e86\u5317\u4eac\u770b\u670b\u53cb", 6, 5},
+		// Latin text keeps breaking at spaces.
+		{"latin", "Hello (world) foo", 8, 6},
+	}
+
+	for _, tt := range tests {
+		got := findSplitPosition(tt.text, tt.maxLen, textwidth.Runes)
+		if got != tt.want {
+			t.Errorf("%s: findSplitPosition(%q, %d) = %d, want %d", tt.name, tt.text, tt.maxLen, got, tt.want)
+		}
+	}
+}
+
+func TestCanBreakAt(t *testing.T) {
+	runes := []rune("\u3042\u300c\u3044\u3002\u3046") // あ「い。う
+	tests := []struct {
+		pos  int
+		want bool
+	}{
+		{0, true},
+		{1, true},  // before 「
+		{2, false}, // after 「
+		{3, false}, // before 。
+		{4, true},  // after 。
+		{5, true},
+	}
+
+	for _, tt := range tests {
+		got := canBreakAt(runes, tt.pos)
+		if got != tt.want {
+			t.Errorf("canBreakAt(%d) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestFindSplitPosition_WidthModes(t *testing.T) {
+	// iPhone 15 の新機能 is 14 runes but 18 half-width units.
+	text := "iPhone 15 の新機能"
+
+	if got := findSplitPosition(text, 14, textwidth.Runes); got != 14 {
+		t.Errorf("runes: findSplitPosition = %d, want 14", got)
+	}
+	if got := findSplitPosition(text, 14, textwidth.EastAsian); got != 9 {
+		t.Errorf("eastasian: findSplitPosition = %d, want 9", got)
+	}
+
+	// A combining mark must stay with its base letter.
+	combining := "abcdéfgh" // abcdéfgh
+	if got := findSplitPosition(combining, 5, textwidth.Graphemes); got != 6 {
+		t.Errorf("graphemes: findSplitPosition = %d, want 6", got)
+	}
+}
+
+func TestGetPriority_Arabic(t *testing.T) {
+	tests := []struct {
+		r    rune
+		want int
+	}{
+		{'\u061f', priorityHigh},   // ؟
+		{'\u06d4', priorityHigh},   // ۔
+		{'\u061b', priorityMedium}, // ؛
+		{'\u060c', priorityLow},    // ،
+	}
+
+	for _, tt := range tests {
+		if got := getPriority(tt.r); got != tt.want {
+			t.Errorf("getPriority(%q) = %d, want %d", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+)
+
+// Anchoring thresholds for Resync. A script token anchors its cue when it is
+// aligned to a recognised token at least resyncMinSimilarity alike; a cue
+// is anchored when enough of its tokens are.
+const (
+	resyncMinSimilarity = 0.6
+	resyncMinAnchors    = 2
+	resyncMinShare      = 0.3
+)
+
+// ResyncReport describes how the cues of a subtitle file were retimed.
+type ResyncReport struct {
+	// Anchored is the number of cues timed from their own recognised words.
+	Anchored int
+	// Unanchored lists the indices of the cues whose text could not be
+	// found in the recognition, such as translations or paraphrases. They
+	// were moved by the shift of the anchored cues around them.
+	Unanchored []int
+}
+
+// Resync retimes cues against a transcript of the same media. The text of
+// all cues is aligned to the recognised words as one script; each cue whose
+// words are found takes their timing, and the others are shifted by the
+// drift interpolated between their anchored neighbours. Cue text and the
+// division into cues are kept; only start and end times change. Overlaps
+// the new timing creates are removed, keeping settings.MinSubtitleGap.
+func Resync(cues []subtitle.Cue, transcript *TranscriptResponse, settings *config.SubtitleSettings) ([]subtitle.Cue, ResyncReport, error) {
+	var report ResyncReport
+	if len(cues) == 0 {
+		return nil, report, fmt.Errorf("no cues to resync")
+	}
+	recognised := recognisedTokens(transcript.Words)
+	if len(recognised) == 0 {
+		return nil, report, fmt.Errorf("no words were recognised to resync the cues to")
+	}
+
+	// Tokens of all cues in order; cue i owns tokens first[i]..first[i+1]-1.
+	var tokens []alignToken
+	first := make([]int, len(cues)+1)
+	for i, c := range cues {
+		first[i] = len(tokens)
+		tokens = append(tokens, scriptTokens(c.Text)...)
+	}
+	first[len(cues)] = len(tokens)
+
+	aligned := alignSequences(tokens, recognised)
+
+	// anchor[t] is the recognised token script token t is anchored to, or -1.
+	var sim similarity
+	anchor := make([]int, len(tokens))
+	var anchoredChars int
+	var anchoredTime float64
+	for t, j := range aligned {
+		anchor[t] = -1
+		if j < 0 || sim.score(tokens[t].runes, recognised[j].runes) < resyncMinSimilarity {
+			continue
+		}
+		anchor[t] = j
+		anchoredChars += tokenChars(tokens[t])
+		anchoredTime += recognised[j].End - recognised[j].Start
+	}
+	perChar := anchoredTime / float64(max(anchoredChars, 1))
+
+	out := make([]subtitle.Cue, len(cues))
+	copy(out, cues)
+	isAnchored := make([]bool, len(cues))
+	for i := range cues {
+		lo, hi := first[i], first[i+1]
+		var count int
+		firstAnchor, lastAnchor := -1, -1
+		for t := lo; t < hi; t++ {
+			if anchor[t] < 0 {
+				continue
+			}
+			count++
+			if firstAnchor < 0 {
+				firstAnchor = t
+			}
+			lastAnchor = t
+		}
+		if count == 0 || count < min(resyncMinAnchors, hi-lo) || float64(count) < resyncMinShare*float64(hi-lo) {
+			report.Unanchored = append(report.Unanchored, i)
+			continue
+		}
+
+		// Words before the first and after the last anchor extend the
+		// cue at the average speaking rate.
+		var lead, trail int
+		for t := lo; t < firstAnchor; t++ {
+			lead += tokenChars(tokens[t])
+		}
+		for t := lastAnchor + 1; t < hi; t++ {
+			trail += tokenChars(tokens[t])
+		}
+		out[i].Start = max(recognised[anchor[firstAnchor]].Start-float64(lead)*perChar, 0)
+		out[i].End = recognised[anchor[lastAnchor]].End + float64(trail)*perChar
+		isAnchored[i] = true
+		report.Anchored++
+	}
+	if report.Anchored == 0 {
+		return nil, report, fmt.Errorf("none of the cues could be found in the recognised speech; is the subtitle in the spoken language?")
+	}
+
+	shiftUnanchored(out, cues, isAnchored)
+	removeResyncOverlaps(out, settings.MinSubtitleGap)
+	return out, report, nil
+}
+
+// shiftUnanchored moves each unanchored cue by the shift of the anchored
+// cues before and after it, interpolated by its original start time, so a
+// gradual drift is followed. Cues before the first or after the last
+// anchored cue take that cue's shift.
+func shiftUnanchored(out, orig []subtitle.Cue, isAnchored []bool) {
+	prev := -1
+	for i := range out {
+		if isAnchored[i] {
+			prev = i
+			continue
+		}
+		next := -1
+		for k := i + 1; k < len(out); k++ {
+			if isAnchored[k] {
+				next = k
+				break
+			}
+		}
+
+		var shift float64
+		switch {
+		case prev >= 0 && next >= 0:
+			sp := out[prev].Start - orig[prev].Start
+			sn := out[next].Start - orig[next].Start
+			frac := 0.0
+			if span := orig[next].Start - orig[prev].Start; span > 0 {
+				frac = min(max((orig[i].Start-orig[prev].Start)/span, 0), 1)
+			}
+			shift = sp + (sn-sp)*frac
+		case prev >= 0:
+			shift = out[prev].Start - orig[prev].Start
+		default:
+			shift = out[next].Start - orig[next].Start
+		}
+		out[i].Start = max(orig[i].Start+shift, 0)
+		out[i].End = max(orig[i].End+shift, out[i].Start)
+	}
+}
+
+// removeResyncOverlaps ends each cue gap seconds before the next one starts
+// when they would overlap. A cue too short to keep the gap ends where the
+// next one starts.
+func removeResyncOverlaps(cues []subtitle.Cue, gap float64) {
+	for i := 0; i+1 < len(cues); i++ {
+		next := cues[i+1].Start
+		if cues[i].End <= next-gap {
+			continue
+		}
+		if next-gap > cues[i].Start {
+			cues[i].End = next - gap
+		} else if next > cues[i].Start {
+			cues[i].End = next
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package pipeline
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"scribe2srt/internal/subtitle"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestResync_RetimesCuesFromTheirWords(t *testing.T) {
+	// Words at 10, 11, ...; the cues were timed two seconds early.
+	transcript := recognised("en", "good", "morning", "everyone", "lets", "begin")
+	for i := range transcript.Words {
+		transcript.Words[i].Start += 10
+		transcript.Words[i].End += 10
+	}
+	cues := []subtitle.Cue{
+		{Start: 8, End: 10.5, Text: "Good morning,\neveryone."},
+		{Start: 11, End: 12.5, Text: "Let's begin."},
+	}
+
+	got, report, err := Resync(cues, transcript, defaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Anchored != 2 || len(report.Unanchored) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if !near(got[0].Start, 10) || !near(got[0].End, 12.9) || !near(got[1].Start, 13) || !near(got[1].End, 14.9) {
+		t.Errorf("times = %+v", got)
+	}
+	if got[0].Text != cues[0].Text || got[1].Text != cues[1].Text {
+		t.Errorf("text changed: %+v", got)
+	}
+}
+
+func TestResync_ShiftsUnanchoredCuesByNeighbours(t *testing.T) {
+	transcript := recognised("en", "one", "two", "three", "four", "five", "six")
+	for i := range transcript.Words {
+		transcript.Words[i].Start += 1
+		transcript.Words[i].End += 1
+	}
+	cues := []subtitle.Cue{
+		{Start: 0, End: 1.9, Text: "one two"},
+		{Start: 2, End: 3.9, Text: "drei vier"},
+		{Start: 4, End: 5.9, Text: "five six"},
+	}
+
+	got, report, err := Resync(cues, transcript, defaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Unanchored, []int{1}) {
+		t.Errorf("unanchored = %v, want [1]", report.Unanchored)
+	}
+	// Both neighbours moved one second later, so the middle cue does too.
+	if !near(got[1].Start, 3) || !near(got[1].End, 4.9) {
+		t.Errorf("middle cue = %g..%g, want 3..4.9", got[1].Start, got[1].End)
+	}
+}
+
+func TestResync_ExtendsForUnrecognisedEdges(t *testing.T) {
+	// "right" at the end of the cue was not recognised. The anchored words
+	// take 3.6s for 10 characters, so its 5 characters add 1.8s.
+	transcript := recognised("en", "so", "then", "we", "go")
+	cues := []subtitle.Cue{{Start: 0, End: 4, Text: "So then\nwe go, right?"}}
+
+	got, _, err := Resync(cues, transcript, defaultSettings())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(got[0].Start, 0) || !near(got[0].End, 5.7) {
+		t.Errorf("cue = %g..%g, want 0..5.7", got[0].Start, got[0].End)
+	}
+}
+
+func TestResync_RemovesOverlaps(t *testing.T) {
+	transcript := recognised("en", "a", "bb", "cc", "dd")
+	transcript.Words[2].End = 2.5 // "bb" runs into "cc"
+	cues := []subtitle.Cue{
+		{Start: 0, End: 1, Text: "a bb"},
+		{Start: 1, End: 2, Text: "cc dd"},
+	}
+	settings := defaultSettings()
+
+	got, _, err := Resync(cues, transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0].End > got[1].Start-settings.MinSubtitleGap+1e-9 {
+		t.Errorf("cues overlap: %+v", got)
+	}
+}
+
+func TestResync_NothingAnchored(t *testing.T) {
+	transcript := recognised("en", "hello", "world")
+	cues := []subtitle.Cue{{Start: 0, End: 1, Text: "Bonjour le monde"}}
+	if _, _, err := Resync(cues, transcript, defaultSettings()); err == nil {
+		t.Error("want error when no cue can be anchored")
+	}
+}
@@ -0,0 +1,23 @@
+package pipeline
+
+import (
+	"fmt"
+
+	"scribe2srt/internal/bidi"
+	"scribe2srt/internal/subtitle"
+)
+
+// generateSCC writes entries as SCC pop-on captions. Line-21 decoders
+// have no directional marks, so none are written.
+func generateSCC(entries []SubtitleEntry, opts outputOptions) (string, error) {
+	opts.RTL = bidi.None
+	cues := make([]subtitle.Cue, len(entries))
+	for i, entry := range entries {
+		cues[i] = subtitle.Cue{Start: entry.Start, End: entry.End, Text: opts.cueText(entry, plainText, nil)}
+	}
+	scc, err := subtitle.FormatSCC(cues)
+	if err != nil {
+		return "", fmt.Errorf("write SCC: %w", err)
+	}
+	return scc, nil
+}
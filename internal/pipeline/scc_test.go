@@ -0,0 +1,67 @@
+package pipeline
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+)
+
+// sccRows returns the basic characters of each row of the first caption.
+func sccRows(scc string) []string {
+	caption := strings.SplitN(scc, "\n", 4)[2]
+	var rows []string
+	for _, word := range strings.Fields(caption)[1:] {
+		code, _ := strconv.ParseUint(word, 16, 16)
+		a, b := byte(code>>8)&0x7F, byte(code)&0x7F
+		switch {
+		case a >= 0x10 && a < 0x20 && b >= 0x40: // preamble address code
+			if len(rows) == 0 || rows[len(rows)-1] != "" {
+				rows = append(rows, "")
+			}
+		case a >= 0x20 && len(rows) > 0:
+			rows[len(rows)-1] += strings.TrimRight(string([]byte{a, b}), "\x00")
+		}
+	}
+	return rows
+}
+
+func TestFitRows_SCC(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatSCC
+	fitted := fitRows(settings)
+	if fitted.LatinCharsPerLine != 32 || fitted.CJKCharsPerLine > 32 {
+		t.Errorf("SCC CPL = %d/%d, want at most 32", fitted.LatinCharsPerLine, fitted.CJKCharsPerLine)
+	}
+}
+
+func TestProcess_SCC(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatSCC
+
+	// At the default 42 characters the first line would be 39 long.
+	transcript := stlTranscript("en", "The", "committee", "will", "publish", "its", "findings", "early", "next", "month.")
+	data, _, err := ProcessContext(context.Background(), transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(data, "Scenarist_SCC V1.0\n\n00:00:00;00\t9420 9420 94ae 94ae") {
+		t.Errorf("SCC starts %q", data[:min(len(data), 60)])
+	}
+	rows := sccRows(data)
+	if len(rows) != 2 {
+		t.Fatalf("rows = %q, want two", rows)
+	}
+	for _, row := range rows {
+		if len(row) > 32 {
+			t.Errorf("row %q is longer than 32 characters", row)
+		}
+	}
+
+	_, _, err = ProcessContext(context.Background(), stlTranscript("en", "na\u00efve"), settings) // naïve
+	if err == nil || !strings.Contains(err.Error(), "write SCC") || !strings.Contains(err.Error(), "U+00EF") {
+		t.Errorf("err = %v", err)
+	}
+}
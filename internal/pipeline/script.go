@@ -0,0 +1,122 @@
+package pipeline
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/textwidth"
+)
+
+// isCJKRune reports whether r belongs to a CJK script (Han, kana or Hangul).
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// isNoSpaceRune reports whether r belongs to a script written without spaces
+// between words, or is full-width CJK punctuation. Hangul is excluded because
+// Korean separates words with spaces.
+func isNoSpaceRune(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana):
+		return true
+	case isSoutheastAsianRune(r):
+		return true
+	case r >= 0x3000 && r <= 0x303F: // CJK symbols and punctuation
+		return true
+	case r >= 0xFF01 && r <= 0xFF60: // full-width forms
+		return true
+	}
+	return false
+}
+
+// isCJKText reports whether CJK is the dominant script of text. CJK
+// characters carry roughly twice the content of a Latin letter, so they are
+// weighted double. Text without any letters yields fallback.
+func isCJKText(text string, fallback bool) bool {
+	cjk, other := 0, 0
+	for _, r := range text {
+		switch {
+		case isCJKRune(r):
+			cjk += 2
+		case unicode.IsLetter(r):
+			other++
+		}
+	}
+	if cjk == 0 && other == 0 {
+		return fallback
+	}
+	return cjk >= other
+}
+
+// needsJoinSpace reports whether a space belongs between t1 and t2 when they
+// are joined into one cue. The decision is made from the characters at the
+// boundary, so English phrases inside a Chinese transcript keep their spaces
+// while CJK text on either side of the join is glued together.
+func needsJoinSpace(t1, t2 string) bool {
+	if t1 == "" || t2 == "" {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(t1)
+	first, _ := utf8.DecodeRuneInString(t2)
+	return !isNoSpaceRune(last) && !isNoSpaceRune(first)
+}
+
+// joinTexts joins two cue texts the way mergeTwoEntries does.
+func joinTexts(t1, t2 string) string {
+	if t1 != "" && endsWithJoinPunctuation(t1) {
+		return t1 + t2
+	}
+	if needsJoinSpace(t1, t2) {
+		return t1 + " " + t2
+	}
+	return t1 + t2
+}
+
+// scriptLimits resolves reading-speed and line-length limits per cue from the
+// dominant script of its text, instead of from the transcript language alone.
+type scriptLimits struct {
+	CJKCPS   float64
+	LatinCPS float64
+	CJKCPL   int
+	LatinCPL int
+
+	// DefaultCJK is used for text without letters, such as numbers or
+	// punctuation only.
+	DefaultCJK bool
+	WidthMode  textwidth.Mode
+}
+
+// newScriptLimits builds limits from settings. CJK limits are given in
+// full-width characters and are scaled to the width mode's units.
+func newScriptLimits(settings *config.SubtitleSettings, defaultCJK bool) scriptLimits {
+	scale := settings.WidthMode.FullWidthUnits()
+	return scriptLimits{
+		CJKCPS:     settings.CJKCPS * float64(scale),
+		LatinCPS:   settings.LatinCPS,
+		CJKCPL:     settings.CJKCharsPerLine * scale,
+		LatinCPL:   settings.LatinCharsPerLine,
+		DefaultCJK: defaultCJK,
+		WidthMode:  settings.WidthMode,
+	}
+}
+
+func (l scriptLimits) isCJK(text string) bool {
+	return isCJKText(text, l.DefaultCJK)
+}
+
+// cps returns the characters-per-second limit for text.
+func (l scriptLimits) cps(text string) float64 {
+	if l.isCJK(text) {
+		return l.CJKCPS
+	}
+	return l.LatinCPS
+}
+
+// cpl returns the characters-per-line limit for text.
+func (l scriptLimits) cpl(text string) int {
+	if l.isCJK(text) {
+		return l.CJKCPL
+	}
+	return l.LatinCPL
+}
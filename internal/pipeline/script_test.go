@@ -0,0 +1,84 @@
+package pipeline
+
+import (
+	"testing"
+
+	"scribe2srt/internal/config"
+)
+
+func TestIsCJKText(t *testing.T) {
+	tests := []struct {
+		text     string
+		fallback bool
+		want     bool
+	}{
+		{"hello world", true, false},
+		{"\u4f60\u597d", false, true},                       // 你好
+		{"iPhone 15 \u306e\u65b0\u6a5f\u80fd", false, true}, // iPhone 15 の新機能
+		{"\u6211\u4eec use the API", true, false},           // 我们 use the API
+		{"123 !?", true, true},
+		{"123 !?", false, false},
+	}
+
+	for _, tt := range tests {
+		got := isCJKText(tt.text, tt.fallback)
+		if got != tt.want {
+			t.Errorf("isCJKText(%q, %v) = %v, want %v", tt.text, tt.fallback, got, tt.want)
+		}
+	}
+}
+
+func TestJoinTexts(t *testing.T) {
+	tests := []struct {
+		t1, t2 string
+		want   string
+	}{
+		{"machine learning", "is great", "machine learning is great"},
+		{"\u6211\u559c\u6b22", "machine learning", "\u6211\u559c\u6b22machine learning"},                              // 我喜欢
+		{"deep learning", "\u5f88\u96be", "deep learning\u5f88\u96be"},                                                // 很难
+		{"\uc548\ub155\ud558\uc138\uc694", "\uc5ec\ub7ec\ubd84", "\uc548\ub155\ud558\uc138\uc694 \uc5ec\ub7ec\ubd84"}, // 안녕하세요 여러분
+		{"Hello,", "world", "Hello,world"},
+	}
+
+	for _, tt := range tests {
+		got := joinTexts(tt.t1, tt.t2)
+		if got != tt.want {
+			t.Errorf("joinTexts(%q, %q) = %q, want %q", tt.t1, tt.t2, got, tt.want)
+		}
+	}
+}
+
+func TestMergeTwoEntries_CodeSwitched(t *testing.T) {
+	settings := &config.SubtitleSettings{
+		MinSubtitleDuration: 0.83,
+		MaxSubtitleDuration: 12.0,
+		MinSubtitleGap:      0.083,
+		CJKCPS:              11,
+		LatinCPS:            15,
+		CJKCharsPerLine:     25,
+		LatinCharsPerLine:   42,
+	}
+	m := NewIntelligentMerger("zh", settings)
+
+	e1 := SubtitleEntry{Text: "open source", Start: 0, End: 1, WordCount: 2}
+	e2 := SubtitleEntry{Text: "software", Start: 1.1, End: 2, WordCount: 1}
+
+	merged := m.mergeTwoEntries(e1, e2)
+	if merged.Text != "open source software" {
+		t.Errorf("merged.Text = %q, want English words to keep their space", merged.Text)
+	}
+}
+
+func TestScriptLimits(t *testing.T) {
+	limits := newScriptLimits(defaultSettings(), true)
+
+	if got := limits.cpl("This is an English sentence"); got != 42 {
+		t.Errorf("cpl(latin) = %d, want 42", got)
+	}
+	if got := limits.cpl("\u3053\u3093\u306b\u3061\u306f"); got != 25 { // こんにちは
+		t.Errorf("cpl(cjk) = %d, want 25", got)
+	}
+	if got := limits.cps("42"); got != 11 {
+		t.Errorf("cps(no letters) = %f, want the CJK default 11", got)
+	}
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"math"
+	"sort"
+)
+
+// timingRules returns the minimum gap and minimum duration in seconds. With a
+// frame rate set they come from the frame-based rules, so boundaries moved
+// before frame snapping already respect them.
+func (m *IntelligentMerger) timingRules() (gap, minDuration float64) {
+	if m.FrameRate.IsZero() {
+		return m.MinSubtitleGap, m.MinSubtitleDuration
+	}
+	gapFrames, minFrames, _ := m.frameRules()
+	return m.FrameRate.ToSeconds(gapFrames), m.FrameRate.ToSeconds(minFrames)
+}
+
+// nearbyShots returns the shot changes within m.ShotSnapWindow of t, nearest
+// first.
+func (m *IntelligentMerger) nearbyShots(t float64) []float64 {
+	lo := sort.SearchFloat64s(m.ShotChanges, t-m.ShotSnapWindow)
+	hi := sort.SearchFloat64s(m.ShotChanges, t+m.ShotSnapWindow+1e-9)
+	if lo >= hi {
+		return nil
+	}
+	shots := make([]float64, hi-lo)
+	copy(shots, m.ShotChanges[lo:hi])
+	sort.SliceStable(shots, func(i, j int) bool {
+		return math.Abs(shots[i]-t) < math.Abs(shots[j]-t)
+	})
+	return shots
+}
+
+// snapToShots moves cue boundaries onto shot changes within ShotSnapWindow.
+// A cue starts exactly on a cut and ends the minimum gap before one, so a
+// cue that starts on the same cut keeps the gap. Shots are tried nearest
+// first and a move is only made when the cue still meets the minimum gap to
+// its neighbours and the minimum and maximum durations; this also pulls a
+// cue that straddles a cut by a few frames onto one side of it.
+func (m *IntelligentMerger) snapToShots(entries []SubtitleEntry) []SubtitleEntry {
+	gap, minDuration := m.timingRules()
+
+	snapped := make([]SubtitleEntry, len(entries))
+	copy(snapped, entries)
+
+	for i := range snapped {
+		e := &snapped[i]
+
+		prevEnd := math.Inf(-1)
+		if i > 0 {
+			prevEnd = snapped[i-1].End
+		}
+		nextStart := math.Inf(1)
+		if i+1 < len(snapped) {
+			nextStart = snapped[i+1].Start
+		}
+
+		for _, cut := range m.nearbyShots(e.Start) {
+			if m.validTiming(cut, e.End, minDuration) && cut >= prevEnd+gap-1e-9 {
+				e.Start = cut
+				break
+			}
+		}
+
+		for _, cut := range m.nearbyShots(e.End) {
+			end := cut - gap
+			if m.validTiming(e.Start, end, minDuration) && end <= nextStart-gap+1e-9 {
+				e.End = end
+				break
+			}
+		}
+	}
+
+	return snapped
+}
+
+// validTiming reports whether a cue from start to end meets the duration
+// limits.
+func (m *IntelligentMerger) validTiming(start, end, minDuration float64) bool {
+	d := end - start
+	return start >= 0 && d >= minDuration-1e-9 && d <= m.MaxSubtitleDuration
+}
@@ -0,0 +1,79 @@
+package pipeline
+
+import (
+	"math"
+	"testing"
+)
+
+func shotMerger(shots ...float64) *IntelligentMerger {
+	m := defaultMerger()
+	m.ShotChanges = shots
+	m.ShotSnapWindow = 0.5
+	return m
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestSnapToShots_MovesBoundaries(t *testing.T) {
+	m := shotMerger(0.8, 4.3)
+	entries := []SubtitleEntry{
+		{Text: "One", Start: 1.0, End: 4.0},
+	}
+
+	got := m.snapToShots(entries)
+	if !approxEqual(got[0].Start, 0.8) {
+		t.Errorf("Start = %v, want 0.8 (on the cut)", got[0].Start)
+	}
+	if want := 4.3 - m.MinSubtitleGap; !approxEqual(got[0].End, want) {
+		t.Errorf("End = %v, want %v (min gap before the cut)", got[0].End, want)
+	}
+}
+
+func TestSnapToShots_NoStraddle(t *testing.T) {
+	// The cue ends three frames after a cut; it should end before it instead.
+	m := shotMerger(3.0)
+	entries := []SubtitleEntry{
+		{Text: "One", Start: 1.0, End: 3.125},
+		{Text: "Two", Start: 3.2, End: 5.0},
+	}
+
+	got := m.snapToShots(entries)
+	if got[0].End >= 3.0 {
+		t.Errorf("first cue still straddles the cut: End = %v", got[0].End)
+	}
+	if !approxEqual(got[1].Start, 3.0) {
+		t.Errorf("second cue Start = %v, want 3.0", got[1].Start)
+	}
+	if gap := got[1].Start - got[0].End; gap < m.MinSubtitleGap-1e-9 {
+		t.Errorf("gap = %v, want at least %v", gap, m.MinSubtitleGap)
+	}
+}
+
+func TestSnapToShots_KeepsMinimumDuration(t *testing.T) {
+	// Snapping the start to the cut would leave the cue shorter than the
+	// minimum duration.
+	m := shotMerger(1.4)
+	entries := []SubtitleEntry{
+		{Text: "Hi", Start: 1.0, End: 1.9},
+	}
+
+	got := m.snapToShots(entries)
+	if got[0].Start != 1.0 {
+		t.Errorf("Start = %v, want unchanged 1.0", got[0].Start)
+	}
+}
+
+func TestSnapToShots_KeepsGapToPrevious(t *testing.T) {
+	m := shotMerger(2.0)
+	entries := []SubtitleEntry{
+		{Text: "One", Start: 0.5, End: 1.95},
+		{Text: "Two", Start: 2.1, End: 4.0},
+	}
+
+	got := m.snapToShots(entries)
+	if gap := got[1].Start - got[0].End; gap < m.MinSubtitleGap-1e-9 {
+		t.Errorf("gap = %v, want at least %v", gap, m.MinSubtitleGap)
+	}
+}
@@ -5,29 +5,37 @@ import (
 	"unicode/utf8"
 
 	"scribe2srt/internal/config"
+	"scribe2srt/internal/lang"
 )
 
 // SentenceSplitter implements Stage 1 of the subtitle pipeline.
 type SentenceSplitter struct {
 	Language string
 	IsCJK   bool
+	Profile segmentProfile
+	// SentenceFinal is the language's sentence-final punctuation from the
+	// registry; it always ends a sentence.
+	SentenceFinal string
 }
 
 // NewSentenceSplitter creates a new splitter for the given language code.
 func NewSentenceSplitter(langCode string) *SentenceSplitter {
-	lang := langCode
-	if len(lang) > 3 {
-		lang = lang[:3]
-	}
+	code := lang.Normalize(langCode)
+	l, _ := lang.Lookup(code)
 	return &SentenceSplitter{
-		Language: lang,
-		IsCJK:   config.IsCJK(lang),
+		Language:      code,
+		IsCJK:         config.IsCJK(code),
+		Profile:       profileForLanguage(code),
+		SentenceFinal: l.SentenceFinal,
 	}
 }
 
 // shouldSplitAtWord decides whether to split after the current word.
 func (s *SentenceSplitter) shouldSplitAtWord(word Word, accumulated []Word) bool {
 	text := strings.TrimSpace(word.Text)
+	if r, _ := utf8.DecodeLastRuneInString(text); text != "" && strings.ContainsRune(s.SentenceFinal, r) {
+		return true
+	}
 	hasPunct, _, priority := wordEndsWithPunctuation(text)
 	if !hasPunct {
 		return false
@@ -78,6 +86,12 @@ func (s *SentenceSplitter) SplitIntoSentenceGroups(words []Word) [][]Word {
 		shouldSplit := s.shouldSplitAtWord(word, accumulated)
 		isLast := i == len(words)-1
 
+		// Scripts without spaces between words also split at phrase
+		// boundaries: spaces and pauses in the word timings.
+		if !shouldSplit && !isLast {
+			shouldSplit = s.Profile.shouldSplitAtPhrase(word, &words[i+1], current)
+		}
+
 		if shouldSplit || isLast {
 			if len(current) > 0 {
 				groups = append(groups, current)
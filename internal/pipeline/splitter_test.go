@@ -157,3 +157,22 @@ func TestSentenceSplitter_CreateBasicEntries_SkipsEmptyGroups(t *testing.T) {
 		t.Fatalf("expected 1 entry (empty group skipped), got %d", len(entries))
 	}
 }
+
+func TestSentenceSplitter_RegistrySentenceFinal(t *testing.T) {
+	tests := []struct {
+		lang  string
+		words []string
+	}{
+		{"hi", []string{"\u0928\u092e\u0938\u094d\u0924\u0947\u0964", "\u0906\u092a"}}, // नमस्ते। आप
+		{"el", []string{"\u03a4\u03b9;", "\u039a\u03b1\u03bb\u03ac."}},                 // Τι; Καλά.
+	}
+	for _, tt := range tests {
+		var words []Word
+		for i, text := range tt.words {
+			words = append(words, Word{Text: text, Start: float64(i), End: float64(i) + 0.5, Type: "word"})
+		}
+		if groups := NewSentenceSplitter(tt.lang).SplitIntoSentenceGroups(words); len(groups) != 2 {
+			t.Errorf("%s: got %d groups, want a split after %q", tt.lang, len(groups), tt.words[0])
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"fmt"
+	"time"
+
+	"scribe2srt/internal/bidi"
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/subtitle"
+)
+
+// generateSTL writes entries as an EBU STL file. The character table is
+// picked from the script of the language written unless one is set.
+// Directional marks are left out: STL players order right-to-left text
+// themselves, and the 8-bit tables have no room for them.
+func generateSTL(entries []SubtitleEntry, opts outputOptions) (string, error) {
+	opts.RTL = bidi.None
+	cues := make([]subtitle.Cue, len(entries))
+	for i, entry := range entries {
+		cues[i] = subtitle.Cue{Start: entry.Start, End: entry.End, Text: opts.cueText(entry, plainText, nil)}
+	}
+
+	charset := stlCharset(opts.Lang)
+	if opts.STL.Charset != nil {
+		charset = *opts.STL.Charset
+	}
+	data, err := subtitle.FormatSTL(subtitle.STLOptions{
+		CodePage:     opts.STL.CodePage,
+		Charset:      charset,
+		Display:      opts.STL.Display,
+		FrameRate:    opts.FrameRate,
+		RowChars:     opts.STL.Row(),
+		Language:     opts.Lang,
+		Title:        opts.Title,
+		EpisodeTitle: opts.STL.EpisodeTitle,
+		Publisher:    opts.STL.Publisher,
+		Country:      opts.STL.Country,
+		Created:      time.Now(),
+	}, cues)
+	if err != nil {
+		return "", fmt.Errorf("write EBU STL: %w", err)
+	}
+	return string(data), nil
+}
+
+// stlCharset returns the character table for the script of code.
+func stlCharset(code string) subtitle.STLCharset {
+	l, _ := lang.Lookup(code)
+	switch l.Script {
+	case lang.Cyrillic:
+		return subtitle.STLCyrillic
+	case lang.Greek:
+		return subtitle.STLGreek
+	case lang.Arabic:
+		return subtitle.STLArabic
+	case lang.Hebrew:
+		return subtitle.STLHebrew
+	}
+	return subtitle.STLLatin
+}
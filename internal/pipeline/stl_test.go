@@ -0,0 +1,102 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+)
+
+// stlTranscript builds a transcript of words 0.4 seconds apart.
+func stlTranscript(langCode string, texts ...string) *TranscriptResponse {
+	t := &TranscriptResponse{LanguageCode: langCode}
+	for i, text := range texts {
+		if i > 0 {
+			t.Words = append(t.Words, Word{Text: " ", Start: float64(i) * 0.4, End: float64(i) * 0.4, Type: "spacing"})
+		}
+		t.Words = append(t.Words, Word{Text: text, Start: float64(i) * 0.4, End: float64(i)*0.4 + 0.35, Type: "word"})
+	}
+	return t
+}
+
+// stlRows returns the printable text of each row of the first TTI block.
+func stlRows(data []byte) []string {
+	field := bytes.TrimRight(data[1024+16:1024+128], "\x8f")
+	var rows []string
+	for _, row := range bytes.Split(field, []byte{0x8A}) {
+		row = bytes.TrimLeft(row, "\x0b\x0d")
+		if len(row) > 0 {
+			rows = append(rows, string(row))
+		}
+	}
+	return rows
+}
+
+func TestFitRows(t *testing.T) {
+	settings := defaultSettings()
+	if fitRows(settings) != settings {
+		t.Error("SRT settings were changed")
+	}
+
+	settings.Format = config.FormatSTL
+	fitted := fitRows(settings)
+	if fitted.LatinCharsPerLine != 37 || fitted.CJKCharsPerLine != 25 {
+		t.Errorf("teletext CPL = %d/%d, want 37/25", fitted.LatinCharsPerLine, fitted.CJKCharsPerLine)
+	}
+	if settings.LatinCharsPerLine != 42 {
+		t.Error("fitRows changed its argument")
+	}
+
+	settings.STL.RowChars = 40
+	if got := fitRows(settings).LatinCharsPerLine; got != 40 {
+		t.Errorf("CPL with 40-character rows = %d", got)
+	}
+}
+
+func TestProcess_STL(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatSTL
+	settings.Title = "News"
+
+	// At the default 42 characters the first line would be 39 long.
+	transcript := stlTranscript("en", "The", "committee", "will", "publish", "its", "findings", "early", "next", "month.")
+	data, _, err := ProcessContext(context.Background(), transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(data, "850STL25.011") || data[14:16] != "09" || !strings.HasPrefix(data[16:], "News ") {
+		t.Errorf("GSI starts %q", data[:24])
+	}
+	rows := stlRows([]byte(data))
+	if len(rows) != 2 {
+		t.Fatalf("rows = %q, want two", rows)
+	}
+	for _, row := range rows {
+		if len(row) > 37 {
+			t.Errorf("row %q is longer than 37 characters", row)
+		}
+	}
+}
+
+func TestProcess_STLCharsetFromLanguage(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatSTL
+
+	data, _, err := ProcessContext(context.Background(), stlTranscript("ru", "\u0414\u0430."), settings) // Да.
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[12:14] != "01" || data[14:16] != "56" {
+		t.Errorf("CCT = %q, LC = %q; want Cyrillic and Russian", data[12:14], data[14:16])
+	}
+
+	latin := subtitle.STLLatin
+	settings.STL.Charset = &latin
+	_, _, err = ProcessContext(context.Background(), stlTranscript("ru", "\u0414\u0430."), settings)
+	if err == nil || !strings.Contains(err.Error(), "latin character table") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/translate"
+)
+
+// translateEntries fills in the Translation of every speech cue of entries.
+// Cues are sent in batches, each with tr.Context cues of context on either
+// side, so the service sees the conversation rather than isolated lines.
+// Cue timing is left unchanged.
+func translateEntries(ctx context.Context, entries []SubtitleEntry, tr *config.Translation, source string) error {
+	var speech []int
+	for i, e := range entries {
+		if !e.IsAudioEvent && strings.TrimSpace(e.Text) != "" {
+			speech = append(speech, i)
+		}
+	}
+
+	texts := func(indices []int) []string {
+		out := make([]string, len(indices))
+		for k, i := range indices {
+			out[k] = strings.TrimSpace(entries[i].Text)
+		}
+		return out
+	}
+
+	batch := max(tr.BatchSize, 1)
+	for start := 0; start < len(speech); start += batch {
+		end := min(start+batch, len(speech))
+		req := translate.Request{
+			Source: source,
+			Target: tr.Target,
+			Before: texts(speech[max(start-tr.Context, 0):start]),
+			Lines:  texts(speech[start:end]),
+			After:  texts(speech[end:min(end+tr.Context, len(speech))]),
+		}
+		lines, err := tr.Translator.Translate(ctx, req)
+		if err != nil {
+			return fmt.Errorf("translate cues %d-%d: %w", start+1, end, err)
+		}
+		for k, i := range speech[start:end] {
+			entries[i].Translation = strings.TrimSpace(lines[k])
+		}
+	}
+	return nil
+}
+
+// translateSentences translates the sentence entries from the splitter and
+// re-segments each translation into basic entries in the target language.
+// The translation is spread over the sentence's time span by character
+// share, split where the target language's splitter would split it, and
+// further divided until every piece fits on two lines, so the merger can
+// then apply the target language's CPS and CPL rules as usual. A sentence
+// the service returns no translation for keeps its source text, so no
+// speech goes missing from the output; those sentences are returned as
+// untranslated.
+func translateSentences(ctx context.Context, sentences []SubtitleEntry, tr *config.Translation, source string, settings *config.SubtitleSettings) (entries, untranslated []SubtitleEntry, err error) {
+	if err := translateEntries(ctx, sentences, tr, source); err != nil {
+		return nil, nil, err
+	}
+
+	splitter := NewSentenceSplitter(tr.Target)
+	fit := NewIntelligentMerger(tr.Target, settings)
+
+	for _, s := range sentences {
+		text := s.Translation
+		if text == "" {
+			text = strings.TrimSpace(s.Text)
+			untranslated = append(untranslated, s)
+		}
+		if text == "" {
+			continue
+		}
+		words := distributeText(text, s.Start, s.End)
+		var groups [][]Word
+		for _, g := range splitter.SplitIntoSentenceGroups(words) {
+			groups = append(groups, fit.splitToFit(g)...)
+		}
+		entries = append(entries, splitter.CreateBasicEntries(groups)...)
+	}
+	return entries, untranslated, nil
+}
+
+// tokenizeTranslation splits text into word tokens. Characters of scripts
+// written without spaces become tokens of their own; other runs stay
+// together, and each space-separated field keeps a trailing space.
+func tokenizeTranslation(text string) []string {
+	fields := strings.Fields(text)
+	var tokens []string
+	for i, field := range fields {
+		var run []rune
+		for _, r := range field {
+			if !isNoSpaceRune(r) {
+				run = append(run, r)
+				continue
+			}
+			if len(run) > 0 {
+				tokens = append(tokens, string(run))
+				run = nil
+			}
+			tokens = append(tokens, string(r))
+		}
+		if len(run) > 0 {
+			tokens = append(tokens, string(run))
+		}
+		if i < len(fields)-1 {
+			tokens[len(tokens)-1] += " "
+		}
+	}
+	return tokens
+}
+
+// distributeText turns text into words spanning start to end, each timed in
+// proportion to its share of the characters.
+func distributeText(text string, start, end float64) []Word {
+	tokens := tokenizeTranslation(text)
+	weights := make([]int, len(tokens))
+	total := 0
+	for i, tok := range tokens {
+		weights[i] = max(utf8.RuneCountInString(strings.TrimSpace(tok)), 1)
+		total += weights[i]
+	}
+
+	words := make([]Word, len(tokens))
+	done := 0
+	for i, tok := range tokens {
+		words[i] = Word{
+			Text:  tok,
+			Start: start + (end-start)*float64(done)/float64(total),
+			Type:  "word",
+		}
+		done += weights[i]
+		words[i].End = start + (end-start)*float64(done)/float64(total)
+	}
+	return words
+}
+
+// splitToFit divides a word group until each part's text fits on two lines.
+// Each cut is made at the word boundary closest to the middle, preferring
+// one after punctuation in the middle half of the group.
+func (m *IntelligentMerger) splitToFit(group []Word) [][]Word {
+	text := strings.TrimSpace(joinWords(group))
+	if len(group) < 2 || m.calculateDisplayLines(text, group) <= 2 {
+		return [][]Word{group}
+	}
+
+	total := 0
+	for _, w := range group {
+		total += utf8.RuneCountInString(strings.TrimSpace(w.Text))
+	}
+
+	best, bestScore := 1, math.Inf(1)
+	chars := 0
+	for k := 1; k < len(group); k++ {
+		chars += utf8.RuneCountInString(strings.TrimSpace(group[k-1].Text))
+		score := math.Abs(float64(chars) - float64(total)/2)
+		if ok, _, _ := wordEndsWithPunctuation(strings.TrimSpace(group[k-1].Text)); ok && score <= float64(total)/4 {
+			score -= float64(total) / 4
+		}
+		if score < bestScore {
+			best, bestScore = k, score
+		}
+	}
+	return append(m.splitToFit(group[:best]), m.splitToFit(group[best:])...)
+}
+
+func joinWords(words []Word) string {
+	var sb strings.Builder
+	for _, w := range words {
+		sb.WriteString(w.Text)
+	}
+	return sb.String()
+}
+
+// attachSourceText turns entries holding translated text into bilingual
+// entries: the translation moves to Translation, and Text and Words become
+// the source words spoken during the entry. A source word belongs to the
+// entry whose translated words cover the middle of it, or to the last entry
+// starting before it. Words of untranslated sentences already hold the
+// source text, so they are left out of the translation.
+func attachSourceText(entries []SubtitleEntry, source []Word, untranslated []SubtitleEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	e := 0
+	parts := make([][]Word, len(entries))
+	for _, w := range source {
+		mid := (w.Start + w.End) / 2
+		for e+1 < len(entries) && len(entries[e+1].Words) > 0 && entries[e+1].Words[0].Start <= mid {
+			e++
+		}
+		parts[e] = append(parts[e], w)
+	}
+	for i := range entries {
+		translation := entries[i].Text
+		if kept := translatedWords(entries[i].Words, untranslated); len(kept) < len(entries[i].Words) {
+			translation = joinWords(kept)
+		}
+		entries[i].Translation = strings.TrimSpace(translation)
+		entries[i].Text = strings.TrimSpace(joinWords(parts[i]))
+		entries[i].Words = parts[i]
+	}
+}
+
+// translatedWords returns the words not timed within one of the untranslated
+// sentences.
+func translatedWords(words []Word, untranslated []SubtitleEntry) []Word {
+	if len(untranslated) == 0 {
+		return words
+	}
+	var out []Word
+	for _, w := range words {
+		mid := (w.Start + w.End) / 2
+		kept := true
+		for _, s := range untranslated {
+			if mid >= s.Start && mid <= s.End {
+				kept = false
+				break
+			}
+		}
+		if kept {
+			out = append(out, w)
+		}
+	}
+	return out
+}
@@ -0,0 +1,322 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/translate"
+)
+
+// fakeTranslator looks each line up in a table and records the requests.
+type fakeTranslator struct {
+	table    map[string]string
+	requests []translate.Request
+	err      error
+}
+
+func (f *fakeTranslator) Translate(_ context.Context, req translate.Request) ([]string, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := make([]string, len(req.Lines))
+	for i, line := range req.Lines {
+		out[i] = f.table[line]
+	}
+	return out, nil
+}
+
+func TestTranslateEntries_BatchesWithContext(t *testing.T) {
+	entries := []SubtitleEntry{
+		{Text: "one"},
+		{Text: "(music)", IsAudioEvent: true},
+		{Text: "two"},
+		{Text: "three"},
+		{Text: "four"},
+	}
+	f := &fakeTranslator{table: map[string]string{"one": "1", "two": "2", "three": "3", "four": "4"}}
+	tr := &config.Translation{Translator: f, Target: "en", Context: 1, BatchSize: 2}
+
+	if err := translateEntries(context.Background(), entries, tr, "ja"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(f.requests))
+	}
+	second := f.requests[1]
+	if strings.Join(second.Before, ",") != "two" || strings.Join(second.Lines, ",") != "three,four" || len(second.After) != 0 {
+		t.Errorf("second request = %+v", second)
+	}
+	if second.Source != "ja" || second.Target != "en" {
+		t.Errorf("languages = %q -> %q", second.Source, second.Target)
+	}
+	if entries[1].Translation != "" {
+		t.Error("audio event was translated")
+	}
+	if entries[0].Translation != "1" || entries[4].Translation != "4" {
+		t.Errorf("translations = %+v", entries)
+	}
+}
+
+// translationSource is the text of translationTranscript.
+const translationSource = "\u4eca\u65e5\u306f\u3044\u3044\u5929\u6c17\u3067\u3059\u306d\u3002" // 今日はいい天気ですね。
+
+// translationTranscript is a Japanese sentence that fits one cue.
+func translationTranscript() *TranscriptResponse {
+	return &TranscriptResponse{
+		LanguageCode: "ja",
+		Words: []Word{
+			{Text: "\u4eca\u65e5\u306f", Start: 0, End: 0.8, Type: "word"},
+			{Text: "\u3044\u3044", Start: 0.8, End: 1.2, Type: "word"},
+			{Text: "\u5929\u6c17\u3067\u3059\u306d\u3002", Start: 1.2, End: 2.5, Type: "word"},
+		},
+	}
+}
+
+func TestProcess_TranslationRewrapsAtTargetCPL(t *testing.T) {
+	f := &fakeTranslator{table: map[string]string{
+		translationSource: "The weather is really nice today, isn't it?",
+	}}
+	settings := defaultSettings()
+	settings.LatinCharsPerLine = 30
+	settings.Translation = &config.Translation{Translator: f, Target: "en", BatchSize: 20}
+
+	result, _, err := ProcessContext(context.Background(), translationTranscript(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, "00:00:00,000 --> 00:00:02,500\nThe weather is really nice\ntoday, isn't it?\n") {
+		t.Errorf("expected the translation wrapped at 30 characters with the source timing, got:\n%s", result)
+	}
+	if strings.Contains(result, "\u5929\u6c17") { // 天気
+		t.Errorf("source text should be replaced, got:\n%s", result)
+	}
+}
+
+func TestProcess_Bilingual(t *testing.T) {
+	f := &fakeTranslator{table: map[string]string{
+		translationSource: "Nice weather today.",
+	}}
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: f, Target: "en", Bilingual: true, BatchSize: 20}
+
+	result, _, err := ProcessContext(context.Background(), translationTranscript(), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(result, translationSource+"\nNice weather today.\n") {
+		t.Errorf("expected the source above the translation, got:\n%s", result)
+	}
+}
+
+func TestProcess_TranslationError(t *testing.T) {
+	f := &fakeTranslator{err: errors.New("service down")}
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: f, Target: "en", BatchSize: 20}
+
+	if _, _, err := ProcessContext(context.Background(), translationTranscript(), settings); err == nil {
+		t.Error("expected the translator error to be returned")
+	}
+}
+
+func TestTokenizeTranslation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello, world.", []string{"Hello, ", "world."}},
+		// 你好，iPhone 15。
+		{"\u4f60\u597d\uff0ciPhone 15\u3002", []string{"\u4f60", "\u597d", "\uff0c", "iPhone ", "15", "\u3002"}},
+	}
+	for _, tt := range tests {
+		got := tokenizeTranslation(tt.in)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("tokenizeTranslation(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDistributeText_CharacterShare(t *testing.T) {
+	words := distributeText("ab abcdef", 1, 5)
+	if len(words) != 2 {
+		t.Fatalf("got %d words", len(words))
+	}
+	// 2 of 8 characters -> the first quarter of the span.
+	if words[0].Start != 1 || words[0].End != 2 || words[1].Start != 2 || words[1].End != 5 {
+		t.Errorf("words = %+v", words)
+	}
+}
+
+func TestSplitToFit(t *testing.T) {
+	settings := defaultSettings()
+	settings.LatinCharsPerLine = 10
+	m := NewIntelligentMerger("en", settings)
+
+	words := distributeText("one two three, four five six seven", 0, 4)
+	parts := m.splitToFit(words)
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(parts))
+	}
+	// The comma is close enough to the middle to be preferred.
+	if got := strings.TrimSpace(joinWords(parts[0])); got != "one two three," {
+		t.Errorf("first part = %q", got)
+	}
+}
+
+// sentenceTranscript is one Japanese sentence spoken over eight seconds.
+func sentenceTranscript() *TranscriptResponse {
+	// 昨日、駅の前で十年以上会っていなかった学校の古い友達に偶然会いました。
+	return &TranscriptResponse{
+		LanguageCode: "ja",
+		Words: []Word{
+			{Text: "\u6628\u65e5\u3001", Start: 0, End: 0.8, Type: "word"},
+			{Text: "\u99c5\u306e\u524d\u3067", Start: 0.8, End: 2.0, Type: "word"},
+			{Text: "\u5341\u5e74\u4ee5\u4e0a\u4f1a\u3063\u3066\u3044\u306a\u304b\u3063\u305f", Start: 2.0, End: 4.5, Type: "word"},
+			{Text: "\u5b66\u6821\u306e\u53e4\u3044\u53cb\u9054\u306b", Start: 4.5, End: 6.5, Type: "word"},
+			{Text: "\u5076\u7136\u4f1a\u3044\u307e\u3057\u305f\u3002", Start: 6.5, End: 8.0, Type: "word"},
+		},
+	}
+}
+
+const sentenceTranslation = "Yesterday, in front of the station, I happened to run into an old friend from school whom I had not seen for more than ten years."
+
+// srtCues splits SRT content into the time line and text of each cue.
+func srtCues(srt string) (times, texts []string) {
+	for _, block := range strings.Split(strings.TrimSpace(srt), "\n\n") {
+		lines := strings.SplitN(block, "\n", 3)
+		if len(lines) == 3 {
+			times = append(times, lines[1])
+			texts = append(texts, lines[2])
+		}
+	}
+	return times, texts
+}
+
+func TestProcess_SentenceTranslation(t *testing.T) {
+	transcript := sentenceTranscript()
+	f := &fakeTranslator{table: map[string]string{
+		joinWords(transcript.Words): sentenceTranslation,
+	}}
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: f, Target: "en", Unit: config.TranslateSentences, BatchSize: 20}
+
+	result, _, err := ProcessContext(context.Background(), transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.requests) != 1 || len(f.requests[0].Lines) != 1 {
+		t.Fatalf("expected the whole sentence in one request, got %+v", f.requests)
+	}
+
+	times, texts := srtCues(result)
+	if len(texts) < 2 {
+		t.Fatalf("expected the translation to be re-segmented into several cues, got:\n%s", result)
+	}
+	if !strings.HasPrefix(times[0], "00:00:00,000 -->") {
+		t.Errorf("first cue should start with the sentence, got %s", times[0])
+	}
+	var joined []string
+	for _, text := range texts {
+		lines := strings.Split(text, "\n")
+		if len(lines) > 2 {
+			t.Errorf("cue has %d lines: %q", len(lines), text)
+		}
+		for _, line := range lines {
+			if len(line) > settings.LatinCharsPerLine {
+				t.Errorf("line longer than %d characters: %q", settings.LatinCharsPerLine, line)
+			}
+		}
+		joined = append(joined, strings.ReplaceAll(text, "\n", " "))
+	}
+	if got := strings.Join(joined, " "); got != sentenceTranslation {
+		t.Errorf("cue texts = %q, want the full translation", got)
+	}
+}
+
+func TestProcess_SentenceTranslationBilingual(t *testing.T) {
+	transcript := sentenceTranscript()
+	f := &fakeTranslator{table: map[string]string{
+		joinWords(transcript.Words): sentenceTranslation,
+	}}
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: f, Target: "en", Unit: config.TranslateSentences, Bilingual: true, BatchSize: 20}
+
+	result, _, err := ProcessContext(context.Background(), transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each cue starts with the Japanese spoken during it, and together
+	// they give back the whole source sentence.
+	_, texts := srtCues(result)
+	var source strings.Builder
+	for _, text := range texts {
+		first, _, _ := strings.Cut(text, "\n")
+		if !isCJKText(first, false) {
+			t.Errorf("cue does not start with the source text: %q", text)
+		}
+		source.WriteString(first)
+	}
+	if got := source.String(); got != joinWords(transcript.Words) {
+		t.Errorf("source lines = %q, want %q", got, joinWords(transcript.Words))
+	}
+}
+
+func TestProcess_SentenceTranslationEmpty(t *testing.T) {
+	transcript := &TranscriptResponse{
+		LanguageCode: "ja",
+		Words:        []Word{{Text: "\u306f\u3044\u3002", Start: 0, End: 1, Type: "word"}}, // はい。
+	}
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: &fakeTranslator{}, Target: "en", Unit: config.TranslateSentences, Bilingual: true, BatchSize: 20}
+
+	// The translator returns "" for the sentence: its source text is kept,
+	// and shown once rather than as its own translation.
+	result, _, err := ProcessContext(context.Background(), transcript, settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, texts := srtCues(result); len(texts) != 1 || texts[0] != "\u306f\u3044\u3002" {
+		t.Errorf("cues = %q, want only the untranslated sentence", texts)
+	}
+}
+
+func TestAttachSourceText_NoEntries(t *testing.T) {
+	var entries []SubtitleEntry
+	attachSourceText(entries, []Word{{Text: "a", Start: 0, End: 1, Type: "word"}}, nil)
+	if len(entries) != 0 {
+		t.Errorf("entries = %+v, want none", entries)
+	}
+}
+
+func TestAttachSourceText_Untranslated(t *testing.T) {
+	source := []Word{
+		{Text: "yes ", Start: 0, End: 1, Type: "word"},
+		{Text: "no", Start: 1, End: 2, Type: "word"},
+	}
+	entries := []SubtitleEntry{{
+		Text: "oui yes",
+		Words: []Word{
+			{Text: "oui ", Start: 0, End: 1, Type: "word"},
+			{Text: "yes", Start: 1, End: 2, Type: "word"},
+		},
+	}}
+	untranslated := []SubtitleEntry{{Text: "no", Start: 1, End: 2}}
+
+	attachSourceText(entries, source, untranslated)
+	if entries[0].Text != "yes no" || entries[0].Translation != "oui" {
+		t.Errorf("entry = %q / %q, want %q / %q", entries[0].Text, entries[0].Translation, "yes no", "oui")
+	}
+}
+
+func TestProcessWithReport_TranslationError(t *testing.T) {
+	settings := defaultSettings()
+	settings.Translation = &config.Translation{Translator: &fakeTranslator{err: errors.New("quota exceeded")}, Target: "en", BatchSize: 20}
+
+	if _, _, err := ProcessWithReport(sentenceTranscript(), settings); err == nil {
+		t.Error("expected the translation error")
+	}
+}
@@ -0,0 +1,152 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+
+	"scribe2srt/internal/bidi"
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+)
+
+// TTML style IDs.
+const (
+	ttmlDefaultStyle = "s_default"
+	ttmlEventStyle   = "s_event"
+)
+
+// speakerColors are given to speakers in order of appearance, following the
+// broadcast convention of white, yellow, cyan and green.
+var speakerColors = []string{"white", "yellow", "cyan", "lime"}
+
+// generateTTML writes entries as an IMSC1 Text profile TTML document, or as
+// DFXP. Audio events are in italics. When the transcript has more than one
+// speaker, each speaker's text gets a colour of its own; a cue with several
+// speakers is divided into spans at the words where the speaker changes.
+func generateTTML(entries []SubtitleEntry, opts outputOptions) string {
+	speakers := speakerStyles(entries)
+	styles := []subtitle.TTMLStyle{
+		{ID: ttmlDefaultStyle, Color: "white"},
+		{ID: ttmlEventStyle, Italic: true},
+	}
+	for i, id := range speakers.order {
+		styles = append(styles, subtitle.TTMLStyle{ID: speakers.styles[id], Color: speakerColors[i%len(speakerColors)]})
+	}
+
+	cues := make([]subtitle.TTMLCue, 0, len(entries))
+	for _, entry := range entries {
+		cue := subtitle.TTMLCue{Start: entry.Start, End: entry.End}
+		if entry.IsAudioEvent {
+			cue.Style = ttmlEventStyle
+		} else {
+			cue.Style = speakers.styles[dominantSpeaker(entry.Words)]
+		}
+
+		if entry.EventText != "" {
+			cue.Spans = append(cue.Spans, subtitle.TTMLSpan{Text: bidi.ApplyLines(entry.EventText, opts.RTL) + "\n", Style: ttmlEventStyle})
+		}
+		source := bidi.ApplyLines(optimizeEntryDisplay(entry, opts.Limits.cpl(entry.Text), opts.Limits.WidthMode), opts.RTL)
+		if entry.Translation == "" || opts.Bilingual {
+			spans := speakerSpans(source, entry.Words, speakers.styles)
+			if len(spans) > 1 {
+				cue.Style = ""
+			}
+			cue.Spans = append(cue.Spans, spans...)
+		}
+		if entry.Translation != "" {
+			translated := bidi.ApplyLines(optimizeTextDisplay(entry.Translation, opts.Target.cpl(entry.Translation), opts.Target.WidthMode), opts.RTL)
+			if opts.Bilingual {
+				translated = "\n" + translated
+			}
+			cue.Spans = append(cue.Spans, subtitle.TTMLSpan{Text: translated})
+		}
+		cues = append(cues, cue)
+	}
+
+	return subtitle.FormatTTML(subtitle.TTMLOptions{
+		DFXP:      opts.Format == config.FormatDFXP,
+		Lang:      opts.Lang,
+		Title:     opts.Title,
+		FrameRate: opts.FrameRate,
+		Timing:    opts.TTMLTiming,
+	}, styles, cues)
+}
+
+// ttmlSpeakers maps speaker IDs to style IDs.
+type ttmlSpeakers struct {
+	order  []string
+	styles map[string]string
+}
+
+// speakerStyles assigns a style to each speaker in order of appearance.
+// A transcript with fewer than two speakers gets none, so its text keeps
+// the default style.
+func speakerStyles(entries []SubtitleEntry) ttmlSpeakers {
+	s := ttmlSpeakers{styles: map[string]string{}}
+	for _, entry := range entries {
+		for _, w := range entry.Words {
+			if w.SpeakerID == "" || w.Type == "audio_event" {
+				continue
+			}
+			if _, ok := s.styles[w.SpeakerID]; !ok {
+				s.order = append(s.order, w.SpeakerID)
+				s.styles[w.SpeakerID] = fmt.Sprintf("s_speaker%d", len(s.order))
+			}
+		}
+	}
+	if len(s.order) < 2 {
+		return ttmlSpeakers{styles: map[string]string{}}
+	}
+	return s
+}
+
+// dominantSpeaker returns the speaker of most of the characters in words.
+func dominantSpeaker(words []Word) string {
+	chars := map[string]int{}
+	best := ""
+	for _, w := range words {
+		if w.SpeakerID == "" || w.Type == "audio_event" {
+			continue
+		}
+		chars[w.SpeakerID] += len(strings.TrimSpace(w.Text))
+		if chars[w.SpeakerID] > chars[best] {
+			best = w.SpeakerID
+		}
+	}
+	return best
+}
+
+// speakerSpans divides text, the laid-out source lines of a cue, into one
+// span per run of words by the same speaker. Text whose words cannot be
+// found, or by a single speaker, is returned as a single unstyled span.
+func speakerSpans(text string, words []Word, styles map[string]string) []subtitle.TTMLSpan {
+	whole := []subtitle.TTMLSpan{{Text: text}}
+	if len(styles) == 0 {
+		return whole
+	}
+	marks, ok := wordMarks(text, words)
+	if !ok {
+		return whole
+	}
+
+	var spans []subtitle.TTMLSpan
+	start, speaker := 0, ""
+	for _, m := range marks {
+		if m.Speaker == "" || m.Speaker == speaker {
+			continue
+		}
+		if speaker == "" {
+			// Text before the first word belongs to its speaker.
+			speaker = m.Speaker
+			continue
+		}
+		if m.Offset > start {
+			spans = append(spans, subtitle.TTMLSpan{Text: text[start:m.Offset], Style: styles[speaker]})
+		}
+		start, speaker = m.Offset, m.Speaker
+	}
+	if len(spans) == 0 {
+		return whole
+	}
+	return append(spans, subtitle.TTMLSpan{Text: text[start:], Style: styles[speaker]})
+}
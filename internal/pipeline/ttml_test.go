@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/config"
+	"scribe2srt/internal/subtitle"
+)
+
+func ttmlEntries() []SubtitleEntry {
+	return []SubtitleEntry{
+		{Text: "(door slams)", Start: 0, End: 1, IsAudioEvent: true},
+		{Text: "Who's there? It's me.", Start: 1, End: 3, Words: []Word{
+			{Text: "Who's", Start: 1, End: 1.3, Type: "word", SpeakerID: "speaker_0"},
+			{Text: "there?", Start: 1.3, End: 1.8, Type: "word", SpeakerID: "speaker_0"},
+			{Text: "It's", Start: 2, End: 2.3, Type: "word", SpeakerID: "speaker_1"},
+			{Text: "me.", Start: 2.3, End: 3, Type: "word", SpeakerID: "speaker_1"},
+		}},
+		{Text: "Come in.", Start: 3.2, End: 4, Words: []Word{
+			{Text: "Come", Start: 3.2, End: 3.5, Type: "word", SpeakerID: "speaker_0"},
+			{Text: "in.", Start: 3.5, End: 4, Type: "word", SpeakerID: "speaker_0"},
+		}},
+	}
+}
+
+func ttmlOptions(format config.OutputFormat) outputOptions {
+	limits := newScriptLimits(defaultSettings(), false)
+	return outputOptions{Format: format, Lang: "en", Limits: limits, Target: limits}
+}
+
+func TestSpeakerSpans(t *testing.T) {
+	entry := ttmlEntries()[1]
+	styles := map[string]string{"speaker_0": "s_speaker1", "speaker_1": "s_speaker2"}
+
+	got := speakerSpans("Who's there?\nIt's me.", entry.Words, styles)
+	want := []subtitle.TTMLSpan{
+		{Text: "Who's there?\n", Style: "s_speaker1"},
+		{Text: "It's me.", Style: "s_speaker2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("spans = %q, want %q", got, want)
+	}
+
+	// Text that doesn't match the words stays whole.
+	got = speakerSpans("Somebody else", entry.Words, styles)
+	if len(got) != 1 || got[0].Style != "" {
+		t.Errorf("mismatched text spans = %q", got)
+	}
+}
+
+func TestSpeakerStyles_SingleSpeaker(t *testing.T) {
+	entries := ttmlEntries()[2:]
+	if s := speakerStyles(entries); len(s.order) != 0 || len(s.styles) != 0 {
+		t.Errorf("single speaker got styles %v", s)
+	}
+}
+
+func TestGenerateTTML(t *testing.T) {
+	doc := mustGenerate(t, ttmlEntries(), ttmlOptions(config.FormatTTML))
+	for _, s := range []string{
+		`ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text"`,
+		`xml:lang="en"`,
+		`<style xml:id="s_event" tts:fontStyle="italic"/>`,
+		`<style xml:id="s_speaker1" tts:color="white"/>`,
+		`<style xml:id="s_speaker2" tts:color="yellow"/>`,
+		`<p begin="00:00:00.000" end="00:00:01.000" style="s_event">(door slams)</p>`,
+		`<p begin="00:00:03.200" end="00:00:04.000" style="s_speaker1">Come in.</p>`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("TTML lacks %s:\n%s", s, doc)
+		}
+	}
+	if !strings.Contains(doc, `<span style="s_speaker1">Who's there?`) || !strings.Contains(doc, `<span style="s_speaker2">It's me.</span>`) {
+		t.Errorf("TTML lacks per-speaker spans:\n%s", doc)
+	}
+}
+
+func TestGenerateTTML_DFXPBilingual(t *testing.T) {
+	entries := []SubtitleEntry{{Text: "Blue skies", Translation: "Cielos azules", Start: 4, End: 5.5}}
+	opts := ttmlOptions(config.FormatDFXP)
+	opts.Bilingual = true
+	doc := mustGenerate(t, entries, opts)
+	for _, s := range []string{
+		`ttp:profile="http://www.w3.org/ns/ttml/profile/dfxp-presentation"`,
+		`<p begin="00:00:04.000" end="00:00:05.500">Blue skies<br/>Cielos azules</p>`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("DFXP lacks %s:\n%s", s, doc)
+		}
+	}
+}
+
+func TestProcess_TTMLLanguage(t *testing.T) {
+	settings := defaultSettings()
+	settings.Format = config.FormatTTML
+
+	transcript := karaokeTranscript()
+	transcript.LanguageCode = "eng"
+	doc := Process(transcript, settings)
+	if !strings.Contains(doc, `xml:lang="en"`) {
+		t.Errorf("TTML lacks xml:lang=\"en\":\n%s", doc)
+	}
+}
@@ -6,6 +6,8 @@ type Word struct {
 	Start float64 `json:"start"`
 	End   float64 `json:"end"`
 	Type  string  `json:"type"` // "word", "spacing", "audio_event"
+	// SpeakerID identifies the diarized speaker of the word, when known.
+	SpeakerID string `json:"speaker_id,omitempty"`
 }
 
 // SubtitleEntry represents one subtitle block.
@@ -17,6 +19,13 @@ type SubtitleEntry struct {
 	IsAudioEvent bool
 	WordCount    int
 	CharCount    int
+
+	// EventText holds audio-event labels stacked on their own line above
+	// the speech text.
+	EventText string
+	// Translation is the translated text of a speech cue, when the
+	// translation stage ran.
+	Translation string
 }
 
 // TranscriptResponse is the top-level JSON structure from ElevenLabs.
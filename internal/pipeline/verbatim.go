@@ -0,0 +1,293 @@
+package pipeline
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scribe2srt/internal/lang"
+)
+
+// defaultFillers lists the filler words removed by clean verbatim, keyed by
+// ISO 639-1 code. Entries are compared after normalizeToken.
+var defaultFillers = map[string][]string{
+	"en": {"um", "umm", "uh", "uhh", "er", "erm", "ah", "hmm", "mm"},
+	// えーと えっと ええと えー あのー うーん
+	"ja": {"\u3048\u30fc\u3068", "\u3048\u3063\u3068", "\u3048\u3048\u3068", "\u3048\u30fc", "\u3042\u306e\u30fc", "\u3046\u30fc\u3093"},
+	// 嗯 呃 额 那个
+	"zh": {"\u55ef", "\u5443", "\u989d", "\u90a3\u4e2a"},
+	// 음 어 저기요
+	"ko": {"\uc74c", "\uc5b4", "\uc800\uae30\uc694"},
+	// äh ähm öhm hm
+	"de": {"\u00e4h", "\u00e4hm", "\u00f6hm", "hm"},
+	"fr": {"euh", "heu", "hum"},
+	"es": {"eh", "em", "mmm"},
+	"it": {"ehm", "eh", "mah"},
+	// hum ahn hã
+	"pt": {"hum", "ahn", "h\u00e3"},
+}
+
+// legitimateRepeats lists, by ISO 639-1 code, words that are often
+// correctly doubled ("I said that that was fine", "dass das das Beste
+// ist", "nous nous sommes vus") and are never collapsed. Entries are
+// compared after normalizeToken.
+var legitimateRepeats = map[string][]string{
+	"en": {"that", "had"},
+	"de": {"das", "die", "der", "den", "dem", "sie"},
+	"nl": {"dat", "die"},
+	"fr": {"nous", "vous"},
+}
+
+// fillerJoinGap is the largest silence, in seconds, between a removed word
+// and a neighbour for the neighbour to take over its time.
+const fillerJoinGap = 0.2
+
+// maxFillerTokens is the largest number of recognised tokens one filler may
+// be split into, e.g. "え" "ー" "と".
+const maxFillerTokens = 4
+
+// verbatimCleaner removes filler words and collapses stutters and immediate
+// repetitions for clean-verbatim subtitles.
+type verbatimCleaner struct {
+	fillers map[string]bool
+	// repeats holds the language's legitimateRepeats.
+	repeats map[string]bool
+}
+
+// newVerbatimCleaner builds a cleaner for a language. An entry for the
+// language in overrides replaces the built-in filler list.
+func newVerbatimCleaner(langCode string, overrides map[string][]string) *verbatimCleaner {
+	code := lang.Normalize(langCode)
+	if l, ok := lang.Lookup(code); ok {
+		code = l.Code()
+	}
+
+	list, ok := overrides[code]
+	if !ok {
+		list = defaultFillers[code]
+	}
+	c := &verbatimCleaner{fillers: make(map[string]bool, len(list)), repeats: make(map[string]bool)}
+	for _, f := range list {
+		if key := normalizeToken(f); key != "" {
+			c.fillers[key] = true
+		}
+	}
+	for _, w := range legitimateRepeats[code] {
+		c.repeats[normalizeToken(w)] = true
+	}
+	return c
+}
+
+// normalizeToken lower-cases a word and strips spaces, punctuation and
+// symbols for comparison.
+func normalizeToken(text string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, text)
+}
+
+// clean returns words without fillers and repetitions. The time of removed
+// words goes to the neighbours they directly touch; otherwise it becomes
+// part of the gap between them.
+func (c *verbatimCleaner) clean(words []Word) []Word {
+	if len(words) == 0 {
+		return words
+	}
+
+	words = append([]Word(nil), words...)
+	for i := range words {
+		words[i].Text = collapseStutter(words[i].Text)
+	}
+
+	filler := c.markFillers(words)
+	repeat := c.markRepeats(words, filler)
+
+	out := make([]Word, 0, len(words))
+	for i := 0; i < len(words); {
+		if !filler[i] && !repeat[i] {
+			out = append(out, words[i])
+			i++
+			continue
+		}
+
+		// A run of removed words ends at the next kept one.
+		j := i
+		repeated := false
+		for j < len(words) && (filler[j] || repeat[j]) {
+			repeated = repeated || repeat[j]
+			j++
+		}
+		var prev, next *Word
+		if len(out) > 0 {
+			prev = &out[len(out)-1]
+		}
+		if j < len(words) {
+			next = &words[j]
+		}
+		giveTime(words[i:j], prev, next, repeated)
+		i = j
+	}
+	return out
+}
+
+// markFillers flags the words that form filler words. A filler may span up
+// to maxFillerTokens consecutive tokens.
+func (c *verbatimCleaner) markFillers(words []Word) []bool {
+	filler := make([]bool, len(words))
+	if len(c.fillers) == 0 {
+		return filler
+	}
+	for i := 0; i < len(words); i++ {
+		// Prefer the longest filler, so "えー" "と" is one filler and not
+		// "えー" followed by a stray "と".
+		key, longest := "", 0
+		for n := 1; n <= maxFillerTokens && i+n <= len(words); n++ {
+			key += normalizeToken(words[i+n-1].Text)
+			if key == "" {
+				break
+			}
+			if c.fillers[key] {
+				longest = n
+			}
+		}
+		for k := i; k < i+longest; k++ {
+			filler[k] = true
+		}
+		i += max(longest-1, 0)
+	}
+	return filler
+}
+
+// markRepeats flags all but the last word of each run of identical words,
+// ignoring fillers between them. A run ends at sentence-final punctuation,
+// so "Stop. Stop." keeps both sentences. Scripts without spaces use
+// reduplication grammatically ("看看"), so there only runs of three or more
+// collapse.
+func (c *verbatimCleaner) markRepeats(words []Word, filler []bool) []bool {
+	repeat := make([]bool, len(words))
+
+	var run []int
+	flush := func() {
+		if len(run) < 2 {
+			return
+		}
+		first, _ := utf8.DecodeRuneInString(strings.TrimSpace(words[run[0]].Text))
+		if isNoSpaceRune(first) && len(run) < 3 {
+			return
+		}
+		for _, k := range run[:len(run)-1] {
+			repeat[k] = true
+		}
+	}
+
+	for i, w := range words {
+		if filler[i] {
+			continue
+		}
+		key := normalizeToken(w.Text)
+		if len(run) > 0 && key != "" && key == normalizeToken(words[run[0]].Text) && !c.repeats[key] {
+			run = append(run, i)
+		} else {
+			flush()
+			run = []int{i}
+		}
+		if r, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(w.Text)); isSentenceFinal(r) {
+			flush()
+			run = nil
+		}
+	}
+	flush()
+	return repeat
+}
+
+// collapseStutter reduces a hyphenated stutter inside one token to the word
+// itself: "I-I-I" becomes "I" and "th-th-think" becomes "think". Every part
+// before the last must be a beginning of the last one, and a two-part token
+// only counts when its first part is a single letter, so ordinary words such
+// as "so-so", "bye-bye" or "re-read" are left alone.
+func collapseStutter(text string) string {
+	core := strings.TrimRightFunc(text, unicode.IsSpace)
+	trailing := text[len(core):]
+
+	parts := strings.Split(core, "-")
+	if len(parts) < 2 {
+		return text
+	}
+	last := parts[len(parts)-1]
+	lastKey := strings.ToLower(strings.TrimRightFunc(last, unicode.IsPunct))
+
+	for _, p := range parts[:len(parts)-1] {
+		key := strings.ToLower(p)
+		if key == "" || !strings.HasPrefix(lastKey, key) {
+			return text
+		}
+	}
+	if len(parts) == 2 && utf8.RuneCountInString(parts[0]) > 1 {
+		return text
+	}
+	return last + trailing
+}
+
+// giveTime hands the time of the removed words to their neighbours. A
+// repeated word's kept occurrence starts where the repetition began, so the
+// cue starts when the speaker does. A filler's time is split between the
+// neighbours that directly touch it.
+func giveTime(removed []Word, prev, next *Word, repeated bool) {
+	start, end := removed[0].Start, removed[len(removed)-1].End
+
+	if repeated && next != nil && next.Start-end <= fillerJoinGap {
+		next.Start = start
+		carryPunctuation(removed, prev, next)
+		return
+	}
+
+	mid := (start + end) / 2
+	prevTouches := prev != nil && start-prev.End <= fillerJoinGap
+	nextTouches := next != nil && next.Start-end <= fillerJoinGap
+	switch {
+	case prevTouches && nextTouches:
+		prev.End = max(prev.End, mid)
+		next.Start = min(next.Start, mid)
+	case prevTouches:
+		prev.End = max(prev.End, end)
+	case nextTouches:
+		next.Start = min(next.Start, start)
+	}
+
+	carryPunctuation(removed, prev, next)
+}
+
+// carryPunctuation keeps the sentence structure intact when fillers or
+// repetitions are removed: sentence-final punctuation on the last removed
+// word moves to the word before it, and a removed word that began a
+// sentence passes its capital letter on to the next word.
+func carryPunctuation(removed []Word, prev, next *Word) {
+	lastText := strings.TrimSpace(removed[len(removed)-1].Text)
+	if r, _ := utf8.DecodeLastRuneInString(lastText); prev != nil && isSentenceFinal(r) {
+		prevText := strings.TrimRightFunc(prev.Text, unicode.IsSpace)
+		if p, _ := utf8.DecodeLastRuneInString(prevText); !unicode.IsPunct(p) {
+			prev.Text = prevText + string(r) + prev.Text[len(prevText):]
+		}
+	}
+
+	first, _ := utf8.DecodeRuneInString(strings.TrimSpace(removed[0].Text))
+	if next == nil || !unicode.IsUpper(first) {
+		return
+	}
+	text := strings.TrimLeftFunc(next.Text, unicode.IsSpace)
+	if r, size := utf8.DecodeRuneInString(text); unicode.IsLower(r) {
+		next.Text = next.Text[:len(next.Text)-len(text)] + string(unicode.ToUpper(r)) + text[size:]
+	}
+}
+
+// isSentenceFinal reports whether r ends a sentence.
+func isSentenceFinal(r rune) bool {
+	switch r {
+	case '.', '?', '!', '\u3002', '\uff1f', '\uff01': // 。？！
+		return true
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+func wordsText(words []Word) string {
+	var sb strings.Builder
+	for _, w := range words {
+		sb.WriteString(w.Text)
+	}
+	return sb.String()
+}
+
+func TestCollapseStutter(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"I-I-I ", "I "},
+		{"I-I", "I"},
+		{"th-th-think", "think"},
+		{"w-we", "we"},
+		{"so-so", "so-so"},
+		{"bye-bye,", "bye-bye,"},
+		{"re-read", "re-read"},
+		{"well-known", "well-known"},
+		{"hello", "hello"},
+	}
+
+	for _, tt := range tests {
+		if got := collapseStutter(tt.in); got != tt.want {
+			t.Errorf("collapseStutter(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestVerbatimCleaner_Fillers(t *testing.T) {
+	c := newVerbatimCleaner("en", nil)
+	words := []Word{
+		{Text: "Um, ", Start: 0.0, End: 0.3},
+		{Text: "so ", Start: 0.35, End: 0.5},
+		{Text: "we ", Start: 0.5, End: 0.7},
+		{Text: "uh ", Start: 0.75, End: 1.0},
+		{Text: "went.", Start: 1.05, End: 1.4},
+	}
+
+	got := c.clean(words)
+	if text := wordsText(got); text != "So we went." {
+		t.Errorf("text = %q, want %q", text, "So we went.")
+	}
+	if got[0].Start != 0.0 {
+		t.Errorf("first word Start = %v, want 0 (time of the removed filler)", got[0].Start)
+	}
+	// "uh" is split between "we" and "went."
+	if got[1].End != 0.875 || got[2].Start != 0.875 {
+		t.Errorf("neighbours of the removed filler = %v and %v, want both 0.875", got[1].End, got[2].Start)
+	}
+}
+
+func TestVerbatimCleaner_FillerAfterPause(t *testing.T) {
+	c := newVerbatimCleaner("en", nil)
+	words := []Word{
+		{Text: "Yes. ", Start: 0.0, End: 0.4},
+		{Text: "Uh ", Start: 2.0, End: 2.3},
+		{Text: "no.", Start: 3.0, End: 3.4},
+	}
+
+	got := c.clean(words)
+	if got[0].End != 0.4 || got[1].Start != 3.0 {
+		t.Errorf("a filler surrounded by silence should leave a gap, got %+v", got)
+	}
+	if got[1].Text != "No." {
+		t.Errorf("next word = %q, want it capitalised", got[1].Text)
+	}
+}
+
+func TestVerbatimCleaner_Repetitions(t *testing.T) {
+	c := newVerbatimCleaner("en", nil)
+	words := []Word{
+		{Text: "I ", Start: 0.0, End: 0.1},
+		{Text: "I ", Start: 0.15, End: 0.25},
+		{Text: "um ", Start: 0.3, End: 0.5},
+		{Text: "I ", Start: 0.55, End: 0.65},
+		{Text: "think ", Start: 0.7, End: 1.0},
+		{Text: "that ", Start: 1.0, End: 1.2},
+		{Text: "that ", Start: 1.2, End: 1.4},
+		{Text: "works.", Start: 1.4, End: 1.8},
+	}
+
+	got := c.clean(words)
+	if text := wordsText(got); text != "I think that that works." {
+		t.Errorf("text = %q", text)
+	}
+	if got[0].Start != 0.0 {
+		t.Errorf("kept word Start = %v, want 0 (start of the repetition)", got[0].Start)
+	}
+}
+
+func TestVerbatimCleaner_RepetitionsAcrossSentences(t *testing.T) {
+	c := newVerbatimCleaner("en", nil)
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"Stop. ", "Stop."}, "Stop. Stop."},
+		{[]string{"No, ", "no, ", "no."}, "No."},
+	}
+	for _, tt := range tests {
+		var words []Word
+		for i, text := range tt.words {
+			words = append(words, Word{Text: text, Start: float64(i) * 0.3, End: float64(i)*0.3 + 0.25})
+		}
+		if text := wordsText(c.clean(words)); text != tt.want {
+			t.Errorf("clean(%q) = %q, want %q", tt.words, text, tt.want)
+		}
+	}
+}
+
+func TestVerbatimCleaner_LanguageRepeats(t *testing.T) {
+	words := []Word{
+		{Text: "Ich ", Start: 0.0, End: 0.2},
+		{Text: "wei\u00df, ", Start: 0.2, End: 0.5}, // weiß
+		{Text: "dass ", Start: 0.5, End: 0.7},
+		{Text: "das ", Start: 0.7, End: 0.9},
+		{Text: "das ", Start: 0.9, End: 1.1},
+		{Text: "Beste ", Start: 1.1, End: 1.4},
+		{Text: "ist.", Start: 1.4, End: 1.6},
+	}
+	want := "Ich wei\u00df, dass das das Beste ist." // Ich weiß, dass das das Beste ist.
+	if text := wordsText(newVerbatimCleaner("de", nil).clean(words)); text != want {
+		t.Errorf("German text = %q, want %q", text, want)
+	}
+
+	// English exceptions do not apply to other languages, and German ones
+	// not to English.
+	words = []Word{{Text: "das ", Start: 0, End: 0.2}, {Text: "das", Start: 0.2, End: 0.4}}
+	if text := wordsText(newVerbatimCleaner("en", nil).clean(words)); text != "das" {
+		t.Errorf("English text = %q, want the repeat collapsed", text)
+	}
+}
+
+func TestVerbatimCleaner_CJK(t *testing.T) {
+	// えー|と、|これ|は|見|見
+	c := newVerbatimCleaner("ja", nil)
+	words := []Word{
+		{Text: "\u3048\u30fc", Start: 0.0, End: 0.2},
+		{Text: "\u3068\u3001", Start: 0.2, End: 0.4},
+		{Text: "\u3053\u308c", Start: 0.45, End: 0.7},
+		{Text: "\u306f", Start: 0.7, End: 0.8},
+		{Text: "\u898b", Start: 0.8, End: 0.9},
+		{Text: "\u898b", Start: 0.9, End: 1.0},
+	}
+
+	got := c.clean(words)
+	if text := wordsText(got); text != "\u3053\u308c\u306f\u898b\u898b" { // これは見見
+		t.Errorf("text = %q, want the multi-token filler removed and the reduplication kept", text)
+	}
+}
+
+func TestVerbatimCleaner_Overrides(t *testing.T) {
+	c := newVerbatimCleaner("eng", map[string][]string{"en": {"like"}})
+	words := []Word{
+		{Text: "It's ", Start: 0, End: 0.2},
+		{Text: "like ", Start: 0.2, End: 0.4},
+		{Text: "um ", Start: 0.4, End: 0.6},
+		{Text: "big.", Start: 0.6, End: 1.0},
+	}
+
+	if text := wordsText(c.clean(words)); text != "It's um big." {
+		t.Errorf("text = %q, want only the configured filler removed", text)
+	}
+}
+
+func TestProcess_CleanVerbatim(t *testing.T) {
+	transcript := &TranscriptResponse{
+		LanguageCode: "en",
+		Words: []Word{
+			{Text: "Um", Start: 0, End: 0.3, Type: "word"},
+			{Text: " ", Start: 0.3, End: 0.3, Type: "spacing"},
+			{Text: "hello", Start: 0.3, End: 0.8, Type: "word"},
+			{Text: " ", Start: 0.8, End: 0.8, Type: "spacing"},
+			{Text: "there.", Start: 0.8, End: 1.5, Type: "word"},
+		},
+	}
+
+	settings := defaultSettings()
+	if result := Process(transcript, settings); !strings.Contains(result, "Um hello there.") {
+		t.Errorf("full verbatim should be the default, got:\n%s", result)
+	}
+
+	settings.CleanVerbatim = true
+	if result := Process(transcript, settings); !strings.Contains(result, "\nHello there.") {
+		t.Errorf("expected the filler removed, got:\n%s", result)
+	}
+}
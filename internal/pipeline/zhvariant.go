@@ -0,0 +1,49 @@
+package pipeline
+
+import (
+	"unicode/utf8"
+
+	"scribe2srt/internal/lang"
+	"scribe2srt/internal/zhconv"
+)
+
+// convertChinese converts the word texts of a Chinese transcript to the
+// variant of c. The words are converted as one text, so that phrases the
+// recogniser split across several tokens are still matched as phrases. A
+// phrase whose replacement has a different length cannot be divided among
+// its words again; those words collapse into one that keeps their timing.
+func convertChinese(words []Word, c *zhconv.Converter, langCode string) []Word {
+	if c == nil || len(words) == 0 {
+		return words
+	}
+	if l, ok := lang.Lookup(langCode); !ok || l.Script != lang.Han {
+		return words
+	}
+
+	// bounds[i] is the rune offset of words[i] in the joined text.
+	bounds := make([]int, len(words)+1)
+	var text []byte
+	for i, w := range words {
+		bounds[i] = utf8.RuneCount(text)
+		text = append(text, w.Text...)
+	}
+	bounds[len(words)] = utf8.RuneCount(text)
+
+	converted, cuts := c.ConvertAligned(string(text))
+	out := []rune(converted)
+
+	result := make([]Word, 0, len(words))
+	first := 0
+	for i := range words {
+		end := cuts[bounds[i+1]]
+		if end < 0 {
+			continue
+		}
+		w := words[first]
+		w.Text = string(out[cuts[bounds[first]]:end])
+		w.End = words[i].End
+		result = append(result, w)
+		first = i + 1
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/zhconv"
+)
+
+func twConverter(t *testing.T) *zhconv.Converter {
+	t.Helper()
+	c, err := zhconv.New(zhconv.TW)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestConvertChinese_PhraseAcrossWords(t *testing.T) {
+	// 软 / 件 / 信 / 息 -> 軟 / 體 / 資 / 訊: the recogniser split both
+	// phrases, but they are still converted as phrases.
+	words := []Word{
+		{Text: "\u8f6f", Start: 0, End: 0.2},
+		{Text: "\u4ef6", Start: 0.2, End: 0.4},
+		{Text: "\u4fe1", Start: 0.4, End: 0.6},
+		{Text: "\u606f", Start: 0.6, End: 0.8},
+	}
+	got := convertChinese(words, twConverter(t), "zh")
+	want := []string{"\u8edf", "\u9ad4", "\u8cc7", "\u8a0a"} // 軟 體 資 訊
+	if len(got) != len(want) {
+		t.Fatalf("got %d words, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Text != want[i] || got[i].Start != words[i].Start {
+			t.Errorf("word %d = %+v, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestConvertChinese_LengthChangeMergesWords(t *testing.T) {
+	// U / 盘 / 坏了 -> 隨身碟 / 壞了
+	words := []Word{
+		{Text: "U", Start: 0, End: 0.2},
+		{Text: "\u76d8", Start: 0.2, End: 0.5},
+		{Text: "\u574f\u4e86", Start: 0.5, End: 1.0},
+	}
+	got := convertChinese(words, twConverter(t), "zh")
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want 2 words", got)
+	}
+	if got[0].Text != "\u96a8\u8eab\u789f" || got[0].Start != 0 || got[0].End != 0.5 { // 隨身碟
+		t.Errorf("first word = %+v", got[0])
+	}
+	if got[1].Text != "\u58de\u4e86" { // 壞了
+		t.Errorf("second word = %+v", got[1])
+	}
+}
+
+func TestConvertChinese_OtherLanguagesUnchanged(t *testing.T) {
+	// 里面 is Japanese here and must not be converted.
+	words := []Word{{Text: "\u91cc\u9762", Start: 0, End: 0.5}}
+	got := convertChinese(words, twConverter(t), "ja")
+	if got[0].Text != words[0].Text {
+		t.Errorf("Japanese text converted to %q", got[0].Text)
+	}
+}
+
+func TestProcess_ZhVariantBeforeLayout(t *testing.T) {
+	// 这个软件的信息。 -> 這個軟體的資訊。
+	transcript := &TranscriptResponse{
+		LanguageCode: "zh",
+		Words: []Word{
+			{Text: "\u8fd9\u4e2a", Start: 0, End: 0.5, Type: "word"},
+			{Text: "\u8f6f\u4ef6", Start: 0.5, End: 1.0, Type: "word"},
+			{Text: "\u7684", Start: 1.0, End: 1.2, Type: "word"},
+			{Text: "\u4fe1\u606f\u3002", Start: 1.2, End: 2.0, Type: "word"},
+		},
+	}
+	settings := defaultSettings()
+	settings.ZhConverter = twConverter(t)
+
+	result := Process(transcript, settings)
+	if !strings.Contains(result, "\u9019\u500b\u8edf\u9ad4\u7684\u8cc7\u8a0a\u3002") { // 這個軟體的資訊。
+		t.Errorf("expected Taiwan vocabulary, got:\n%s", result)
+	}
+}
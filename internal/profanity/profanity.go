@@ -0,0 +1,396 @@
+// Package profanity masks offensive words in subtitle text for clients that
+// require it.
+//
+// Word lists are plain text files with one entry per line; blank lines and
+// lines starting with # are ignored. An entry ending in * matches any word
+// that starts with it ("fuck*" also masks "fucking"), and an entry of several
+// words matches them in sequence. Entries are matched against whole words,
+// case-insensitively, except in scripts written without spaces between words
+// (Chinese, Japanese, Thai, ...), where they are found anywhere in the text.
+package profanity
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"scribe2srt/internal/lang"
+)
+
+// Style selects how a matched word is masked.
+type Style int
+
+const (
+	// Asterisks replaces every letter: "****".
+	Asterisks Style = iota
+	// FirstLetter keeps the first letter: "f***".
+	FirstLetter
+	// Replace substitutes the whole word with Filter.Replacement.
+	Replace
+)
+
+// DefaultReplacement is the text used by the Replace style when none is set.
+const DefaultReplacement = "[bleep]"
+
+// ParseStyle parses a --profanity-style value.
+func ParseStyle(s string) (Style, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "asterisks":
+		return Asterisks, nil
+	case "first-letter":
+		return FirstLetter, nil
+	case "replace":
+		return Replace, nil
+	}
+	return Asterisks, fmt.Errorf("unknown profanity style %q (want asterisks, first-letter or replace)", s)
+}
+
+func (s Style) String() string {
+	switch s {
+	case FirstLetter:
+		return "first-letter"
+	case Replace:
+		return "replace"
+	default:
+		return "asterisks"
+	}
+}
+
+// list holds the entries for one language.
+type list struct {
+	// phrases are entries of one or more words, longest first.
+	phrases []phrase
+	// substrings are entries in scripts without spaces, longest first.
+	substrings []string
+}
+
+// phrase is an entry of one or more words. With prefix set, its last word
+// also matches longer words that start with it.
+type phrase struct {
+	words  []string
+	prefix bool
+}
+
+// Match is a listed word or phrase found in a text, as byte offsets, with
+// the text that masks it.
+type Match struct {
+	Start       int
+	End         int
+	Replacement string
+}
+
+// Filter masks the words of its lists. Lists registered for the empty
+// language apply to every language.
+type Filter struct {
+	Style       Style
+	Replacement string
+
+	lists map[string]*list
+	allow map[string]bool
+}
+
+// New returns an empty filter.
+func New(style Style, replacement string) *Filter {
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	return &Filter{
+		Style:       style,
+		Replacement: replacement,
+		lists:       make(map[string]*list),
+		allow:       make(map[string]bool),
+	}
+}
+
+// languageKey returns the registry code for a language, so "eng", "en" and
+// "en-GB" share one list.
+func languageKey(code string) string {
+	if l, ok := lang.Lookup(code); ok {
+		return l.Code()
+	}
+	return lang.Normalize(code)
+}
+
+// LoadList adds the entries of a word list file for a language; an empty
+// language applies the list to all languages.
+func (f *Filter) LoadList(langCode, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open profanity list: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := readEntries(file)
+	if err != nil {
+		return fmt.Errorf("read profanity list %s: %w", path, err)
+	}
+	f.AddList(langCode, entries)
+	return nil
+}
+
+// LoadAllowList adds the words of an allow-list file. Allowed words are
+// never masked, even when a list entry matches them.
+func (f *Filter) LoadAllowList(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open profanity allow-list: %w", err)
+	}
+	defer file.Close()
+
+	entries, err := readEntries(file)
+	if err != nil {
+		return fmt.Errorf("read profanity allow-list %s: %w", path, err)
+	}
+	f.Allow(entries...)
+	return nil
+}
+
+// AddList adds entries for a language.
+func (f *Filter) AddList(langCode string, entries []string) {
+	key := languageKey(langCode)
+	l := f.lists[key]
+	if l == nil {
+		l = &list{}
+		f.lists[key] = l
+	}
+	for _, e := range entries {
+		e = strings.ToLower(strings.TrimSpace(e))
+		switch {
+		case e == "" || e == "*":
+		case hasNoSpaceScript(e):
+			l.substrings = append(l.substrings, strings.TrimSuffix(e, "*"))
+		default:
+			words := strings.Fields(strings.TrimSuffix(e, "*"))
+			if len(words) > 0 {
+				l.phrases = append(l.phrases, phrase{words: words, prefix: strings.HasSuffix(e, "*")})
+			}
+		}
+	}
+	sort.SliceStable(l.phrases, func(i, j int) bool {
+		return len(l.phrases[i].words) > len(l.phrases[j].words)
+	})
+	sort.SliceStable(l.substrings, func(i, j int) bool {
+		return len(l.substrings[i]) > len(l.substrings[j])
+	})
+}
+
+// Allow adds words to the allow-list.
+func (f *Filter) Allow(words ...string) {
+	for _, w := range words {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			f.allow[w] = true
+		}
+	}
+}
+
+func readEntries(r io.Reader) ([]string, error) {
+	var entries []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		entries = append(entries, line)
+	}
+	return entries, scanner.Err()
+}
+
+// Mask returns text with the listed words for langCode masked, and the
+// number of words masked.
+func (f *Filter) Mask(text, langCode string) (string, int) {
+	matches := f.Find(text, langCode)
+	if len(matches) == 0 {
+		return text, 0
+	}
+	var sb strings.Builder
+	pos := 0
+	for _, m := range matches {
+		sb.WriteString(text[pos:m.Start])
+		sb.WriteString(m.Replacement)
+		pos = m.End
+	}
+	sb.WriteString(text[pos:])
+	return sb.String(), len(matches)
+}
+
+// Find returns the listed words and phrases for langCode in text, in order
+// and without overlaps. Surrounding spaces and punctuation are not part of a
+// match.
+func (f *Filter) Find(text, langCode string) []Match {
+	if f == nil || len(f.lists) == 0 {
+		return nil
+	}
+	lists := []*list{f.lists[""], f.lists[languageKey(langCode)]}
+
+	var matches []Match
+	tokens := tokenize(text)
+	for i := 0; i < len(tokens); {
+		n := 0
+		for _, l := range lists {
+			if l != nil {
+				n = max(n, l.matchPhrase(tokens[i:]))
+			}
+		}
+		if n == 0 {
+			i++
+			continue
+		}
+		start, end := tokens[i].start, tokens[i+n-1].end
+		if !f.allow[strings.ToLower(text[start:end])] {
+			matches = append(matches, Match{Start: start, End: end, Replacement: f.mask(text[start:end])})
+		}
+		i += n
+	}
+	matches = append(matches, f.findSubstrings(text, lists)...)
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+// token is a word of a text, without surrounding punctuation: key is its
+// lower-case form and start and end its byte offsets.
+type token struct {
+	key        string
+	start, end int
+}
+
+// tokenize splits text into its space-separated words. Words in scripts
+// without spaces are left to the substring entries.
+func tokenize(text string) []token {
+	var tokens []token
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if unicode.IsSpace(r) {
+			i += size
+			continue
+		}
+		end := i + strings.IndexFunc(text[i:]+" ", unicode.IsSpace)
+		field := text[i:end]
+		core := strings.TrimFunc(field, isEdge)
+		if core != "" && !hasNoSpaceScript(core) {
+			start := i + strings.Index(field, core)
+			tokens = append(tokens, token{key: strings.ToLower(core), start: start, end: start + len(core)})
+		}
+		i = end
+	}
+	return tokens
+}
+
+// matchPhrase returns the number of tokens at the start of tokens matched by
+// the longest phrase of the list, or 0.
+func (l *list) matchPhrase(tokens []token) int {
+	for _, p := range l.phrases {
+		if len(p.words) > len(tokens) {
+			continue
+		}
+		ok := true
+		for k, w := range p.words {
+			if tokens[k].key != w && !(p.prefix && k == len(p.words)-1 && strings.HasPrefix(tokens[k].key, w)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return len(p.words)
+		}
+	}
+	return 0
+}
+
+// findSubstrings finds the entries in scripts without spaces anywhere in
+// text. These scripts have no case, so the entries are matched as written.
+// Occurrences of allowed words protect the entries inside them.
+func (f *Filter) findSubstrings(text string, lists []*list) []Match {
+	var taken [][2]int
+	free := func(start, end int) bool {
+		for _, t := range taken {
+			if start < t[1] && t[0] < end {
+				return false
+			}
+		}
+		return true
+	}
+	for w := range f.allow {
+		if hasNoSpaceScript(w) {
+			for _, i := range indexAll(text, w) {
+				taken = append(taken, [2]int{i, i + len(w)})
+			}
+		}
+	}
+	allowed := len(taken)
+
+	for _, l := range lists {
+		if l == nil {
+			continue
+		}
+		for _, s := range l.substrings {
+			if s == "" {
+				continue
+			}
+			for _, i := range indexAll(text, s) {
+				if free(i, i+len(s)) {
+					taken = append(taken, [2]int{i, i + len(s)})
+				}
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(taken)-allowed)
+	for _, t := range taken[allowed:] {
+		matches = append(matches, Match{Start: t[0], End: t[1], Replacement: f.mask(text[t[0]:t[1]])})
+	}
+	return matches
+}
+
+// indexAll returns the byte offsets of the non-overlapping occurrences of
+// sub in s.
+func indexAll(s, sub string) []int {
+	var offsets []int
+	for from := 0; ; {
+		i := strings.Index(s[from:], sub)
+		if i < 0 {
+			return offsets
+		}
+		offsets = append(offsets, from+i)
+		from += i + len(sub)
+	}
+}
+
+// mask masks one word according to the filter style.
+func (f *Filter) mask(word string) string {
+	switch f.Style {
+	case Replace:
+		return f.Replacement
+	case FirstLetter:
+		first, size := utf8.DecodeRuneInString(word)
+		return string(first) + strings.Map(maskRune, word[size:])
+	default:
+		return strings.Map(maskRune, word)
+	}
+}
+
+func maskRune(r rune) rune {
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+		return '*'
+	}
+	return r
+}
+
+// isEdge reports whether r may surround a word without being part of it.
+func isEdge(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) && r != '*'
+}
+
+// hasNoSpaceScript reports whether s contains a script written without
+// spaces between words.
+func hasNoSpaceScript(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana,
+			unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar)
+	}) >= 0
+}
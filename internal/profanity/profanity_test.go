@@ -0,0 +1,139 @@
+package profanity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Style
+		wantErr bool
+	}{
+		{"", Asterisks, false},
+		{"asterisks", Asterisks, false},
+		{"First-Letter", FirstLetter, false},
+		{"replace", Replace, false},
+		{"bleep", Asterisks, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStyle(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStyle(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseStyle(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMask_Styles(t *testing.T) {
+	tests := []struct {
+		style Style
+		in    string
+		want  string
+	}{
+		{Asterisks, "Damn, ", "****, "},
+		{FirstLetter, "Damn, ", "D***, "},
+		{Replace, "Damn, ", "[bleep], "},
+		{FirstLetter, "damned!", "d*****!"},
+		{FirstLetter, "dam ", "dam "},
+	}
+
+	for _, tt := range tests {
+		f := New(tt.style, "")
+		f.AddList("", []string{"damn*"})
+		if got, _ := f.Mask(tt.in, "en"); got != tt.want {
+			t.Errorf("%v: Mask(%q) = %q, want %q", tt.style, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMask_LanguagesAndAllowList(t *testing.T) {
+	f := New(Asterisks, "")
+	f.AddList("eng", []string{"hell"})
+	f.AddList("de", []string{"mist"})
+	f.Allow("Hell")
+
+	if got, n := f.Mask("hell ", "en"); got != "hell " || n != 0 {
+		t.Errorf("allowed word was masked: %q", got)
+	}
+	if got, _ := f.Mask("mist", "en"); got != "mist" {
+		t.Errorf("German list applied to English: %q", got)
+	}
+	if got, _ := f.Mask("Mist!", "de-AT"); got != "****!" {
+		t.Errorf("Mask(%q, de-AT) = %q, want ****!", "Mist!", got)
+	}
+}
+
+func TestMask_NoSpaceScripts(t *testing.T) {
+	f := New(FirstLetter, "")
+	f.AddList("zh", []string{"\u6df7\u86cb"}) // 混蛋
+
+	got, n := f.Mask("\u4f60\u8fd9\u4e2a\u6df7\u86cb\uff01", "zh")        // 你这个混蛋！
+	if want := "\u4f60\u8fd9\u4e2a\u6df7*\uff01"; got != want || n != 1 { // 你这个混*！
+		t.Errorf("Mask() = %q, %d; want %q, 1", got, n, want)
+	}
+}
+
+func TestMask_Phrases(t *testing.T) {
+	f := New(Asterisks, "")
+	f.AddList("", []string{"son of a bitch", "holy crap*"})
+
+	tests := []struct{ in, want string }{
+		{"You son of a bitch!", "You *** ** * *****!"},
+		{"Holy crapola.", "**** *******."},
+		{"A son of a gun.", "A son of a gun."},
+	}
+	for _, tt := range tests {
+		if got, _ := f.Mask(tt.in, "en"); got != tt.want {
+			t.Errorf("Mask(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMask_AllowedWordContainingEntry(t *testing.T) {
+	f := New(Asterisks, "")
+	f.AddList("ja", []string{"\u304f\u305d"}) // くそ
+	f.Allow("\u304f\u305d\u771f\u9762\u76ee") // くそ真面目
+
+	// くそ真面目だ、くそ。
+	got, n := f.Mask("\u304f\u305d\u771f\u9762\u76ee\u3060\u3001\u304f\u305d\u3002", "ja")
+	if want := "\u304f\u305d\u771f\u9762\u76ee\u3060\u3001**\u3002"; got != want || n != 1 { // くそ真面目だ、**。
+		t.Errorf("Mask() = %q, %d; want %q, 1", got, n, want)
+	}
+}
+
+func TestLoadList(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "en.txt")
+	allow := filepath.Join(dir, "allow.txt")
+	if err := os.WriteFile(list, []byte("# swears\ncrap\n\nbloody\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(allow, []byte("bloody\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := New(Asterisks, "")
+	if err := f.LoadList("en", list); err != nil {
+		t.Fatalf("LoadList() error = %v", err)
+	}
+	if err := f.LoadAllowList(allow); err != nil {
+		t.Fatalf("LoadAllowList() error = %v", err)
+	}
+
+	if got, _ := f.Mask("Crap", "en"); got != "****" {
+		t.Errorf("Mask(Crap) = %q", got)
+	}
+	if got, _ := f.Mask("bloody", "en"); got != "bloody" {
+		t.Errorf("Mask(bloody) = %q, want it allowed", got)
+	}
+	if err := f.LoadList("en", filepath.Join(dir, "missing.txt")); err == nil {
+		t.Error("expected an error for a missing list")
+	}
+}
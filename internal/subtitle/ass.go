@@ -0,0 +1,85 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ASS alignments (numpad layout) used by the styles below.
+const (
+	AlignBottom = 2
+	AlignTop    = 8
+)
+
+// ASSStyle is one entry of the [V4+ Styles] section.
+type ASSStyle struct {
+	Name     string
+	FontName string
+	FontSize int
+	// PrimaryColour and SecondaryColour are &HAABBGGRR values. The
+	// secondary colour is the not-yet-sung colour of karaoke effects.
+	PrimaryColour   string
+	SecondaryColour string
+	Alignment       int
+	MarginV         int
+}
+
+// DefaultASSStyle returns a white, outlined bottom-centre style.
+func DefaultASSStyle(name string) ASSStyle {
+	return ASSStyle{
+		Name:            name,
+		FontName:        "Arial",
+		FontSize:        48,
+		PrimaryColour:   "&H00FFFFFF",
+		SecondaryColour: "&H0000FFFF",
+		Alignment:       AlignBottom,
+		MarginV:         40,
+	}
+}
+
+// ASSEvent is one Dialogue line. Text lines are separated by "\n" and
+// written as \N; override tags such as {\k50} are passed through.
+type ASSEvent struct {
+	Start float64
+	End   float64
+	Style string
+	Text  string
+}
+
+// FormatASS writes a complete ASS script for a 1920x1080 canvas.
+func FormatASS(title string, styles []ASSStyle, events []ASSEvent) string {
+	var sb strings.Builder
+	sb.WriteString("[Script Info]\n")
+	if title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n", title)
+	}
+	sb.WriteString("ScriptType: v4.00+\n")
+	sb.WriteString("WrapStyle: 0\n")
+	sb.WriteString("ScaledBorderAndShadow: yes\n")
+	sb.WriteString("PlayResX: 1920\n")
+	sb.WriteString("PlayResY: 1080\n")
+
+	sb.WriteString("\n[V4+ Styles]\n")
+	sb.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	for _, s := range styles {
+		fmt.Fprintf(&sb, "Style: %s,%s,%d,%s,%s,&H00000000,&H80000000,0,0,0,0,100,100,0,0,1,2,1,%d,60,60,%d,1\n",
+			s.Name, s.FontName, s.FontSize, s.PrimaryColour, s.SecondaryColour, s.Alignment, s.MarginV)
+	}
+
+	sb.WriteString("\n[Events]\n")
+	sb.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, e := range events {
+		fmt.Fprintf(&sb, "Dialogue: 0,%s,%s,%s,,0,0,0,,%s\n",
+			FormatASSTime(e.Start), FormatASSTime(e.End), e.Style, assText(e.Text))
+	}
+	return sb.String()
+}
+
+// assText converts line breaks to \N and keeps every line on one physical
+// line of the script.
+func assText(text string) string {
+	text = strings.ReplaceAll(text, "\r", "")
+	return strings.ReplaceAll(text, "\n", `\N`)
+}
@@ -0,0 +1,39 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatASSTime(t *testing.T) {
+	tests := map[float64]string{
+		0:        "0:00:00.00",
+		1.254:    "0:00:01.25",
+		61.999:   "0:01:02.00",
+		3723.456: "1:02:03.46",
+	}
+	for in, want := range tests {
+		if got := FormatASSTime(in); got != want {
+			t.Errorf("FormatASSTime(%g) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatASS(t *testing.T) {
+	top := DefaultASSStyle("Top")
+	top.Alignment = AlignTop
+	ass := FormatASS("Demo", []ASSStyle{top, DefaultASSStyle("Bottom")}, []ASSEvent{
+		{Start: 1, End: 2.5, Style: "Top", Text: "first\nsecond"},
+	})
+
+	for _, want := range []string{
+		"[Script Info]\nTitle: Demo\nScriptType: v4.00+\n",
+		"Style: Top,Arial,48,&H00FFFFFF,&H0000FFFF,",
+		",8,60,60,40,1\n",
+		"Dialogue: 0,0:00:01.00,0:00:02.50,Top,,0,0,0,,first\\Nsecond\n",
+	} {
+		if !strings.Contains(ass, want) {
+			t.Errorf("ASS output missing %q:\n%s", want, ass)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LRCLine is one timed line of LRC lyrics. Text may hold enhanced-LRC word
+// timestamps; an empty Text clears the previous line.
+type LRCLine struct {
+	Time float64
+	Text string
+}
+
+// FormatLRCTime formats seconds as an LRC timestamp mm:ss.xx. Minutes are
+// not wrapped into hours, as LRC has no hour field.
+func FormatLRCTime(seconds float64) string {
+	cs := (millis(seconds) + 5) / 10
+	return fmt.Sprintf("%02d:%02d.%02d", cs/6000, cs/100%60, cs%100)
+}
+
+// FormatLRC writes LRC lyrics with [ti:] and [ar:] tags for the title and
+// artist when they are known. Lines are written in the order given, each on
+// a single physical line.
+func FormatLRC(title, artist string, lines []LRCLine) string {
+	var sb strings.Builder
+	if title = lrcTagValue(title); title != "" {
+		fmt.Fprintf(&sb, "[ti:%s]\n", title)
+	}
+	if artist = lrcTagValue(artist); artist != "" {
+		fmt.Fprintf(&sb, "[ar:%s]\n", artist)
+	}
+	for _, l := range lines {
+		text := strings.Join(strings.Fields(l.Text), " ")
+		fmt.Fprintf(&sb, "[%s]%s\n", FormatLRCTime(l.Time), text)
+	}
+	return sb.String()
+}
+
+// lrcTagValue keeps a metadata value on one line and drops the closing
+// bracket that would end the tag early.
+func lrcTagValue(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, "]", "")), " ")
+}
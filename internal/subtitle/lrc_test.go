@@ -0,0 +1,28 @@
+package subtitle
+
+import "testing"
+
+func TestFormatLRCTime(t *testing.T) {
+	tests := map[float64]string{
+		0:       "00:00.00",
+		1.254:   "00:01.25",
+		61.999:  "01:02.00",
+		3723.45: "62:03.45",
+	}
+	for in, want := range tests {
+		if got := FormatLRCTime(in); got != want {
+			t.Errorf("FormatLRCTime(%g) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatLRC(t *testing.T) {
+	lrc := FormatLRC("Blue [Live]", "", []LRCLine{
+		{Time: 1, Text: "first\nline"},
+		{Time: 2.5},
+	})
+	want := "[ti:Blue [Live]\n[00:01.00]first line\n[00:02.50]\n"
+	if lrc != want {
+		t.Errorf("FormatLRC =\n%q\nwant\n%q", lrc, want)
+	}
+}
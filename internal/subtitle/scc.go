@@ -0,0 +1,186 @@
+package subtitle
+
+import (
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"scribe2srt/internal/timecode"
+)
+
+// SCCColumns is the width of a CEA-608 caption row.
+const SCCColumns = 32
+
+// CEA-608 caption channel 1 control codes.
+const (
+	sccMisc      = 0x14 // first byte of the miscellaneous control codes
+	sccRCL       = 0x20 // resume caption loading (pop-on)
+	sccEDM       = 0x2C // erase displayed memory
+	sccENM       = 0x2E // erase non-displayed memory
+	sccEOC       = 0x2F // end of caption: swap memories
+	sccTab       = 0x17 // first byte of the tab offsets 0x21-0x23
+	sccSpecial   = 0x11 // first byte of the special characters 0x30-0x3F
+	sccMaxRows   = 4
+	sccBottomRow = 15
+)
+
+// sccRate is the frame rate of SCC timecodes: one code word per frame.
+var sccRate = timecode.Rate2997DF
+
+// sccPACRows holds the two preamble address code bytes for column 0 of each
+// row, 1 to 15.
+var sccPACRows = [16][2]byte{
+	{},
+	{0x11, 0x40}, {0x11, 0x60}, {0x12, 0x40}, {0x12, 0x60},
+	{0x15, 0x40}, {0x15, 0x60}, {0x16, 0x40}, {0x16, 0x60},
+	{0x17, 0x40}, {0x17, 0x60}, {0x10, 0x40}, {0x13, 0x40},
+	{0x13, 0x60}, {0x14, 0x40}, {0x14, 0x60},
+}
+
+// sccBasic holds the characters of the basic North American set that are
+// not at their ASCII positions. The ASCII characters at those positions,
+// *\^_`{|}~, cannot be written.
+var sccBasic = map[rune]byte{
+	'\u00e1': 0x2A, '\u00e9': 0x5C, '\u00ed': 0x5E, '\u00f3': 0x5F, '\u00fa': 0x60, // áéíóú
+	'\u00e7': 0x7B, '\u00f7': 0x7C, '\u00d1': 0x7D, '\u00f1': 0x7E, '\u2588': 0x7F, // ç÷Ññ█
+}
+
+// sccSpecials holds the second byte of the special North American
+// characters. The no-break space is written as the transparent space.
+var sccSpecials = map[rune]byte{
+	'\u00ae': 0x30, '\u00b0': 0x31, '\u00bd': 0x32, '\u00bf': 0x33, '\u2122': 0x34, '\u00a2': 0x35, '\u00a3': 0x36, '\u266a': 0x37, // ®°½¿™¢£♪
+	'\u00e0': 0x38, '\u00a0': 0x39, '\u00e8': 0x3A, '\u00e2': 0x3B, '\u00ea': 0x3C, '\u00ee': 0x3D, '\u00f4': 0x3E, '\u00fb': 0x3F, // à, no-break space, èâêîôû
+}
+
+// FormatSCC writes cues as Scenarist SCC pop-on captions on channel CC1,
+// timed in 29.97 fps drop-frame timecode. Each caption is loaded into
+// non-displayed memory so that its End of Caption code falls on the cue
+// start, and erased at the cue end unless the next caption is already
+// loading then; its End of Caption replaces it instead. Lines are centred
+// on the bottom rows. Captions with more than four lines, lines wider than
+// 32 columns or characters outside the basic and special North American
+// sets are reported as errors.
+func FormatSCC(cues []Cue) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Scenarist_SCC V1.0\n")
+	block := func(frame int64, words []string) {
+		fmt.Fprintf(&sb, "\n%s\t%s\n", sccRate.FormatSMPTE(frame), strings.Join(words, " "))
+	}
+	erase := sccControl(sccMisc, sccEDM)
+
+	var free int64 // first frame not taken by an earlier code word
+	shownUntil := int64(-1)
+	for i, c := range cues {
+		words, err := sccCaption(c.Text)
+		if err != nil {
+			return "", fmt.Errorf("caption %d at %s: %w", i+1, FormatSRTTime(c.Start), err)
+		}
+		// The first of the two End of Caption words takes effect.
+		load := max(sccRate.ToFrames(c.Start)-int64(len(words)-2), free)
+		if shownUntil >= 0 {
+			if at := max(shownUntil, free); at+int64(len(erase)) <= load {
+				block(at, erase)
+				free = at + int64(len(erase))
+			}
+		}
+		block(load, words)
+		free = load + int64(len(words))
+		shownUntil = sccRate.ToFrames(c.End)
+	}
+	if shownUntil >= 0 {
+		block(max(shownUntil, free), erase)
+	}
+	return sb.String(), nil
+}
+
+// sccCaption returns the code words that load text as a pop-on caption and
+// display it.
+func sccCaption(text string) ([]string, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	if len(lines) > sccMaxRows {
+		return nil, fmt.Errorf("%d lines; pop-on captions hold at most %d", len(lines), sccMaxRows)
+	}
+
+	words := append(sccControl(sccMisc, sccRCL), sccControl(sccMisc, sccENM)...)
+	for i, line := range lines {
+		columns := []rune(line)
+		if len(columns) > SCCColumns {
+			return nil, fmt.Errorf("line %q is %d columns wide; rows hold %d", line, len(columns), SCCColumns)
+		}
+		// Centre the line: the preamble sets the indent in steps of four
+		// columns, a tab offset moves on the remaining one to three.
+		indent := (SCCColumns - len(columns)) / 2
+		pac := sccPACRows[sccBottomRow-len(lines)+1+i]
+		words = append(words, sccControl(pac[0], pac[1]+0x10+byte(indent/4)*2)...)
+		if indent%4 > 0 {
+			words = append(words, sccControl(sccTab, 0x20+byte(indent%4))...)
+		}
+
+		// Characters are sent two to a word; a lone one is padded with a
+		// null.
+		pending := -1
+		flush := func() {
+			if pending >= 0 {
+				words = append(words, sccWord(byte(pending), 0))
+				pending = -1
+			}
+		}
+		for _, r := range columns {
+			if b, ok := sccBasicByte(r); ok {
+				if pending < 0 {
+					pending = int(b)
+				} else {
+					words = append(words, sccWord(byte(pending), b))
+					pending = -1
+				}
+				continue
+			}
+			s, ok := sccSpecials[r]
+			if !ok {
+				return nil, fmt.Errorf("%q (U+%04X) is not in the CEA-608 basic North American character set", r, r)
+			}
+			// Special characters are control code pairs and start a word.
+			flush()
+			words = append(words, sccControl(sccSpecial, s)...)
+		}
+		flush()
+	}
+	return append(words, sccControl(sccMisc, sccEOC)...), nil
+}
+
+// sccBasicByte returns the basic character set byte of r.
+func sccBasicByte(r rune) (byte, bool) {
+	if b, ok := sccBasic[r]; ok {
+		return b, true
+	}
+	switch r {
+	case '*', '\\', '^', '_', '`', '{', '|', '}', '~':
+		return 0, false
+	}
+	if r >= 0x20 && r < 0x7F {
+		return byte(r), true
+	}
+	return 0, false
+}
+
+// sccControl returns a control code, sent twice so decoders that miss one
+// still act on it. Decoders ignore the repeat.
+func sccControl(a, b byte) []string {
+	w := sccWord(a, b)
+	return []string{w, w}
+}
+
+// sccWord formats two bytes with odd parity as an SCC code word.
+func sccWord(a, b byte) string {
+	return fmt.Sprintf("%02x%02x", sccParity(a), sccParity(b))
+}
+
+// sccParity sets the high bit of a 7-bit byte so that it has an odd number
+// of ones.
+func sccParity(b byte) byte {
+	b &= 0x7F
+	if bits.OnesCount8(b)%2 == 0 {
+		b |= 0x80
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSCCParity(t *testing.T) {
+	tests := map[byte]byte{0x14: 0x94, 0x20: 0x20, 0x2C: 0x2C, 0x2E: 0xAE, 0x00: 0x80, 0x48: 0xC8, 0x7F: 0x7F}
+	for in, want := range tests {
+		if got := sccParity(in); got != want {
+			t.Errorf("sccParity(%#02x) = %#02x, want %#02x", in, got, want)
+		}
+	}
+}
+
+func TestFormatSCC(t *testing.T) {
+	scc, err := FormatSCC([]Cue{{Start: 2, End: 4, Text: "Hi!"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Resume caption loading, erase non-displayed memory, row 15 indent 12
+	// plus tab offset 2, "Hi!" padded with a null, end of caption; the
+	// load starts ten frames early so that EOC falls on frame 60.
+	want := "Scenarist_SCC V1.0\n" +
+		"\n00:00:01;20\t9420 9420 94ae 94ae 9476 9476 97a2 97a2 c8e9 a180 942f 942f\n" +
+		"\n00:00:04;00\t942c 942c\n"
+	if scc != want {
+		t.Errorf("SCC =\n%q\nwant\n%q", scc, want)
+	}
+}
+
+func TestFormatSCC_RowsAndSpecialCharacters(t *testing.T) {
+	scc, err := FormatSCC([]Cue{{Start: 10, End: 12, Text: "ab\n\u266a La caf\u00e9"}}) // ♪ La café
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"94d6 94d6 9723 9723 6162",                          // row 14, column 15: "ab"
+		"94f4 94f4 9723 9723 9137 9137 204c 6120 e361 e6dc", // row 15, column 11: ♪, " L", "a ", "ca", "fé"
+	} {
+		if !strings.Contains(scc, s) {
+			t.Errorf("SCC lacks %q:\n%s", s, scc)
+		}
+	}
+}
+
+func TestFormatSCC_EraseBetweenCaptions(t *testing.T) {
+	// A gap: the first caption is erased at its end.
+	scc, err := FormatSCC([]Cue{{Start: 1, End: 2, Text: "one"}, {Start: 5, End: 6, Text: "two"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(scc, "942c 942c"); n != 2 {
+		t.Errorf("%d erase blocks, want 2:\n%s", n, scc)
+	}
+	if !strings.Contains(scc, "\n00:00:02;00\t942c 942c\n") {
+		t.Errorf("first caption not erased at 2s:\n%s", scc)
+	}
+
+	// Back to back: the second caption's EOC replaces the first.
+	scc, err = FormatSCC([]Cue{{Start: 1, End: 2, Text: "one"}, {Start: 2.1, End: 3, Text: "two"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(scc, "942c 942c"); n != 1 {
+		t.Errorf("%d erase blocks, want only the last:\n%s", n, scc)
+	}
+}
+
+func TestFormatSCC_DropFrameTimecode(t *testing.T) {
+	scc, err := FormatSCC([]Cue{{Start: 590, End: 600, Text: "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 600 s is frame 17982, labelled 00:10:00;00 in drop-frame.
+	if !strings.HasSuffix(scc, "\n00:10:00;00\t942c 942c\n") {
+		t.Errorf("SCC =\n%s", scc)
+	}
+}
+
+func TestFormatSCC_Unsupported(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"na\u00efve", "U+00EF"}, // naïve
+		{"a ~ b", "U+007E"},
+		{strings.Repeat("x", 33), "33 columns"},
+		{"1\n2\n3\n4\n5", "at most 4"},
+	}
+	for _, tt := range tests {
+		_, err := FormatSCC([]Cue{{Start: 0, End: 1, Text: "fine"}, {Start: 3, End: 4, Text: tt.text}})
+		if err == nil || !strings.Contains(err.Error(), "caption 2 at 00:00:03,000") || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("FormatSCC(%q) error = %v, want %q", tt.text, err, tt.want)
+		}
+	}
+}
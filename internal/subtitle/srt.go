@@ -0,0 +1,138 @@
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadSRT reads an SRT file.
+func LoadSRT(path string) ([]Cue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open subtitles: %w", err)
+	}
+	defer f.Close()
+
+	cues, err := ParseSRT(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cues, nil
+}
+
+// ParseSRT parses SRT content. Cue numbers are optional and ignored, both
+// "," and "." are accepted before the milliseconds, and anything after the
+// end time (position hints) is ignored.
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var cues []Cue
+	var current *Cue
+	var text []string
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(text, "\n")
+			cues = append(cues, *current)
+		}
+		current, text = nil, nil
+	}
+
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimRight(sc.Text(), "\r")
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff") // byte order mark
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case current == nil && strings.Contains(line, "-->"):
+			start, end, err := parseSRTTiming(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			current = &Cue{Start: start, End: end}
+		case current == nil:
+			// A cue number, or stray text between cues.
+			if _, err := strconv.Atoi(strings.TrimSpace(line)); err != nil {
+				return nil, fmt.Errorf("line %d: expected a cue number or timing, got %q", lineNo, line)
+			}
+		default:
+			text = append(text, line)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return cues, nil
+}
+
+// parseSRTTiming parses "HH:MM:SS,mmm --> HH:MM:SS,mmm".
+func parseSRTTiming(line string) (start, end float64, err error) {
+	from, to, _ := strings.Cut(line, "-->")
+	start, err = parseSRTTime(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("missing end time")
+	}
+	end, err = parseSRTTime(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("end time %s is before start time", fields[0])
+	}
+	return start, end, nil
+}
+
+// parseSRTTime parses HH:MM:SS,mmm into seconds.
+func parseSRTTime(s string) (float64, error) {
+	hms, frac, _ := strings.Cut(strings.Replace(s, ".", ",", 1), ",")
+	parts := strings.Split(hms, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	var total float64
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		total = total*60 + float64(n)
+	}
+	if frac != "" {
+		frac = frac[:min(len(frac), 3)]
+		ms, err := strconv.Atoi(frac)
+		if err != nil || ms < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		for i := len(frac); i < 3; i++ {
+			ms *= 10
+		}
+		total += float64(ms) / 1000
+	}
+	return total, nil
+}
+
+// FormatSRT writes cues as SRT content, numbered from 1.
+func FormatSRT(cues []Cue) string {
+	var sb strings.Builder
+	for i, c := range cues {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n", i+1, FormatSRTTime(c.Start), FormatSRTTime(c.End), c.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSRT(t *testing.T) {
+	input := "\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\nHello\r\nworld\r\n\r\n" +
+		"2\n00:00:03.2 --> 00:00:04,000 X1:100\nSecond\n"
+	cues, err := ParseSRT(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cues) != 2 {
+		t.Fatalf("got %d cues, want 2", len(cues))
+	}
+	if cues[0].Start != 1 || cues[0].End != 2.5 || cues[0].Text != "Hello\nworld" {
+		t.Errorf("cue 1 = %+v", cues[0])
+	}
+	if cues[1].Start != 3.2 || cues[1].End != 4 || cues[1].Text != "Second" {
+		t.Errorf("cue 2 = %+v", cues[1])
+	}
+}
+
+func TestParseSRT_Errors(t *testing.T) {
+	for _, input := range []string{
+		"1\n00:01,000 --> 00:00:02,000\ntext\n",
+		"1\n00:00:02,000 --> 00:00:01,000\ntext\n",
+		"not a number\n",
+	} {
+		if _, err := ParseSRT(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseSRT(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestFormatSRT_RoundTrip(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 1.5, Text: "One"},
+		{Start: 61.123, End: 3661.999, Text: "Two\nlines"},
+	}
+	srt := FormatSRT(cues)
+	if !strings.Contains(srt, "2\n00:01:01,123 --> 01:01:01,999\nTwo\nlines\n") {
+		t.Errorf("unexpected SRT:\n%s", srt)
+	}
+	parsed, err := ParseSRT(strings.NewReader(srt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 || parsed[1].Text != "Two\nlines" {
+		t.Errorf("round trip = %+v", parsed)
+	}
+}
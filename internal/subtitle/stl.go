@@ -0,0 +1,363 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"scribe2srt/internal/timecode"
+)
+
+// EBU STL (Tech 3264) block sizes and TTI text field codes.
+const (
+	stlGSISize       = 1024
+	stlTTISize       = 128
+	stlTextSize      = 112
+	stlLastBlock     = 0xFF
+	stlNewline       = 0x8A
+	stlUnused        = 0x8F
+	stlDoubleHeight  = 0x0D
+	stlStartBox      = 0x0B
+	stlJustifyCentre = 2
+	// stlBottomRow is the teletext row of the last subtitle line.
+	stlBottomRow = 22
+	stlRows      = 23
+)
+
+// STLCodePage is the DOS code page of the text fields of the GSI block.
+type STLCodePage int
+
+// ParseSTLCodePage parses an --stl-code-page value.
+func ParseSTLCodePage(s string) (STLCodePage, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err == nil {
+		if _, ok := codePageHigh[STLCodePage(n)]; ok {
+			return STLCodePage(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown STL code page %q (want 437, 850, 860, 863 or 865)", s)
+}
+
+func (cp STLCodePage) String() string { return strconv.Itoa(int(cp)) }
+
+// STLCharset is the character code table of the subtitle text.
+type STLCharset int
+
+const (
+	// STLLatin is the Latin alphabet of ISO 6937.
+	STLLatin STLCharset = iota
+	// STLCyrillic is ISO 8859-5.
+	STLCyrillic
+	// STLArabic is ISO 8859-6.
+	STLArabic
+	// STLGreek is ISO 8859-7.
+	STLGreek
+	// STLHebrew is ISO 8859-8.
+	STLHebrew
+)
+
+// ParseSTLCharset parses an --stl-charset value.
+func ParseSTLCharset(s string) (STLCharset, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "latin":
+		return STLLatin, nil
+	case "cyrillic":
+		return STLCyrillic, nil
+	case "arabic":
+		return STLArabic, nil
+	case "greek":
+		return STLGreek, nil
+	case "hebrew":
+		return STLHebrew, nil
+	}
+	return STLLatin, fmt.Errorf("unknown STL character table %q (want latin, cyrillic, arabic, greek or hebrew)", s)
+}
+
+func (cs STLCharset) String() string {
+	switch cs {
+	case STLCyrillic:
+		return "cyrillic"
+	case STLArabic:
+		return "arabic"
+	case STLGreek:
+		return "greek"
+	case STLHebrew:
+		return "hebrew"
+	default:
+		return "latin"
+	}
+}
+
+// STLDisplay is the display standard of an STL file.
+type STLDisplay int
+
+const (
+	// STLTeletext writes Level-1 teletext subtitles: double-height rows in
+	// a boxed background.
+	STLTeletext STLDisplay = iota
+	// STLOpen writes open subtitles, burnt in or rendered by the player.
+	STLOpen
+)
+
+// ParseSTLDisplay parses an --stl-display value.
+func ParseSTLDisplay(s string) (STLDisplay, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "teletext":
+		return STLTeletext, nil
+	case "open":
+		return STLOpen, nil
+	}
+	return STLTeletext, fmt.Errorf("unknown STL display %q (want teletext or open)", s)
+}
+
+func (d STLDisplay) String() string {
+	if d == STLOpen {
+		return "open"
+	}
+	return "teletext"
+}
+
+// RowChars returns the usual row width of the display: 37 characters for
+// teletext, whose 40-column rows start with the double-height and box
+// codes, and 40 for open subtitles.
+func (d STLDisplay) RowChars() int {
+	if d == STLOpen {
+		return 40
+	}
+	return 37
+}
+
+// STLOptions configures FormatSTL. Text fields longer than their GSI field
+// are cut.
+type STLOptions struct {
+	// CodePage encodes the GSI text fields; 0 means 850.
+	CodePage STLCodePage
+	Charset  STLCharset
+	Display  STLDisplay
+	// FrameRate must be 25 or 30 (29.97) fps; zero means 25.
+	FrameRate timecode.Rate
+	// RowChars is the longest row declared; 0 means Display.RowChars.
+	RowChars int
+	// Language is an ISO 639 code, written as the EBU language code.
+	Language     string
+	Title        string
+	EpisodeTitle string
+	Publisher    string
+	// Country is the ISO 3166 alpha-3 code of the country of origin.
+	Country string
+	Created time.Time
+}
+
+// FormatSTL writes cues as an EBU STL file: a GSI block followed by one or
+// more TTI blocks per cue. It fails when a character has no encoding in
+// the code page or character table chosen.
+func FormatSTL(opts STLOptions, cues []Cue) ([]byte, error) {
+	if opts.CodePage == 0 {
+		opts.CodePage = 850
+	}
+	if _, ok := codePageHigh[opts.CodePage]; !ok {
+		return nil, fmt.Errorf("unsupported STL code page %d", opts.CodePage)
+	}
+	rate := opts.FrameRate
+	if rate.IsZero() {
+		rate = timecode.Rate25
+	}
+	if n := rate.Nominal(); n != 25 && n != 30 {
+		return nil, fmt.Errorf("EBU STL supports 25 and 30 fps, not %s", rate)
+	}
+	if opts.RowChars <= 0 {
+		opts.RowChars = opts.Display.RowChars()
+	}
+
+	var ttis bytes.Buffer
+	blocks := 0
+	for i, c := range cues {
+		text, err := stlText(c.Text, opts)
+		if err != nil {
+			return nil, fmt.Errorf("subtitle %d at %s: %w", i+1, FormatSRTTime(c.Start), err)
+		}
+		lines := strings.Count(c.Text, "\n") + 1
+		blocks += writeTTI(&ttis, i+1, stlTimecode(rate, c.Start), stlTimecode(rate, c.End), stlRow(lines, opts.Display), text)
+	}
+
+	gsi, err := stlGSI(opts, rate, cues, blocks)
+	if err != nil {
+		return nil, err
+	}
+	return append(gsi, ttis.Bytes()...), nil
+}
+
+// stlGSI builds the General Subtitle Information block.
+func stlGSI(opts STLOptions, rate timecode.Rate, cues []Cue, blocks int) ([]byte, error) {
+	gsi := bytes.Repeat([]byte{' '}, stlGSISize)
+	put := func(offset, size int, s string) {
+		copy(gsi[offset:offset+size], s)
+	}
+	text := func(offset, size int, field, s string) error {
+		var b []byte
+		for _, r := range s {
+			enc, ok := encodeCodePage(opts.CodePage, r)
+			if !ok {
+				return fmt.Errorf("%s: %q (U+%04X) cannot be encoded in code page %d", field, r, r, opts.CodePage)
+			}
+			b = append(b, enc...)
+		}
+		put(offset, size, string(b[:min(len(b), size)]))
+		return nil
+	}
+
+	put(0, 3, fmt.Sprintf("%03d", int(opts.CodePage)))
+	put(3, 8, fmt.Sprintf("STL%d.01", rate.Nominal()))
+	if opts.Display == STLOpen {
+		put(11, 1, "0")
+	} else {
+		put(11, 1, "1")
+	}
+	put(12, 2, fmt.Sprintf("%02d", int(opts.Charset)))
+	put(14, 2, stlLanguageCode(opts.Language))
+	for _, f := range []struct {
+		offset, size int
+		field, value string
+	}{
+		{16, 32, "programme title", opts.Title},
+		{48, 32, "episode title", opts.EpisodeTitle},
+		{274, 3, "country", strings.ToUpper(opts.Country)},
+		{277, 32, "publisher", opts.Publisher},
+	} {
+		if err := text(f.offset, f.size, f.field, f.value); err != nil {
+			return nil, err
+		}
+	}
+	date := opts.Created.Format("060102")
+	put(224, 6, date)
+	put(230, 6, date)
+	put(236, 2, "00")
+	put(238, 5, fmt.Sprintf("%05d", blocks))
+	put(243, 5, fmt.Sprintf("%05d", len(cues)))
+	put(248, 3, "001")
+	put(251, 2, fmt.Sprintf("%02d", opts.RowChars))
+	put(253, 2, strconv.Itoa(stlRows))
+	put(255, 1, "1")
+	put(256, 8, "00000000")
+	first := "00000000"
+	if len(cues) > 0 {
+		hh, mm, ss, ff := rate.Components(rate.ToFrames(cues[0].Start))
+		first = fmt.Sprintf("%02d%02d%02d%02d", hh, mm, ss, ff)
+	}
+	put(264, 8, first)
+	put(272, 1, "1")
+	put(273, 1, "1")
+	return gsi, nil
+}
+
+// stlText encodes the lines of a cue into text field codes. Teletext rows
+// start double-height in a box, and each takes two rows.
+func stlText(text string, opts STLOptions) ([][]byte, error) {
+	var codes [][]byte
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+		if i > 0 {
+			codes = append(codes, []byte{stlNewline})
+			if opts.Display == STLTeletext {
+				codes = append(codes, []byte{stlNewline})
+			}
+		}
+		if opts.Display == STLTeletext {
+			codes = append(codes, []byte{stlDoubleHeight}, []byte{stlStartBox}, []byte{stlStartBox})
+		}
+		for _, r := range line {
+			if r == utf8.RuneError {
+				return nil, fmt.Errorf("invalid UTF-8 in %q", line)
+			}
+			enc, ok := encodeCharset(opts.Charset, r)
+			if !ok {
+				return nil, fmt.Errorf("%q (U+%04X) cannot be encoded in the %s character table", r, r, opts.Charset)
+			}
+			codes = append(codes, enc)
+		}
+	}
+	return codes, nil
+}
+
+// writeTTI writes the Text and Timing Information blocks of one subtitle
+// and returns how many were written. Text longer than one block continues
+// in extension blocks, never splitting the bytes of a character.
+func writeTTI(w *bytes.Buffer, number int, in, out [4]byte, row byte, codes [][]byte) int {
+	var fields [][]byte
+	field := make([]byte, 0, stlTextSize)
+	for _, c := range codes {
+		if len(field)+len(c) > stlTextSize {
+			fields = append(fields, field)
+			field = make([]byte, 0, stlTextSize)
+		}
+		field = append(field, c...)
+	}
+	fields = append(fields, field)
+
+	for i, f := range fields {
+		block := make([]byte, stlTTISize)
+		block[0] = 0 // subtitle group
+		block[1], block[2] = byte(number), byte(number>>8)
+		block[3] = byte(i)
+		if i == len(fields)-1 {
+			block[3] = stlLastBlock
+		}
+		block[4] = 0 // not cumulative
+		copy(block[5:9], in[:])
+		copy(block[9:13], out[:])
+		block[13] = row
+		block[14] = stlJustifyCentre
+		block[15] = 0 // subtitle data, not a comment
+		copy(block[16:], f)
+		for j := 16 + len(f); j < stlTTISize; j++ {
+			block[j] = stlUnused
+		}
+		w.Write(block)
+	}
+	return len(fields)
+}
+
+// stlTimecode returns the hours, minutes, seconds and frames of seconds.
+func stlTimecode(rate timecode.Rate, seconds float64) [4]byte {
+	hh, mm, ss, ff := rate.Components(rate.ToFrames(seconds))
+	return [4]byte{byte(hh), byte(mm), byte(ss), byte(ff)}
+}
+
+// stlRow returns the vertical position of the first of lines so that the
+// last sits on the bottom row.
+func stlRow(lines int, display STLDisplay) byte {
+	step := 1
+	if display == STLTeletext {
+		step = 2
+	}
+	return byte(max(stlBottomRow-step*(lines-1), 1))
+}
+
+// stlLanguageCodes maps ISO 639-1 codes to the language codes of EBU Tech
+// 3264 Appendix 3.
+var stlLanguageCodes = map[string]string{
+	"sq": "01", "br": "02", "ca": "03", "hr": "04", "cy": "05", "cs": "06", "da": "07", "de": "08",
+	"en": "09", "es": "0A", "eo": "0B", "et": "0C", "eu": "0D", "fo": "0E", "fr": "0F", "fy": "10",
+	"ga": "11", "gd": "12", "gl": "13", "is": "14", "it": "15", "se": "16", "la": "17", "lv": "18",
+	"lb": "19", "lt": "1A", "hu": "1B", "mt": "1C", "nl": "1D", "no": "1E", "oc": "1F", "pl": "20",
+	"pt": "21", "ro": "22", "rm": "23", "sr": "24", "sk": "25", "sl": "26", "fi": "27", "sv": "28",
+	"tr": "29",
+	"zu": "45", "vi": "46", "uz": "47", "ur": "48", "uk": "49", "th": "4A", "te": "4B", "tt": "4C",
+	"ta": "4D", "tg": "4E", "sw": "4F", "so": "51", "si": "52", "sn": "53", "ru": "56", "qu": "57",
+	"ps": "58", "pa": "59", "fa": "5A", "or": "5C", "ne": "5D", "nr": "5E", "mr": "5F", "ms": "61",
+	"mg": "62", "mk": "63", "lo": "64", "ko": "65", "km": "66", "kk": "67", "kn": "68", "ja": "69",
+	"id": "6A", "hi": "6B", "he": "6C", "ha": "6D", "gu": "6F", "el": "70", "ka": "71", "ff": "72",
+	"zh": "75", "my": "76", "bg": "77", "bn": "78", "be": "79", "bm": "7A", "az": "7B", "as": "7C",
+	"hy": "7D", "ar": "7E", "am": "7F",
+}
+
+// stlLanguageCode returns the EBU code of an ISO 639 code, or "00" when
+// the language has none.
+func stlLanguageCode(code string) string {
+	if c, ok := stlLanguageCodes[strings.ToLower(code)]; ok {
+		return c
+	}
+	return "00"
+}
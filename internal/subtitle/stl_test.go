@@ -0,0 +1,201 @@
+package subtitle
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"scribe2srt/internal/timecode"
+)
+
+func stlOptions() STLOptions {
+	return STLOptions{
+		Language:  "en",
+		Title:     "Fish & Chips",
+		Publisher: "Example TV",
+		Country:   "gbr",
+		Created:   time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestFormatSTL_GSI(t *testing.T) {
+	cues := []Cue{{Start: 10, End: 12, Text: "Hello"}, {Start: 13, End: 14, Text: "Bye"}}
+	data, err := FormatSTL(stlOptions(), cues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != stlGSISize+2*stlTTISize {
+		t.Fatalf("length = %d, want %d", len(data), stlGSISize+2*stlTTISize)
+	}
+
+	gsi := string(data[:stlGSISize])
+	for _, f := range []struct {
+		name         string
+		offset, size int
+		want         string
+	}{
+		{"CPN", 0, 3, "850"},
+		{"DFC", 3, 8, "STL25.01"},
+		{"DSC", 11, 1, "1"},
+		{"CCT", 12, 2, "00"},
+		{"LC", 14, 2, "09"},
+		{"OPT", 16, 32, "Fish & Chips" + strings.Repeat(" ", 20)},
+		{"CD", 224, 6, "260309"},
+		{"TNB", 238, 5, "00002"},
+		{"TNS", 243, 5, "00002"},
+		{"TNG", 248, 3, "001"},
+		{"MNC", 251, 2, "37"},
+		{"MNR", 253, 2, "23"},
+		{"TCS", 255, 1, "1"},
+		{"TCF", 264, 8, "00001000"},
+		{"CO", 274, 3, "GBR"},
+		{"PUB", 277, 10, "Example TV"},
+	} {
+		if got := gsi[f.offset : f.offset+f.size]; got != f.want {
+			t.Errorf("%s = %q, want %q", f.name, got, f.want)
+		}
+	}
+}
+
+func TestFormatSTL_TTI(t *testing.T) {
+	cues := []Cue{{Start: 3661.48, End: 3663, Text: "Caf\u00e9 for two\n\u00a3 5, danke sch\u00f6n"}} // Café, £, schön
+	data, err := FormatSTL(stlOptions(), cues)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tti := data[stlGSISize:]
+
+	header := []byte{
+		0,    // subtitle group
+		1, 0, // subtitle number
+		0xFF,        // last extension block
+		0,           // not cumulative
+		1, 1, 1, 12, // in: 01:01:01:12
+		1, 1, 3, 0, // out: 01:01:03:00
+		20, // two double-height rows ending on row 22
+		2,  // centred
+		0,  // not a comment
+	}
+	if !bytes.Equal(tti[:16], header) {
+		t.Errorf("TTI header = % X, want % X", tti[:16], header)
+	}
+
+	text := []byte{0x0D, 0x0B, 0x0B, 'C', 'a', 'f', 0xC2, 'e', ' ', 'f', 'o', 'r', ' ', 't', 'w', 'o', 0x8A, 0x8A,
+		0x0D, 0x0B, 0x0B, 0xA3, ' ', '5', ',', ' ', 'd', 'a', 'n', 'k', 'e', ' ', 's', 'c', 'h', 0xC8, 'o', 'n'}
+	if !bytes.Equal(tti[16:16+len(text)], text) {
+		t.Errorf("text field = % X\nwant % X", tti[16:16+len(text)], text)
+	}
+	if rest := tti[16+len(text) : stlTTISize]; !bytes.Equal(rest, bytes.Repeat([]byte{0x8F}, len(rest))) {
+		t.Errorf("unused bytes = % X", rest)
+	}
+}
+
+func TestFormatSTL_OpenSubtitles(t *testing.T) {
+	opts := stlOptions()
+	opts.Display = STLOpen
+	data, err := FormatSTL(opts, []Cue{{Start: 1, End: 2, Text: "a\nb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[11] != '0' || string(data[251:253]) != "40" {
+		t.Errorf("DSC = %q, MNC = %q; want 0 and 40", data[11], data[251:253])
+	}
+	tti := data[stlGSISize:]
+	if tti[13] != 21 || !bytes.Equal(tti[16:20], []byte{'a', 0x8A, 'b', 0x8F}) {
+		t.Errorf("row %d, text % X", tti[13], tti[16:20])
+	}
+}
+
+func TestFormatSTL_ExtensionBlocks(t *testing.T) {
+	long := "a" + strings.Repeat("\u00e9", 40) + "\n" + strings.Repeat("\u00e8", 40) // é, è: two bytes each
+	data, err := FormatSTL(stlOptions(), []Cue{{Start: 1, End: 2, Text: long}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != stlGSISize+2*stlTTISize {
+		t.Fatalf("length = %d, want two TTI blocks", len(data))
+	}
+	if string(data[238:243]) != "00002" || string(data[243:248]) != "00001" {
+		t.Errorf("TNB = %q, TNS = %q", data[238:243], data[243:248])
+	}
+	first, second := data[stlGSISize:stlGSISize+stlTTISize], data[stlGSISize+stlTTISize:]
+	if first[3] != 0 || second[3] != 0xFF || second[1] != 1 {
+		t.Errorf("extension block numbers %d, %d", first[3], second[3])
+	}
+	// The first block ends before a character would be split.
+	if first[stlTTISize-1] != 0x8F || first[stlTTISize-2] != 'e' {
+		t.Errorf("first block ends % X", first[stlTTISize-4:])
+	}
+}
+
+func TestFormatSTL_Cyrillic(t *testing.T) {
+	opts := stlOptions()
+	opts.Charset = STLCyrillic
+	data, err := FormatSTL(opts, []Cue{{Start: 1, End: 2, Text: "\u041f\u0440\u0438\u0432\u0435\u0442"}}) // Привет
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[12:14]) != "01" {
+		t.Errorf("CCT = %q", data[12:14])
+	}
+	want := []byte{0x0D, 0x0B, 0x0B, 0xBF, 0xE0, 0xD8, 0xD2, 0xD5, 0xE2}
+	if got := data[stlGSISize+16 : stlGSISize+16+len(want)]; !bytes.Equal(got, want) {
+		t.Errorf("text = % X, want % X", got, want)
+	}
+}
+
+func TestFormatSTL_UnencodableCharacters(t *testing.T) {
+	cues := []Cue{{Start: 1, End: 2, Text: "fine"}, {Start: 5, End: 6, Text: "\u0436"}} // ж
+	_, err := FormatSTL(stlOptions(), cues)
+	if err == nil || !strings.Contains(err.Error(), "subtitle 2 at 00:00:05,000") || !strings.Contains(err.Error(), "U+0436") {
+		t.Errorf("err = %v", err)
+	}
+
+	opts := stlOptions()
+	opts.Title = "\u6771\u4eac" // 東京
+	_, err = FormatSTL(opts, cues[:1])
+	if err == nil || !strings.Contains(err.Error(), "programme title") || !strings.Contains(err.Error(), "code page 850") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestFormatSTL_FrameRates(t *testing.T) {
+	opts := stlOptions()
+	opts.FrameRate = timecode.Rate2997DF
+	// 600 s at 29.97 drop-frame is frame 17982, labelled 00:10:00;00.
+	data, err := FormatSTL(opts, []Cue{{Start: 600, End: 601, Text: "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data[3:11]) != "STL30.01" {
+		t.Errorf("DFC = %q", data[3:11])
+	}
+	if in := data[stlGSISize+5 : stlGSISize+9]; !bytes.Equal(in, []byte{0, 10, 0, 0}) {
+		t.Errorf("TCI = %v", in)
+	}
+
+	opts.FrameRate = timecode.Rate24
+	if _, err := FormatSTL(opts, nil); err == nil {
+		t.Error("24 fps accepted")
+	}
+}
+
+func TestParseSTLValues(t *testing.T) {
+	for _, want := range []STLCharset{STLLatin, STLCyrillic, STLArabic, STLGreek, STLHebrew} {
+		if got, err := ParseSTLCharset(want.String()); err != nil || got != want {
+			t.Errorf("ParseSTLCharset(%q) = %v, %v", want.String(), got, err)
+		}
+	}
+	for _, want := range []STLDisplay{STLTeletext, STLOpen} {
+		if got, err := ParseSTLDisplay(want.String()); err != nil || got != want {
+			t.Errorf("ParseSTLDisplay(%q) = %v, %v", want.String(), got, err)
+		}
+	}
+	if cp, err := ParseSTLCodePage("865"); err != nil || cp != 865 {
+		t.Errorf("ParseSTLCodePage(865) = %v, %v", cp, err)
+	}
+	if _, err := ParseSTLCodePage("1252"); err == nil {
+		t.Error("code page 1252 accepted")
+	}
+}
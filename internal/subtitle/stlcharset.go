@@ -0,0 +1,297 @@
+package subtitle
+
+import "sync"
+
+// EBU STL text is written in single-byte character sets: the GSI block in
+// a DOS code page, the subtitle text in one of the character code tables
+// of EBU Tech 3264 Appendix 2. Bytes 0x20-0x7E are ASCII in all of them.
+
+// codePageHigh holds the characters of bytes 0x80-0xFF of the GSI code
+// pages.
+var codePageHigh = map[STLCodePage]*[128]rune{
+	437: &cp437High,
+	850: &cp850High,
+	860: &cp860High,
+	863: &cp863High,
+	865: &cp865High,
+}
+
+// cp437High is code page 437 (United States).
+var cp437High = [128]rune{
+	0x00c7, 0x00fc, 0x00e9, 0x00e2, 0x00e4, 0x00e0, 0x00e5, 0x00e7,
+	0x00ea, 0x00eb, 0x00e8, 0x00ef, 0x00ee, 0x00ec, 0x00c4, 0x00c5,
+	0x00c9, 0x00e6, 0x00c6, 0x00f4, 0x00f6, 0x00f2, 0x00fb, 0x00f9,
+	0x00ff, 0x00d6, 0x00dc, 0x00a2, 0x00a3, 0x00a5, 0x20a7, 0x0192,
+	0x00e1, 0x00ed, 0x00f3, 0x00fa, 0x00f1, 0x00d1, 0x00aa, 0x00ba,
+	0x00bf, 0x2310, 0x00ac, 0x00bd, 0x00bc, 0x00a1, 0x00ab, 0x00bb,
+	0x2591, 0x2592, 0x2593, 0x2502, 0x2524, 0x2561, 0x2562, 0x2556,
+	0x2555, 0x2563, 0x2551, 0x2557, 0x255d, 0x255c, 0x255b, 0x2510,
+	0x2514, 0x2534, 0x252c, 0x251c, 0x2500, 0x253c, 0x255e, 0x255f,
+	0x255a, 0x2554, 0x2569, 0x2566, 0x2560, 0x2550, 0x256c, 0x2567,
+	0x2568, 0x2564, 0x2565, 0x2559, 0x2558, 0x2552, 0x2553, 0x256b,
+	0x256a, 0x2518, 0x250c, 0x2588, 0x2584, 0x258c, 0x2590, 0x2580,
+	0x03b1, 0x00df, 0x0393, 0x03c0, 0x03a3, 0x03c3, 0x00b5, 0x03c4,
+	0x03a6, 0x0398, 0x03a9, 0x03b4, 0x221e, 0x03c6, 0x03b5, 0x2229,
+	0x2261, 0x00b1, 0x2265, 0x2264, 0x2320, 0x2321, 0x00f7, 0x2248,
+	0x00b0, 0x2219, 0x00b7, 0x221a, 0x207f, 0x00b2, 0x25a0, 0x00a0,
+}
+
+// cp850High is code page 850 (Multilingual).
+var cp850High = [128]rune{
+	0x00c7, 0x00fc, 0x00e9, 0x00e2, 0x00e4, 0x00e0, 0x00e5, 0x00e7,
+	0x00ea, 0x00eb, 0x00e8, 0x00ef, 0x00ee, 0x00ec, 0x00c4, 0x00c5,
+	0x00c9, 0x00e6, 0x00c6, 0x00f4, 0x00f6, 0x00f2, 0x00fb, 0x00f9,
+	0x00ff, 0x00d6, 0x00dc, 0x00f8, 0x00a3, 0x00d8, 0x00d7, 0x0192,
+	0x00e1, 0x00ed, 0x00f3, 0x00fa, 0x00f1, 0x00d1, 0x00aa, 0x00ba,
+	0x00bf, 0x00ae, 0x00ac, 0x00bd, 0x00bc, 0x00a1, 0x00ab, 0x00bb,
+	0x2591, 0x2592, 0x2593, 0x2502, 0x2524, 0x00c1, 0x00c2, 0x00c0,
+	0x00a9, 0x2563, 0x2551, 0x2557, 0x255d, 0x00a2, 0x00a5, 0x2510,
+	0x2514, 0x2534, 0x252c, 0x251c, 0x2500, 0x253c, 0x00e3, 0x00c3,
+	0x255a, 0x2554, 0x2569, 0x2566, 0x2560, 0x2550, 0x256c, 0x00a4,
+	0x00f0, 0x00d0, 0x00ca, 0x00cb, 0x00c8, 0x0131, 0x00cd, 0x00ce,
+	0x00cf, 0x2518, 0x250c, 0x2588, 0x2584, 0x00a6, 0x00cc, 0x2580,
+	0x00d3, 0x00df, 0x00d4, 0x00d2, 0x00f5, 0x00d5, 0x00b5, 0x00fe,
+	0x00de, 0x00da, 0x00db, 0x00d9, 0x00fd, 0x00dd, 0x00af, 0x00b4,
+	0x00ad, 0x00b1, 0x2017, 0x00be, 0x00b6, 0x00a7, 0x00f7, 0x00b8,
+	0x00b0, 0x00a8, 0x00b7, 0x00b9, 0x00b3, 0x00b2, 0x25a0, 0x00a0,
+}
+
+// cp860High is code page 860 (Portugal).
+var cp860High = [128]rune{
+	0x00c7, 0x00fc, 0x00e9, 0x00e2, 0x00e3, 0x00e0, 0x00c1, 0x00e7,
+	0x00ea, 0x00ca, 0x00e8, 0x00cd, 0x00d4, 0x00ec, 0x00c3, 0x00c2,
+	0x00c9, 0x00c0, 0x00c8, 0x00f4, 0x00f5, 0x00f2, 0x00da, 0x00f9,
+	0x00cc, 0x00d5, 0x00dc, 0x00a2, 0x00a3, 0x00d9, 0x20a7, 0x00d3,
+	0x00e1, 0x00ed, 0x00f3, 0x00fa, 0x00f1, 0x00d1, 0x00aa, 0x00ba,
+	0x00bf, 0x00d2, 0x00ac, 0x00bd, 0x00bc, 0x00a1, 0x00ab, 0x00bb,
+	0x2591, 0x2592, 0x2593, 0x2502, 0x2524, 0x2561, 0x2562, 0x2556,
+	0x2555, 0x2563, 0x2551, 0x2557, 0x255d, 0x255c, 0x255b, 0x2510,
+	0x2514, 0x2534, 0x252c, 0x251c, 0x2500, 0x253c, 0x255e, 0x255f,
+	0x255a, 0x2554, 0x2569, 0x2566, 0x2560, 0x2550, 0x256c, 0x2567,
+	0x2568, 0x2564, 0x2565, 0x2559, 0x2558, 0x2552, 0x2553, 0x256b,
+	0x256a, 0x2518, 0x250c, 0x2588, 0x2584, 0x258c, 0x2590, 0x2580,
+	0x03b1, 0x00df, 0x0393, 0x03c0, 0x03a3, 0x03c3, 0x00b5, 0x03c4,
+	0x03a6, 0x0398, 0x03a9, 0x03b4, 0x221e, 0x03c6, 0x03b5, 0x2229,
+	0x2261, 0x00b1, 0x2265, 0x2264, 0x2320, 0x2321, 0x00f7, 0x2248,
+	0x00b0, 0x2219, 0x00b7, 0x221a, 0x207f, 0x00b2, 0x25a0, 0x00a0,
+}
+
+// cp863High is code page 863 (Canada-French).
+var cp863High = [128]rune{
+	0x00c7, 0x00fc, 0x00e9, 0x00e2, 0x00c2, 0x00e0, 0x00b6, 0x00e7,
+	0x00ea, 0x00eb, 0x00e8, 0x00ef, 0x00ee, 0x2017, 0x00c0, 0x00a7,
+	0x00c9, 0x00c8, 0x00ca, 0x00f4, 0x00cb, 0x00cf, 0x00fb, 0x00f9,
+	0x00a4, 0x00d4, 0x00dc, 0x00a2, 0x00a3, 0x00d9, 0x00db, 0x0192,
+	0x00a6, 0x00b4, 0x00f3, 0x00fa, 0x00a8, 0x00b8, 0x00b3, 0x00af,
+	0x00ce, 0x2310, 0x00ac, 0x00bd, 0x00bc, 0x00be, 0x00ab, 0x00bb,
+	0x2591, 0x2592, 0x2593, 0x2502, 0x2524, 0x2561, 0x2562, 0x2556,
+	0x2555, 0x2563, 0x2551, 0x2557, 0x255d, 0x255c, 0x255b, 0x2510,
+	0x2514, 0x2534, 0x252c, 0x251c, 0x2500, 0x253c, 0x255e, 0x255f,
+	0x255a, 0x2554, 0x2569, 0x2566, 0x2560, 0x2550, 0x256c, 0x2567,
+	0x2568, 0x2564, 0x2565, 0x2559, 0x2558, 0x2552, 0x2553, 0x256b,
+	0x256a, 0x2518, 0x250c, 0x2588, 0x2584, 0x258c, 0x2590, 0x2580,
+	0x03b1, 0x00df, 0x0393, 0x03c0, 0x03a3, 0x03c3, 0x00b5, 0x03c4,
+	0x03a6, 0x0398, 0x03a9, 0x03b4, 0x221e, 0x03c6, 0x03b5, 0x2229,
+	0x2261, 0x00b1, 0x2265, 0x2264, 0x2320, 0x2321, 0x00f7, 0x2248,
+	0x00b0, 0x2219, 0x00b7, 0x221a, 0x207f, 0x00b2, 0x25a0, 0x00a0,
+}
+
+// cp865High is code page 865 (Nordic).
+var cp865High = [128]rune{
+	0x00c7, 0x00fc, 0x00e9, 0x00e2, 0x00e4, 0x00e0, 0x00e5, 0x00e7,
+	0x00ea, 0x00eb, 0x00e8, 0x00ef, 0x00ee, 0x00ec, 0x00c4, 0x00c5,
+	0x00c9, 0x00e6, 0x00c6, 0x00f4, 0x00f6, 0x00f2, 0x00fb, 0x00f9,
+	0x00ff, 0x00d6, 0x00dc, 0x00f8, 0x00a3, 0x00d8, 0x20a7, 0x0192,
+	0x00e1, 0x00ed, 0x00f3, 0x00fa, 0x00f1, 0x00d1, 0x00aa, 0x00ba,
+	0x00bf, 0x2310, 0x00ac, 0x00bd, 0x00bc, 0x00a1, 0x00ab, 0x00a4,
+	0x2591, 0x2592, 0x2593, 0x2502, 0x2524, 0x2561, 0x2562, 0x2556,
+	0x2555, 0x2563, 0x2551, 0x2557, 0x255d, 0x255c, 0x255b, 0x2510,
+	0x2514, 0x2534, 0x252c, 0x251c, 0x2500, 0x253c, 0x255e, 0x255f,
+	0x255a, 0x2554, 0x2569, 0x2566, 0x2560, 0x2550, 0x256c, 0x2567,
+	0x2568, 0x2564, 0x2565, 0x2559, 0x2558, 0x2552, 0x2553, 0x256b,
+	0x256a, 0x2518, 0x250c, 0x2588, 0x2584, 0x258c, 0x2590, 0x2580,
+	0x03b1, 0x00df, 0x0393, 0x03c0, 0x03a3, 0x03c3, 0x00b5, 0x03c4,
+	0x03a6, 0x0398, 0x03a9, 0x03b4, 0x221e, 0x03c6, 0x03b5, 0x2229,
+	0x2261, 0x00b1, 0x2265, 0x2264, 0x2320, 0x2321, 0x00f7, 0x2248,
+	0x00b0, 0x2219, 0x00b7, 0x221a, 0x207f, 0x00b2, 0x25a0, 0x00a0,
+}
+
+// iso8859High holds the characters of bytes 0xA0-0xFF of the non-Latin
+// character code tables, which are ISO 8859-5 to 8859-8. Zero marks an
+// unassigned byte.
+var iso8859High = map[STLCharset]*[96]rune{
+	STLCyrillic: &iso8859_5High,
+	STLArabic:   &iso8859_6High,
+	STLGreek:    &iso8859_7High,
+	STLHebrew:   &iso8859_8High,
+}
+
+// iso8859_5High is ISO 8859-5 (Latin/Cyrillic).
+var iso8859_5High = [96]rune{
+	0x00a0, 0x0401, 0x0402, 0x0403, 0x0404, 0x0405, 0x0406, 0x0407,
+	0x0408, 0x0409, 0x040a, 0x040b, 0x040c, 0x00ad, 0x040e, 0x040f,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041a, 0x041b, 0x041c, 0x041d, 0x041e, 0x041f,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042a, 0x042b, 0x042c, 0x042d, 0x042e, 0x042f,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043a, 0x043b, 0x043c, 0x043d, 0x043e, 0x043f,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044a, 0x044b, 0x044c, 0x044d, 0x044e, 0x044f,
+	0x2116, 0x0451, 0x0452, 0x0453, 0x0454, 0x0455, 0x0456, 0x0457,
+	0x0458, 0x0459, 0x045a, 0x045b, 0x045c, 0x00a7, 0x045e, 0x045f,
+}
+
+// iso8859_6High is ISO 8859-6 (Latin/Arabic).
+var iso8859_6High = [96]rune{
+	0x00a0, 0x0000, 0x0000, 0x0000, 0x00a4, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x060c, 0x00ad, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x061b, 0x0000, 0x0000, 0x0000, 0x061f,
+	0x0000, 0x0621, 0x0622, 0x0623, 0x0624, 0x0625, 0x0626, 0x0627,
+	0x0628, 0x0629, 0x062a, 0x062b, 0x062c, 0x062d, 0x062e, 0x062f,
+	0x0630, 0x0631, 0x0632, 0x0633, 0x0634, 0x0635, 0x0636, 0x0637,
+	0x0638, 0x0639, 0x063a, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0640, 0x0641, 0x0642, 0x0643, 0x0644, 0x0645, 0x0646, 0x0647,
+	0x0648, 0x0649, 0x064a, 0x064b, 0x064c, 0x064d, 0x064e, 0x064f,
+	0x0650, 0x0651, 0x0652, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+}
+
+// iso8859_7High is ISO 8859-7 (Latin/Greek).
+var iso8859_7High = [96]rune{
+	0x00a0, 0x2018, 0x2019, 0x00a3, 0x20ac, 0x20af, 0x00a6, 0x00a7,
+	0x00a8, 0x00a9, 0x037a, 0x00ab, 0x00ac, 0x00ad, 0x0000, 0x2015,
+	0x00b0, 0x00b1, 0x00b2, 0x00b3, 0x0384, 0x0385, 0x0386, 0x00b7,
+	0x0388, 0x0389, 0x038a, 0x00bb, 0x038c, 0x00bd, 0x038e, 0x038f,
+	0x0390, 0x0391, 0x0392, 0x0393, 0x0394, 0x0395, 0x0396, 0x0397,
+	0x0398, 0x0399, 0x039a, 0x039b, 0x039c, 0x039d, 0x039e, 0x039f,
+	0x03a0, 0x03a1, 0x0000, 0x03a3, 0x03a4, 0x03a5, 0x03a6, 0x03a7,
+	0x03a8, 0x03a9, 0x03aa, 0x03ab, 0x03ac, 0x03ad, 0x03ae, 0x03af,
+	0x03b0, 0x03b1, 0x03b2, 0x03b3, 0x03b4, 0x03b5, 0x03b6, 0x03b7,
+	0x03b8, 0x03b9, 0x03ba, 0x03bb, 0x03bc, 0x03bd, 0x03be, 0x03bf,
+	0x03c0, 0x03c1, 0x03c2, 0x03c3, 0x03c4, 0x03c5, 0x03c6, 0x03c7,
+	0x03c8, 0x03c9, 0x03ca, 0x03cb, 0x03cc, 0x03cd, 0x03ce, 0x0000,
+}
+
+// iso8859_8High is ISO 8859-8 (Latin/Hebrew).
+var iso8859_8High = [96]rune{
+	0x00a0, 0x0000, 0x00a2, 0x00a3, 0x00a4, 0x00a5, 0x00a6, 0x00a7,
+	0x00a8, 0x00a9, 0x00d7, 0x00ab, 0x00ac, 0x00ad, 0x00ae, 0x00af,
+	0x00b0, 0x00b1, 0x00b2, 0x00b3, 0x00b4, 0x00b5, 0x00b6, 0x00b7,
+	0x00b8, 0x00b9, 0x00f7, 0x00bb, 0x00bc, 0x00bd, 0x00be, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000,
+	0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x0000, 0x2017,
+	0x05d0, 0x05d1, 0x05d2, 0x05d3, 0x05d4, 0x05d5, 0x05d6, 0x05d7,
+	0x05d8, 0x05d9, 0x05da, 0x05db, 0x05dc, 0x05dd, 0x05de, 0x05df,
+	0x05e0, 0x05e1, 0x05e2, 0x05e3, 0x05e4, 0x05e5, 0x05e6, 0x05e7,
+	0x05e8, 0x05e9, 0x05ea, 0x0000, 0x0000, 0x200e, 0x200f, 0x0000,
+}
+
+// iso6937Diacritics lists, for each non-spacing diacritical mark of the
+// Latin table (ISO 6937), the accented letters written as the mark
+// followed by the base letter.
+var iso6937Diacritics = []struct {
+	mark    byte
+	letters string
+	bases   string
+}{
+	{0xC1, "\u00c0\u00c8\u00cc\u00d2\u00d9\u00e0\u00e8\u00ec\u00f2\u00f9", "AEIOUaeiou"},                                                                                                   // grave: ÀÈÌÒÙàèìòù
+	{0xC2, "\u00c1\u00c9\u00cd\u00d3\u00da\u00dd\u00e1\u00e9\u00ed\u00f3\u00fa\u00fd\u0106\u0107\u0139\u013a\u0143\u0144\u0154\u0155\u015a\u015b\u0179\u017a", "AEIOUYaeiouyCcLlNnRrSsZz"}, // acute: ÁÉÍÓÚÝáéíóúýĆćĹĺŃńŔŕŚśŹź
+	{0xC3, "\u00c2\u00ca\u00ce\u00d4\u00db\u00e2\u00ea\u00ee\u00f4\u00fb\u0108\u0109\u011c\u011d\u0124\u0125\u0134\u0135\u015c\u015d\u0174\u0175\u0176\u0177", "AEIOUaeiouCcGgHhJjSsWwYy"}, // circumflex: ÂÊÎÔÛâêîôûĈĉĜĝĤĥĴĵŜŝŴŵŶŷ
+	{0xC4, "\u00c3\u00d1\u00d5\u00e3\u00f1\u00f5\u0128\u0129\u0168\u0169", "ANOanoIiUu"},                                                                                                   // tilde: ÃÑÕãñõĨĩŨũ
+	{0xC5, "\u0100\u0101\u0112\u0113\u012a\u012b\u014c\u014d\u016a\u016b", "AaEeIiOoUu"},                                                                                                   // macron: ĀāĒēĪīŌōŪū
+	{0xC6, "\u0102\u0103\u011e\u011f\u016c\u016d", "AaGgUu"},                                                                                                                               // breve: ĂăĞğŬŭ
+	{0xC7, "\u010a\u010b\u0116\u0117\u0120\u0121\u0130\u017b\u017c", "CcEeGgIZz"},                                                                                                          // dot above: ĊċĖėĠġİŻż
+	{0xC8, "\u00c4\u00cb\u00cf\u00d6\u00dc\u00e4\u00eb\u00ef\u00f6\u00fc\u00ff\u0178", "AEIOUaeiouyY"},                                                                                     // diaeresis: ÄËÏÖÜäëïöüÿŸ
+	{0xCA, "\u00c5\u00e5\u016e\u016f", "AaUu"},                                                                                                                                             // ring: ÅåŮů
+	// Romanian comma-below letters are written with the cedilla, as in
+	// ISO 8859-2.
+	{0xCB, "\u00c7\u00e7\u0122\u0123\u0136\u0137\u013b\u013c\u0145\u0146\u0156\u0157\u015e\u015f\u0162\u0163\u0218\u0219\u021a\u021b", "CcGgKkLlNnRrSsTtSsTt"}, // cedilla: ÇçĢģĶķĻļŅņŖŗŞşŢţȘșȚț
+	{0xCD, "\u0150\u0151\u0170\u0171", "OoUu"},                             // double acute: ŐőŰű
+	{0xCE, "\u0104\u0105\u0118\u0119\u012e\u012f\u0172\u0173", "AaEeIiUu"}, // ogonek: ĄąĘęĮįŲų
+	{0xCF, "\u010c\u010d\u010e\u010f\u011a\u011b\u013d\u013e\u0147\u0148\u0158\u0159\u0160\u0161\u0164\u0165\u017d\u017e", "CcDdEeLlNnRrSsTtZz"}, // caron: ČčĎďĚěĽľŇňŘřŠšŤťŽž
+}
+
+// iso6937Singles are the characters of the Latin table written as a single
+// byte above 0x9F.
+var iso6937Singles = map[rune]byte{
+	'\u00a0': 0xA0, '\u00a1': 0xA1, '\u00a2': 0xA2, '\u00a3': 0xA3, '\u00a5': 0xA5, '\u00a7': 0xA7, '\u00a4': 0xA8, // no-break space, ¡¢£¥§¤
+	'\u2018': 0xA9, '\u201c': 0xAA, '\u00ab': 0xAB, '\u2190': 0xAC, '\u2191': 0xAD, '\u2192': 0xAE, '\u2193': 0xAF, // ‘“«←↑→↓
+	'\u00b0': 0xB0, '\u00b1': 0xB1, '\u00b2': 0xB2, '\u00b3': 0xB3, '\u00d7': 0xB4, '\u00b5': 0xB5, '\u00b6': 0xB6, '\u00b7': 0xB7, // °±²³×µ¶·
+	'\u00f7': 0xB8, '\u2019': 0xB9, '\u201d': 0xBA, '\u00bb': 0xBB, '\u00bc': 0xBC, '\u00bd': 0xBD, '\u00be': 0xBE, '\u00bf': 0xBF, // ÷’”»¼½¾¿
+	'\u2015': 0xD0, '\u00b9': 0xD1, '\u00ae': 0xD2, '\u00a9': 0xD3, '\u2122': 0xD4, '\u266a': 0xD5, '\u00ac': 0xD6, '\u00a6': 0xD7, // ―¹®©™♪¬¦
+	'\u215b': 0xDC, '\u215c': 0xDD, '\u215d': 0xDE, '\u215e': 0xDF, // ⅛⅜⅝⅞
+	'\u03a9': 0xE0, '\u00c6': 0xE1, '\u0110': 0xE2, '\u00aa': 0xE3, '\u0126': 0xE4, '\u0132': 0xE6, '\u013f': 0xE7, '\u0141': 0xE8, // ΩÆĐªĦĲĿŁ
+	'\u00d8': 0xE9, '\u0152': 0xEA, '\u00ba': 0xEB, '\u00de': 0xEC, '\u0166': 0xED, '\u014a': 0xEE, '\u0149': 0xEF, // ØŒºÞŦŊŉ
+	'\u0138': 0xF0, '\u00e6': 0xF1, '\u0111': 0xF2, '\u00f0': 0xF3, '\u0127': 0xF4, '\u0131': 0xF5, '\u0133': 0xF6, '\u0140': 0xF7, // ĸæđðħıĳŀ
+	'\u0142': 0xF8, '\u00f8': 0xF9, '\u0153': 0xFA, '\u00df': 0xFB, '\u00fe': 0xFC, '\u0167': 0xFD, '\u014b': 0xFE, '\u00ad': 0xFF, // łøœßþŧŋ, soft hyphen
+}
+
+var (
+	encoderOnce   sync.Once
+	iso6937Pairs  map[rune][2]byte
+	codePageBytes map[STLCodePage]map[rune]byte
+	iso8859Bytes  map[STLCharset]map[rune]byte
+)
+
+// buildEncoders indexes the character tables by character.
+func buildEncoders() {
+	iso6937Pairs = map[rune][2]byte{}
+	for _, d := range iso6937Diacritics {
+		bases := []byte(d.bases)
+		for i, r := range []rune(d.letters) {
+			iso6937Pairs[r] = [2]byte{d.mark, bases[i]}
+		}
+	}
+	codePageBytes = map[STLCodePage]map[rune]byte{}
+	for cp, table := range codePageHigh {
+		codePageBytes[cp] = reverseTable(table[:], 0x80)
+	}
+	iso8859Bytes = map[STLCharset]map[rune]byte{}
+	for cs, table := range iso8859High {
+		iso8859Bytes[cs] = reverseTable(table[:], 0xA0)
+	}
+}
+
+func reverseTable(table []rune, first int) map[rune]byte {
+	m := make(map[rune]byte, len(table))
+	for i, r := range table {
+		if r != 0 {
+			m[r] = byte(first + i)
+		}
+	}
+	return m
+}
+
+// encodeCodePage returns the bytes of r in a GSI code page.
+func encodeCodePage(cp STLCodePage, r rune) ([]byte, bool) {
+	if r >= 0x20 && r < 0x7F {
+		return []byte{byte(r)}, true
+	}
+	encoderOnce.Do(buildEncoders)
+	b, ok := codePageBytes[cp][r]
+	return []byte{b}, ok
+}
+
+// encodeCharset returns the bytes of r in a character code table. Accented
+// letters of the Latin table take two bytes.
+func encodeCharset(cs STLCharset, r rune) ([]byte, bool) {
+	if r >= 0x20 && r < 0x7F {
+		return []byte{byte(r)}, true
+	}
+	encoderOnce.Do(buildEncoders)
+	if cs != STLLatin {
+		b, ok := iso8859Bytes[cs][r]
+		return []byte{b}, ok
+	}
+	if b, ok := iso6937Singles[r]; ok {
+		return []byte{b}, true
+	}
+	if p, ok := iso6937Pairs[r]; ok {
+		return p[:], true
+	}
+	return nil, false
+}
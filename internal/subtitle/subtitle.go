@@ -0,0 +1,33 @@
+// Package subtitle reads and writes subtitle files independently of the
+// transcription pipeline: SRT parsing and SRT, ASS, WebVTT, LRC, TTML,
+// EBU STL and SCC writers for cues that are already laid out.
+package subtitle
+
+import (
+	"fmt"
+	"math"
+)
+
+// Cue is one timed subtitle. Text may hold several lines separated by "\n".
+type Cue struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// millis converts seconds to whole milliseconds, never below zero.
+func millis(seconds float64) int64 {
+	return max(int64(math.Round(seconds*1000)), 0)
+}
+
+// FormatSRTTime formats seconds as an SRT timestamp HH:MM:SS,mmm.
+func FormatSRTTime(seconds float64) string {
+	ms := millis(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// FormatASSTime formats seconds as an ASS timestamp H:MM:SS.cc.
+func FormatASSTime(seconds float64) string {
+	cs := (millis(seconds) + 5) / 10
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
@@ -0,0 +1,204 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+
+	"scribe2srt/internal/timecode"
+)
+
+// TTML namespaces and profile designators.
+const (
+	nsTT            = "http://www.w3.org/ns/ttml"
+	nsTTP           = "http://www.w3.org/ns/ttml#parameter"
+	nsTTS           = "http://www.w3.org/ns/ttml#styling"
+	nsTTM           = "http://www.w3.org/ns/ttml#metadata"
+	profileIMSC1    = "http://www.w3.org/ns/ttml/profile/imsc1/text"
+	profileDFXPPres = "http://www.w3.org/ns/ttml/profile/dfxp-presentation"
+)
+
+// TTMLTiming selects how TTML time expressions are written.
+type TTMLTiming int
+
+const (
+	// TTMLClock writes media times as HH:MM:SS.mmm.
+	TTMLClock TTMLTiming = iota
+	// TTMLFrames writes media times as frame counts such as 1234f.
+	TTMLFrames
+	// TTMLSMPTE writes SMPTE timecodes HH:MM:SS:FF on the smpte time
+	// base. IMSC1 does not allow it, so only DFXP documents use it.
+	TTMLSMPTE
+)
+
+// ParseTTMLTiming parses a --ttml-time value.
+func ParseTTMLTiming(s string) (TTMLTiming, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "clock":
+		return TTMLClock, nil
+	case "frames":
+		return TTMLFrames, nil
+	case "smpte":
+		return TTMLSMPTE, nil
+	}
+	return TTMLClock, fmt.Errorf("unknown TTML timing %q (want clock, frames or smpte)", s)
+}
+
+func (t TTMLTiming) String() string {
+	switch t {
+	case TTMLFrames:
+		return "frames"
+	case TTMLSMPTE:
+		return "smpte"
+	default:
+		return "clock"
+	}
+}
+
+// TTMLOptions configures FormatTTML.
+type TTMLOptions struct {
+	// DFXP writes a TTML1 DFXP presentation document for legacy systems
+	// instead of an IMSC1 Text profile document.
+	DFXP bool
+	// Lang is the xml:lang of the document; empty means undetermined.
+	Lang  string
+	Title string
+	// FrameRate is declared with ttp:frameRate when set; frame and SMPTE
+	// timings require it and fall back to clock times without it.
+	FrameRate timecode.Rate
+	Timing    TTMLTiming
+}
+
+// TTMLStyle is a style definition. The first style given is the default
+// and applies to the whole body.
+type TTMLStyle struct {
+	ID     string
+	Color  string
+	Italic bool
+}
+
+// TTMLSpan is a run of text with an optional style; "\n" in Text becomes
+// <br/>.
+type TTMLSpan struct {
+	Text  string
+	Style string
+}
+
+// TTMLCue is one timed paragraph.
+type TTMLCue struct {
+	Start float64
+	End   float64
+	Style string
+	Spans []TTMLSpan
+}
+
+// FormatTTML writes cues as a TTML document with a single bottom region.
+func FormatTTML(opts TTMLOptions, styles []TTMLStyle, cues []TTMLCue) string {
+	timing := opts.Timing
+	if opts.FrameRate.IsZero() || (timing == TTMLSMPTE && !opts.DFXP) {
+		timing = TTMLClock
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&sb, `<tt xmlns="%s" xmlns:ttp="%s" xmlns:tts="%s" xmlns:ttm="%s" xml:lang="%s"`,
+		nsTT, nsTTP, nsTTS, nsTTM, xmlAttr(opts.Lang))
+	if opts.DFXP {
+		fmt.Fprintf(&sb, ` ttp:profile="%s"`, profileDFXPPres)
+	} else {
+		fmt.Fprintf(&sb, ` ttp:profile="%s"`, profileIMSC1)
+	}
+	if timing == TTMLSMPTE {
+		sb.WriteString(` ttp:timeBase="smpte"`)
+		if opts.FrameRate.DropFrame {
+			sb.WriteString(` ttp:dropMode="dropNTSC"`)
+		} else {
+			sb.WriteString(` ttp:dropMode="nonDrop"`)
+		}
+	} else {
+		sb.WriteString(` ttp:timeBase="media"`)
+	}
+	if !opts.FrameRate.IsZero() {
+		fmt.Fprintf(&sb, ` ttp:frameRate="%d"`, opts.FrameRate.Nominal())
+		if opts.FrameRate.Den == 1001 {
+			sb.WriteString(` ttp:frameRateMultiplier="1000 1001"`)
+		}
+	}
+	sb.WriteString(">\n")
+
+	sb.WriteString("  <head>\n")
+	if opts.Title != "" {
+		fmt.Fprintf(&sb, "    <metadata>\n      <ttm:title>%s</ttm:title>\n    </metadata>\n", xmlText(opts.Title))
+	}
+	sb.WriteString("    <styling>\n")
+	for i, s := range styles {
+		fmt.Fprintf(&sb, `      <style xml:id="%s"`, xmlAttr(s.ID))
+		if i == 0 {
+			sb.WriteString(` tts:fontFamily="proportionalSansSerif" tts:fontSize="100%" tts:lineHeight="125%" tts:textAlign="center"`)
+		}
+		if s.Color != "" {
+			fmt.Fprintf(&sb, ` tts:color="%s"`, xmlAttr(s.Color))
+		}
+		if s.Italic {
+			sb.WriteString(` tts:fontStyle="italic"`)
+		}
+		sb.WriteString("/>\n")
+	}
+	sb.WriteString("    </styling>\n")
+	sb.WriteString("    <layout>\n")
+	sb.WriteString(`      <region xml:id="bottom" tts:origin="10% 10%" tts:extent="80% 80%" tts:displayAlign="after" tts:textAlign="center"/>` + "\n")
+	sb.WriteString("    </layout>\n")
+	sb.WriteString("  </head>\n")
+
+	sb.WriteString(`  <body region="bottom"`)
+	if len(styles) > 0 {
+		fmt.Fprintf(&sb, ` style="%s"`, xmlAttr(styles[0].ID))
+	}
+	sb.WriteString(">\n    <div>\n")
+	for _, c := range cues {
+		fmt.Fprintf(&sb, `      <p begin="%s" end="%s"`, ttmlTime(c.Start, opts.FrameRate, timing), ttmlTime(c.End, opts.FrameRate, timing))
+		if c.Style != "" {
+			fmt.Fprintf(&sb, ` style="%s"`, xmlAttr(c.Style))
+		}
+		sb.WriteString(">")
+		for _, span := range c.Spans {
+			if span.Style != "" {
+				fmt.Fprintf(&sb, `<span style="%s">%s</span>`, xmlAttr(span.Style), ttmlLines(span.Text))
+			} else {
+				sb.WriteString(ttmlLines(span.Text))
+			}
+		}
+		sb.WriteString("</p>\n")
+	}
+	sb.WriteString("    </div>\n  </body>\n</tt>\n")
+	return sb.String()
+}
+
+// ttmlTime formats a time expression for timing.
+func ttmlTime(seconds float64, rate timecode.Rate, timing TTMLTiming) string {
+	switch timing {
+	case TTMLFrames:
+		return fmt.Sprintf("%df", max(rate.ToFrames(seconds), 0))
+	case TTMLSMPTE:
+		hh, mm, ss, ff := rate.Components(rate.ToFrames(seconds))
+		return fmt.Sprintf("%02d:%02d:%02d:%02d", hh, mm, ss, ff)
+	}
+	ms := millis(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// ttmlLines escapes text and turns its line breaks into <br/>.
+func ttmlLines(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	for i, line := range lines {
+		lines[i] = xmlText(line)
+	}
+	return strings.Join(lines, "<br/>")
+}
+
+var (
+	xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	xmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+)
+
+func xmlText(s string) string { return xmlTextEscaper.Replace(s) }
+func xmlAttr(s string) string { return xmlAttrEscaper.Replace(s) }
@@ -0,0 +1,266 @@
+package subtitle
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"scribe2srt/internal/timecode"
+)
+
+// ttmlDoc is the parts of a TTML document the IMSC1 checks look at.
+type ttmlDoc struct {
+	XMLName xml.Name   `xml:"http://www.w3.org/ns/ttml tt"`
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Head    struct {
+		Styles  []ttmlNode `xml:"styling>style"`
+		Regions []ttmlNode `xml:"layout>region"`
+	} `xml:"head"`
+	Body struct {
+		Attrs []xml.Attr `xml:",any,attr"`
+		Ps    []struct {
+			Attrs []xml.Attr `xml:",any,attr"`
+			Inner string     `xml:",innerxml"`
+		} `xml:"div>p"`
+	} `xml:"body"`
+}
+
+type ttmlNode struct {
+	Attrs []xml.Attr `xml:",any,attr"`
+}
+
+// nsXML is the namespace encoding/xml gives the xml: prefix.
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+func attr(attrs []xml.Attr, space, local string) (string, bool) {
+	for _, a := range attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+var (
+	ttmlClockTime  = regexp.MustCompile(`^(\d{2,}):(\d{2}):(\d{2})\.(\d{3})$`)
+	ttmlFrameTime  = regexp.MustCompile(`^(\d+)f$`)
+	ttmlSMPTETime  = regexp.MustCompile(`^\d{2}:\d{2}:\d{2}:\d{2}$`)
+	ttmlPercentage = regexp.MustCompile(`^(\d+(?:\.\d+)?)% (\d+(?:\.\d+)?)%$`)
+)
+
+// checkIMSC1 verifies doc against the IMSC1 Text profile constraints the
+// writer is responsible for and returns the begin and end of each p in
+// frames or milliseconds.
+func checkIMSC1(t *testing.T, doc string) [][2]int {
+	t.Helper()
+	var tt ttmlDoc
+	if err := xml.Unmarshal([]byte(doc), &tt); err != nil {
+		t.Fatalf("document is not well-formed: %v", err)
+	}
+
+	if _, ok := attr(tt.Attrs, nsXML, "lang"); !ok {
+		t.Error("tt has no xml:lang")
+	}
+	if p, _ := attr(tt.Attrs, nsTTP, "profile"); p != profileIMSC1 {
+		t.Errorf("ttp:profile = %q, want %q", p, profileIMSC1)
+	}
+	if tb, _ := attr(tt.Attrs, nsTTP, "timeBase"); tb != "media" {
+		t.Errorf("ttp:timeBase = %q; IMSC1 requires media", tb)
+	}
+	if _, ok := attr(tt.Attrs, nsTTS, "extent"); ok {
+		t.Error("tts:extent on tt; the root container must be left to the player")
+	}
+	_, hasFrameRate := attr(tt.Attrs, nsTTP, "frameRate")
+
+	ids := map[string]bool{}
+	for _, s := range tt.Head.Styles {
+		id, _ := attr(s.Attrs, nsXML, "id")
+		ids[id] = true
+	}
+	regions := map[string]bool{}
+	for _, r := range tt.Head.Regions {
+		id, _ := attr(r.Attrs, nsXML, "id")
+		regions[id] = true
+		origin, _ := attr(r.Attrs, nsTTS, "origin")
+		extent, _ := attr(r.Attrs, nsTTS, "extent")
+		o, e := ttmlPercentage.FindStringSubmatch(origin), ttmlPercentage.FindStringSubmatch(extent)
+		if o == nil || e == nil {
+			t.Errorf("region %s: origin %q and extent %q must be percentages", id, origin, extent)
+			continue
+		}
+		for i := 1; i <= 2; i++ {
+			ov, _ := strconv.ParseFloat(o[i], 64)
+			ev, _ := strconv.ParseFloat(e[i], 64)
+			if ov+ev > 100 {
+				t.Errorf("region %s extends outside the root container", id)
+			}
+		}
+	}
+	if len(regions) == 0 {
+		t.Error("no region defined")
+	}
+	if len(regions) > 4 {
+		t.Errorf("%d regions; IMSC1 allows at most 4", len(regions))
+	}
+	if r, _ := attr(tt.Body.Attrs, "", "region"); !regions[r] {
+		t.Errorf("body region %q is not defined", r)
+	}
+	if s, ok := attr(tt.Body.Attrs, "", "style"); ok && !ids[s] {
+		t.Errorf("body style %q is not defined", s)
+	}
+
+	spanStyle := regexp.MustCompile(`style="([^"]*)"`)
+	var times [][2]int
+	for i, p := range tt.Body.Ps {
+		if s, ok := attr(p.Attrs, "", "style"); ok && !ids[s] {
+			t.Errorf("p %d: style %q is not defined", i, s)
+		}
+		for _, m := range spanStyle.FindAllStringSubmatch(p.Inner, -1) {
+			if !ids[m[1]] {
+				t.Errorf("p %d: span style %q is not defined", i, m[1])
+			}
+		}
+		begin, _ := attr(p.Attrs, "", "begin")
+		end, _ := attr(p.Attrs, "", "end")
+		var span [2]int
+		for j, v := range []string{begin, end} {
+			switch {
+			case ttmlClockTime.MatchString(v):
+				m := ttmlClockTime.FindStringSubmatch(v)
+				h, _ := strconv.Atoi(m[1])
+				mi, _ := strconv.Atoi(m[2])
+				s, _ := strconv.Atoi(m[3])
+				ms, _ := strconv.Atoi(m[4])
+				if mi > 59 || s > 59 {
+					t.Errorf("p %d: invalid clock time %q", i, v)
+				}
+				span[j] = ((h*60+mi)*60+s)*1000 + ms
+			case ttmlFrameTime.MatchString(v):
+				if !hasFrameRate {
+					t.Errorf("p %d: frame time %q without ttp:frameRate", i, v)
+				}
+				span[j], _ = strconv.Atoi(ttmlFrameTime.FindStringSubmatch(v)[1])
+			default:
+				t.Errorf("p %d: time %q is not a media time expression", i, v)
+			}
+		}
+		if span[1] <= span[0] {
+			t.Errorf("p %d: end %q not after begin %q", i, end, begin)
+		}
+		times = append(times, span)
+	}
+	return times
+}
+
+func ttmlSample() ([]TTMLStyle, []TTMLCue) {
+	styles := []TTMLStyle{{ID: "s0", Color: "white"}, {ID: "s1", Italic: true}, {ID: "s2", Color: "yellow"}}
+	cues := []TTMLCue{
+		{Start: 1, End: 2.5, Spans: []TTMLSpan{{Text: "Fish & chips\n<for two>"}}},
+		{Start: 62.25, End: 64, Style: "s1", Spans: []TTMLSpan{{Text: "(door slams)"}}},
+		{Start: 3600, End: 3601.5, Spans: []TTMLSpan{{Text: "Who's there?\n", Style: "s0"}, {Text: "Me.", Style: "s2"}}},
+	}
+	return styles, cues
+}
+
+func TestFormatTTML_IMSC1(t *testing.T) {
+	styles, cues := ttmlSample()
+	doc := FormatTTML(TTMLOptions{Lang: "en", Title: "Tom & Jerry"}, styles, cues)
+
+	times := checkIMSC1(t, doc)
+	want := [][2]int{{1000, 2500}, {62250, 64000}, {3600000, 3601500}}
+	if len(times) != len(want) {
+		t.Fatalf("%d paragraphs, want %d", len(times), len(want))
+	}
+	for i := range want {
+		if times[i] != want[i] {
+			t.Errorf("p %d timing = %v, want %v", i, times[i], want[i])
+		}
+	}
+
+	for _, s := range []string{
+		`xml:lang="en"`,
+		`<ttm:title>Tom &amp; Jerry</ttm:title>`,
+		`<p begin="00:00:01.000" end="00:00:02.500">Fish &amp; chips<br/>&lt;for two&gt;</p>`,
+		`<p begin="00:01:02.250" end="00:01:04.000" style="s1">(door slams)</p>`,
+		`<span style="s0">Who's there?<br/></span><span style="s2">Me.</span>`,
+		`<style xml:id="s1" tts:fontStyle="italic"/>`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("document lacks %s:\n%s", s, doc)
+		}
+	}
+	if strings.Contains(doc, "frameRate") {
+		t.Error("frame rate declared without one being set")
+	}
+}
+
+func TestFormatTTML_Frames(t *testing.T) {
+	styles, cues := ttmlSample()
+	doc := FormatTTML(TTMLOptions{Lang: "en", FrameRate: timecode.Rate23976, Timing: TTMLFrames}, styles, cues)
+
+	times := checkIMSC1(t, doc)
+	if times[0] != [2]int{24, 60} {
+		t.Errorf("first p in frames = %v, want [24 60]", times[0])
+	}
+	for _, s := range []string{`ttp:frameRate="24"`, `ttp:frameRateMultiplier="1000 1001"`} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("document lacks %s", s)
+		}
+	}
+}
+
+func TestFormatTTML_FramesWithoutRateFallsBackToClock(t *testing.T) {
+	styles, cues := ttmlSample()
+	doc := FormatTTML(TTMLOptions{Timing: TTMLFrames}, styles, cues)
+	checkIMSC1(t, doc)
+	if !strings.Contains(doc, `begin="00:00:01.000"`) {
+		t.Errorf("expected clock times:\n%s", doc)
+	}
+}
+
+func TestFormatTTML_SMPTENotAllowedInIMSC1(t *testing.T) {
+	styles, cues := ttmlSample()
+	doc := FormatTTML(TTMLOptions{FrameRate: timecode.Rate25, Timing: TTMLSMPTE}, styles, cues)
+	checkIMSC1(t, doc)
+	if !strings.Contains(doc, `ttp:frameRate="25"`) || strings.Contains(doc, "frameRateMultiplier") {
+		t.Errorf("unexpected frame rate declaration:\n%s", doc)
+	}
+}
+
+func TestFormatTTML_DFXP(t *testing.T) {
+	styles, cues := ttmlSample()
+	doc := FormatTTML(TTMLOptions{DFXP: true, Lang: "fr", FrameRate: timecode.Rate2997DF, Timing: TTMLSMPTE}, styles, cues)
+	if err := xml.Unmarshal([]byte(doc), new(ttmlDoc)); err != nil {
+		t.Fatalf("document is not well-formed: %v", err)
+	}
+	for _, s := range []string{
+		`ttp:profile="` + profileDFXPPres + `"`,
+		`ttp:timeBase="smpte" ttp:dropMode="dropNTSC"`,
+		`ttp:frameRate="30" ttp:frameRateMultiplier="1000 1001"`,
+		// 3600 s is 107892 frames at 29.97, labelled 01:00:00;00.
+		`<p begin="01:00:00:00" end="01:00:01:15">`,
+	} {
+		if !strings.Contains(doc, s) {
+			t.Errorf("document lacks %s:\n%s", s, doc)
+		}
+	}
+	for _, m := range regexp.MustCompile(`begin="([^"]*)"`).FindAllStringSubmatch(doc, -1) {
+		if !ttmlSMPTETime.MatchString(m[1]) {
+			t.Errorf("begin %q is not an SMPTE timecode", m[1])
+		}
+	}
+}
+
+func TestParseTTMLTiming(t *testing.T) {
+	for _, want := range []TTMLTiming{TTMLClock, TTMLFrames, TTMLSMPTE} {
+		got, err := ParseTTMLTiming(want.String())
+		if err != nil || got != want {
+			t.Errorf("ParseTTMLTiming(%q) = %v, %v", want.String(), got, err)
+		}
+	}
+	if _, err := ParseTTMLTiming("seconds"); err == nil {
+		t.Error("ParseTTMLTiming(seconds) succeeded")
+	}
+}
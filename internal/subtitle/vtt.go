@@ -0,0 +1,40 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatVTTTime formats seconds as a WebVTT timestamp HH:MM:SS.mmm, the form
+// used both for cue timings and for timestamp tags inside cue text.
+func FormatVTTTime(seconds float64) string {
+	ms := millis(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeVTT escapes the characters that would start a tag or a character
+// reference in WebVTT cue text. FormatVTT writes text as given, so tags such
+// as <00:00:01.250> can be added to escaped text.
+func EscapeVTT(text string) string {
+	return vttEscaper.Replace(text)
+}
+
+// FormatVTT writes cues as a WebVTT file. Cue text is written as given and
+// must already be escaped with EscapeVTT; blank lines, which would end a
+// cue, are dropped.
+func FormatVTT(cues []Cue) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n")
+	for _, c := range cues {
+		var lines []string
+		for _, line := range strings.Split(strings.ReplaceAll(c.Text, "\r", ""), "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+		fmt.Fprintf(&sb, "\n%s --> %s\n%s\n", FormatVTTTime(c.Start), FormatVTTTime(c.End), strings.Join(lines, "\n"))
+	}
+	return sb.String()
+}
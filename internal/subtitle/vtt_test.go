@@ -0,0 +1,29 @@
+package subtitle
+
+import "testing"
+
+func TestFormatVTTTime(t *testing.T) {
+	tests := map[float64]string{
+		0:        "00:00:00.000",
+		1.25:     "00:00:01.250",
+		3723.456: "01:02:03.456",
+	}
+	for in, want := range tests {
+		if got := FormatVTTTime(in); got != want {
+			t.Errorf("FormatVTTTime(%g) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFormatVTT(t *testing.T) {
+	vtt := FormatVTT([]Cue{
+		{Start: 1, End: 2.5, Text: EscapeVTT("Tom & Jerry <live>") + "\n\nsecond"},
+		{Start: 3, End: 4, Text: "third"},
+	})
+	want := "WEBVTT\n" +
+		"\n00:00:01.000 --> 00:00:02.500\nTom &amp; Jerry &lt;live&gt;\nsecond\n" +
+		"\n00:00:03.000 --> 00:00:04.000\nthird\n"
+	if vtt != want {
+		t.Errorf("FormatVTT =\n%q\nwant\n%q", vtt, want)
+	}
+}
@@ -0,0 +1,264 @@
+// Package textwidth measures subtitle text for line length and reading speed
+// limits under different width models.
+package textwidth
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Mode selects how the display width of text is measured.
+type Mode int
+
+const (
+	// Runes counts every Unicode code point as one unit.
+	Runes Mode = iota
+	// EastAsian counts East Asian Wide and Fullwidth characters as two
+	// half-width units and zero-width combining characters as none.
+	EastAsian
+	// Graphemes counts every user-perceived character (grapheme cluster),
+	// such as an emoji sequence or a letter with combining marks, once.
+	Graphemes
+)
+
+// ParseMode parses a --width-mode value.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "runes":
+		return Runes, nil
+	case "eastasian":
+		return EastAsian, nil
+	case "graphemes":
+		return Graphemes, nil
+	}
+	return Runes, fmt.Errorf("unknown width mode %q (want runes, eastasian or graphemes)", s)
+}
+
+func (m Mode) String() string {
+	switch m {
+	case EastAsian:
+		return "eastasian"
+	case Graphemes:
+		return "graphemes"
+	default:
+		return "runes"
+	}
+}
+
+// FullWidthUnits returns how many units a full-width character occupies in
+// this mode. CJK limits are configured in full-width characters, so callers
+// scale them by this factor when measuring in half-width units.
+func (m Mode) FullWidthUnits() int {
+	if m == EastAsian {
+		return 2
+	}
+	return 1
+}
+
+// RuneWidths returns the width contributed by each rune of runes. The width
+// of a grapheme cluster is attributed to its first rune; the remaining runes
+// of the cluster get zero, so a zero entry marks a position where a line
+// must not be broken. In Runes mode every entry is 1.
+func (m Mode) RuneWidths(runes []rune) []int {
+	widths := make([]int, len(runes))
+	if m == Runes {
+		for i := range widths {
+			widths[i] = 1
+		}
+		return widths
+	}
+
+	for start := 0; start < len(runes); {
+		end := clusterEnd(runes, start)
+		widths[start] = m.clusterWidth(runes[start:end])
+		start = end
+	}
+	return widths
+}
+
+// Width returns the display width of s.
+func (m Mode) Width(s string) int {
+	if m == Runes {
+		return len([]rune(s))
+	}
+	total := 0
+	for _, w := range m.RuneWidths([]rune(s)) {
+		total += w
+	}
+	return total
+}
+
+// WidthNoSpace returns the display width of s ignoring whitespace.
+func (m Mode) WidthNoSpace(s string) int {
+	runes := []rune(s)
+	total := 0
+	for i, w := range m.RuneWidths(runes) {
+		if !unicode.IsSpace(runes[i]) {
+			total += w
+		}
+	}
+	return total
+}
+
+// IsClusterBoundary reports whether a line may be broken before runes[pos]
+// without splitting a grapheme cluster.
+func (m Mode) IsClusterBoundary(runes []rune, pos int) bool {
+	if m == Runes || pos <= 0 || pos >= len(runes) {
+		return true
+	}
+	return !continuesCluster(runes, pos)
+}
+
+func (m Mode) clusterWidth(cluster []rune) int {
+	if m == Graphemes {
+		return 1
+	}
+
+	base := cluster[0]
+	if isZeroWidth(base) {
+		return 0
+	}
+	if isWide(base) || isRegionalIndicator(base) {
+		return 2
+	}
+	for _, r := range cluster[1:] {
+		// VS16 requests emoji presentation, which is rendered wide.
+		if r == 0xFE0F {
+			return 2
+		}
+	}
+	// Half-width katakana voicing marks occupy a cell of their own.
+	for _, r := range cluster[1:] {
+		if r == 0xFF9E || r == 0xFF9F {
+			return 1 + len(cluster[1:])
+		}
+	}
+	return 1
+}
+
+// clusterEnd returns the index just past the grapheme cluster starting at
+// runes[start].
+func clusterEnd(runes []rune, start int) int {
+	end := start + 1
+	for end < len(runes) && continuesCluster(runes, end) {
+		end++
+	}
+	return end
+}
+
+// continuesCluster reports whether runes[i] belongs to the same grapheme
+// cluster as runes[i-1]. It implements the subset of the UAX #29 rules that
+// matters for subtitle text: combining marks, joiners, variation selectors,
+// emoji modifiers and tags, regional indicator pairs and Hangul jamo.
+func continuesCluster(runes []rune, i int) bool {
+	prev, r := runes[i-1], runes[i]
+
+	if prev == '\r' && r == '\n' {
+		return true
+	}
+	if isExtend(r) || r == 0x200D {
+		return true
+	}
+	if prev == 0x200D {
+		return true
+	}
+	if isRegionalIndicator(prev) && isRegionalIndicator(r) {
+		// Pair regional indicators from the start of the run.
+		n := 0
+		for j := i - 1; j >= 0 && isRegionalIndicator(runes[j]); j-- {
+			n++
+		}
+		return n%2 == 1
+	}
+	switch {
+	case isHangulL(prev):
+		return isHangulL(r) || isHangulV(r)
+	case isHangulV(prev):
+		return isHangulV(r) || isHangulT(r)
+	case isHangulT(prev):
+		return isHangulT(r)
+	}
+	return false
+}
+
+// isExtend reports whether r extends the preceding grapheme cluster.
+func isExtend(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc):
+		return true
+	case r >= 0xFE00 && r <= 0xFE0F, r >= 0xE0100 && r <= 0xE01EF: // variation selectors
+		return true
+	case r >= 0x1F3FB && r <= 0x1F3FF: // emoji skin tone modifiers
+		return true
+	case r >= 0xE0020 && r <= 0xE007F: // emoji tag sequences
+		return true
+	case r == 0xFF9E || r == 0xFF9F: // half-width katakana voicing marks
+		return true
+	}
+	return false
+}
+
+// isZeroWidth reports whether r has no advance width of its own.
+func isZeroWidth(r rune) bool {
+	switch {
+	case r == 0x200B, r == 0x200C, r == 0x200D, r == 0x2060, r == 0xFEFF:
+		return true
+	case r >= 0x200E && r <= 0x200F, r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+		return true
+	}
+	return unicode.In(r, unicode.Mn, unicode.Me)
+}
+
+func isRegionalIndicator(r rune) bool { return r >= 0x1F1E6 && r <= 0x1F1FF }
+func isHangulL(r rune) bool           { return r >= 0x1100 && r <= 0x115F }
+func isHangulV(r rune) bool           { return r >= 0x1160 && r <= 0x11A7 }
+func isHangulT(r rune) bool           { return r >= 0x11A8 && r <= 0x11FF }
+
+// isWide reports whether r has East Asian Width W or F.
+func isWide(r rune) bool {
+	if r < 0x1100 {
+		return false
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < wideRanges[mid][0]:
+			hi = mid
+		case r > wideRanges[mid][1]:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// wideRanges lists the East Asian Wide (W) and Fullwidth (F) code point
+// ranges from Unicode's EastAsianWidth.txt, in ascending order.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x16FE0, 0x16FE4},
+	{0x17000, 0x18AFF}, {0x1B000, 0x1B2FF}, {0x1F004, 0x1F004}, {0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F202}, {0x1F210, 0x1F23B},
+	{0x1F240, 0x1F248}, {0x1F250, 0x1F251}, {0x1F260, 0x1F265}, {0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335}, {0x1F337, 0x1F37C}, {0x1F37E, 0x1F393}, {0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3}, {0x1F3E0, 0x1F3F0}, {0x1F3F4, 0x1F3F4}, {0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440}, {0x1F442, 0x1F4FC}, {0x1F4FF, 0x1F53D}, {0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567}, {0x1F57A, 0x1F57A}, {0x1F595, 0x1F596}, {0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F}, {0x1F680, 0x1F6C5}, {0x1F6CC, 0x1F6CC}, {0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7}, {0x1F6EB, 0x1F6EC}, {0x1F6F4, 0x1F6FC}, {0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F93A}, {0x1F93C, 0x1F945}, {0x1F947, 0x1F9FF}, {0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
@@ -0,0 +1,78 @@
+package textwidth
+
+import "testing"
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Mode
+		wantErr bool
+	}{
+		{"", Runes, false},
+		{"runes", Runes, false},
+		{"EastAsian", EastAsian, false},
+		{"graphemes", Graphemes, false},
+		{"pixels", Runes, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMode(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMode(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		text      string
+		runes     int
+		eastAsian int
+		graphemes int
+	}{
+		{"hello", 5, 5, 5},
+		{"日本", 2, 4, 2},                   // 日本
+		{"iPhone 15 の新機能", 14, 18, 14},    // iPhone 15 の新機能
+		{"café", 5, 4, 4},                // café with a combining acute
+		{"\U0001F44D\U0001F3FD", 2, 2, 1}, // thumbs up with skin tone
+		{"\U0001F1EF\U0001F1F5", 2, 2, 1}, // flag of Japan
+		{"ｶﾞ", 2, 2, 1},                   // ｶﾞ (half-width katakana)
+		{"가", 2, 2, 1},                   // Hangul jamo ᄀ + ᅡ
+		{"❤️", 2, 2, 1},                   // ❤️ with emoji presentation
+	}
+
+	for _, tt := range tests {
+		if got := Runes.Width(tt.text); got != tt.runes {
+			t.Errorf("Runes.Width(%q) = %d, want %d", tt.text, got, tt.runes)
+		}
+		if got := EastAsian.Width(tt.text); got != tt.eastAsian {
+			t.Errorf("EastAsian.Width(%q) = %d, want %d", tt.text, got, tt.eastAsian)
+		}
+		if got := Graphemes.Width(tt.text); got != tt.graphemes {
+			t.Errorf("Graphemes.Width(%q) = %d, want %d", tt.text, got, tt.graphemes)
+		}
+	}
+}
+
+func TestWidthNoSpace(t *testing.T) {
+	if got := EastAsian.WidthNoSpace("a あ b"); got != 4 { // a あ b
+		t.Errorf("EastAsian.WidthNoSpace = %d, want 4", got)
+	}
+}
+
+func TestIsClusterBoundary(t *testing.T) {
+	runes := []rune("éx") // é x
+	if Graphemes.IsClusterBoundary(runes, 1) {
+		t.Error("expected no boundary before a combining mark")
+	}
+	if !Graphemes.IsClusterBoundary(runes, 2) {
+		t.Error("expected a boundary after the cluster")
+	}
+	if !Runes.IsClusterBoundary(runes, 1) {
+		t.Error("Runes mode should allow every position")
+	}
+}
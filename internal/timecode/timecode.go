@@ -0,0 +1,181 @@
+// Package timecode converts between seconds, frame counts and SMPTE
+// timecodes for the common broadcast frame rates.
+package timecode
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Rate is a video frame rate expressed as the rational Num/Den frames per
+// second. The zero Rate means frame timing is disabled.
+type Rate struct {
+	Num       int
+	Den       int
+	DropFrame bool
+}
+
+// Common frame rates.
+var (
+	Rate23976   = Rate{Num: 24000, Den: 1001}
+	Rate24      = Rate{Num: 24, Den: 1}
+	Rate25      = Rate{Num: 25, Den: 1}
+	Rate2997NDF = Rate{Num: 30000, Den: 1001}
+	Rate2997DF  = Rate{Num: 30000, Den: 1001, DropFrame: true}
+	Rate30      = Rate{Num: 30, Den: 1}
+)
+
+// ParseRate parses an --fps value such as "23.976", "25", "29.97df",
+// "29.97ndf" or "30000/1001". An empty string returns the zero Rate.
+func ParseRate(s string) (Rate, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return Rate{}, nil
+	}
+
+	drop := false
+	switch {
+	case strings.HasSuffix(s, "ndf"):
+		s = strings.TrimRight(strings.TrimSuffix(s, "ndf"), "-_ ")
+	case strings.HasSuffix(s, "df"):
+		s = strings.TrimRight(strings.TrimSuffix(s, "df"), "-_ ")
+		drop = true
+	}
+
+	var r Rate
+	switch s {
+	case "23.976", "23.98":
+		r = Rate23976
+	case "29.97":
+		r = Rate2997NDF
+	case "59.94":
+		r = Rate{Num: 60000, Den: 1001}
+	default:
+		if num, den, ok := strings.Cut(s, "/"); ok {
+			n, err1 := strconv.Atoi(num)
+			d, err2 := strconv.Atoi(den)
+			if err1 != nil || err2 != nil || n <= 0 || d <= 0 {
+				return Rate{}, fmt.Errorf("invalid frame rate %q", s)
+			}
+			r = Rate{Num: n, Den: d}
+			break
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return Rate{}, fmt.Errorf("invalid frame rate %q (want e.g. 23.976, 24, 25, 29.97df, 29.97ndf, 30)", s)
+		}
+		r = Rate{Num: n, Den: 1}
+	}
+
+	if drop {
+		if r.Den != 1001 || r.Nominal()%30 != 0 {
+			return Rate{}, fmt.Errorf("drop-frame timecode is only defined for 29.97 and 59.94 fps")
+		}
+		r.DropFrame = true
+	}
+	return r, nil
+}
+
+// IsZero reports whether frame timing is disabled.
+func (r Rate) IsZero() bool {
+	return r.Num == 0 || r.Den == 0
+}
+
+// FPS returns the frame rate in frames per second.
+func (r Rate) FPS() float64 {
+	if r.IsZero() {
+		return 0
+	}
+	return float64(r.Num) / float64(r.Den)
+}
+
+// Nominal returns the integer frame count per timecode second, e.g. 30 for
+// 29.97 fps.
+func (r Rate) Nominal() int {
+	return int(math.Round(r.FPS()))
+}
+
+func (r Rate) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	var s string
+	if r.Den == 1 {
+		s = strconv.Itoa(r.Num)
+	} else {
+		s = strconv.FormatFloat(r.FPS(), 'f', 3, 64)
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if r.DropFrame {
+		s += "df"
+	}
+	return s
+}
+
+// ToFrames converts seconds to the nearest frame number.
+func (r Rate) ToFrames(seconds float64) int64 {
+	return int64(math.Round(seconds * float64(r.Num) / float64(r.Den)))
+}
+
+// CeilFrames converts a duration in seconds to a whole number of frames,
+// rounding up so the duration is never shortened.
+func (r Rate) CeilFrames(seconds float64) int64 {
+	return int64(math.Ceil(seconds*float64(r.Num)/float64(r.Den) - 1e-9))
+}
+
+// ToSeconds converts a frame number to seconds.
+func (r Rate) ToSeconds(frames int64) float64 {
+	return float64(frames) * float64(r.Den) / float64(r.Num)
+}
+
+// Snap rounds seconds to the nearest frame boundary.
+func (r Rate) Snap(seconds float64) float64 {
+	if r.IsZero() {
+		return seconds
+	}
+	return r.ToSeconds(r.ToFrames(seconds))
+}
+
+// Components splits a frame number into timecode hours, minutes, seconds
+// and frames. For drop-frame rates, frame labels 0 and 1 (0-3 at 59.94) are
+// skipped at the start of every minute except each tenth minute, so the
+// timecode stays in step with wall-clock time.
+func (r Rate) Components(frames int64) (hh, mm, ss, ff int) {
+	if frames < 0 {
+		frames = 0
+	}
+	nominal := int64(r.Nominal())
+
+	if r.DropFrame {
+		drop := nominal / 15 // 2 at 29.97, 4 at 59.94
+		perMinute := nominal*60 - drop
+		perTenMinutes := nominal*600 - drop*9
+
+		tens := frames / perTenMinutes
+		rem := frames % perTenMinutes
+		frames += drop * 9 * tens
+		if rem > drop {
+			frames += drop * ((rem - drop) / perMinute)
+		}
+	}
+
+	ff = int(frames % nominal)
+	totalSec := frames / nominal
+	ss = int(totalSec % 60)
+	mm = int(totalSec / 60 % 60)
+	hh = int(totalSec / 3600)
+	return hh, mm, ss, ff
+}
+
+// FormatSMPTE formats a frame number as an SMPTE timecode HH:MM:SS:FF.
+// Drop-frame timecodes use a semicolon before the frame field.
+func (r Rate) FormatSMPTE(frames int64) string {
+	hh, mm, ss, ff := r.Components(frames)
+	sep := ":"
+	if r.DropFrame {
+		sep = ";"
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", hh, mm, ss, sep, ff)
+}
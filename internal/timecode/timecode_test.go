@@ -0,0 +1,105 @@
+package timecode
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"", Rate{}, false},
+		{"23.976", Rate23976, false},
+		{"24", Rate24, false},
+		{"25", Rate25, false},
+		{"29.97", Rate2997NDF, false},
+		{"29.97ndf", Rate2997NDF, false},
+		{"29.97DF", Rate2997DF, false},
+		{"30000/1001", Rate2997NDF, false},
+		{"25df", Rate{}, true},
+		{"fast", Rate{}, true},
+		{"0", Rate{}, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateString(t *testing.T) {
+	tests := []struct {
+		rate Rate
+		want string
+	}{
+		{Rate{}, ""},
+		{Rate23976, "23.976"},
+		{Rate25, "25"},
+		{Rate2997DF, "29.97df"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rate.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestFormatSMPTE(t *testing.T) {
+	tests := []struct {
+		rate   Rate
+		frames int64
+		want   string
+	}{
+		{Rate25, 0, "00:00:00:00"},
+		{Rate25, 25*3600 + 24, "01:00:00:24"},
+		{Rate2997NDF, 1800, "00:01:00:00"},
+		// Drop-frame skips labels ;00 and ;01 at each minute but every tenth.
+		{Rate2997DF, 1799, "00:00:59;29"},
+		{Rate2997DF, 1800, "00:01:00;02"},
+		{Rate2997DF, 17981, "00:09:59;29"},
+		{Rate2997DF, 17982, "00:10:00;00"},
+		{Rate2997DF, 107892, "01:00:00;00"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rate.FormatSMPTE(tt.frames); got != tt.want {
+			t.Errorf("%s FormatSMPTE(%d) = %q, want %q", tt.rate, tt.frames, got, tt.want)
+		}
+	}
+}
+
+func TestSnap(t *testing.T) {
+	got := Rate25.Snap(1.013)
+	if math.Abs(got-1.0) > 1e-9 {
+		t.Errorf("Snap(1.013) at 25 fps = %v, want 1.0", got)
+	}
+
+	got = Rate23976.Snap(1.0)
+	want := 24.0 * 1001 / 24000
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("Snap(1.0) at 23.976 fps = %v, want %v", got, want)
+	}
+
+	if got := (Rate{}).Snap(1.013); got != 1.013 {
+		t.Errorf("Snap with zero rate = %v, want unchanged", got)
+	}
+}
+
+func TestCeilFrames(t *testing.T) {
+	if got := Rate25.CeilFrames(0.083); got != 3 {
+		t.Errorf("CeilFrames(0.083) at 25 fps = %d, want 3", got)
+	}
+	if got := Rate25.CeilFrames(0.08); got != 2 {
+		t.Errorf("CeilFrames(0.08) at 25 fps = %d, want 2", got)
+	}
+}
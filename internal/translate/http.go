@@ -0,0 +1,55 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// httpTranslator posts each Request as JSON to a URL and expects
+// {"translations": [...]} back. It is meant for local translation servers
+// and for stand-ins during testing.
+type httpTranslator struct {
+	url    string
+	client *http.Client
+}
+
+type httpResponse struct {
+	Translations []string `json:"translations"`
+}
+
+func (t *httpTranslator) Translate(ctx context.Context, req Request) ([]string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("translation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("translator returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var out httpResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode translation response: %w", err)
+	}
+	if err := checkCount(out.Translations, req); err != nil {
+		return nil, err
+	}
+	return out.Translations, nil
+}
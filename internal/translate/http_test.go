@@ -0,0 +1,50 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPTranslator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		out := make([]string, len(req.Lines))
+		for i, line := range req.Lines {
+			out[i] = strings.ToUpper(line)
+		}
+		json.NewEncoder(w).Encode(httpResponse{Translations: out})
+	}))
+	defer srv.Close()
+
+	tr, err := New(HTTP, srv.URL, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := tr.Translate(context.Background(), Request{Target: "en", Lines: []string{"one", "two"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(lines, ",") != "ONE,TWO" {
+		t.Errorf("lines = %q", lines)
+	}
+}
+
+func TestHTTPTranslator_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	tr, _ := New(HTTP, srv.URL, "", "")
+	_, err := tr.Translate(context.Background(), Request{Target: "en", Lines: []string{"one"}})
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("err = %v, want a status 503 error", err)
+	}
+}
@@ -0,0 +1,118 @@
+package translate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// openAITranslator uses the chat completions API of OpenAI and the many
+// services and local servers compatible with it.
+type openAITranslator struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// systemPrompt returns the instructions for translating req.
+func systemPrompt(req Request) string {
+	source := "the source language"
+	if req.Source != "" {
+		source = languageName(req.Source)
+	}
+	return fmt.Sprintf(`You translate subtitles from %s to %s.
+The user sends a JSON object. "lines" holds the subtitle lines to translate; "context_before" and "context_after" hold the surrounding lines, for context only.
+Translate every entry of "lines" naturally and concisely, as a subtitler would. Keep one translation per line, in the same order, without merging or splitting lines.
+Reply with a JSON array of strings only.`, source, languageName(req.Target))
+}
+
+func (t *openAITranslator) Translate(ctx context.Context, req Request) ([]string, error) {
+	user, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(chatRequest{
+		Model: t.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: systemPrompt(req)},
+			{Role: "user", Content: string(user)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if t.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("translation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("translator returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var chat chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		return nil, fmt.Errorf("decode translation response: %w", err)
+	}
+	if len(chat.Choices) == 0 {
+		return nil, fmt.Errorf("translator returned no choices")
+	}
+
+	lines, err := parseLines(chat.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCount(lines, req); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// parseLines extracts the JSON array of translations from a model reply,
+// tolerating a Markdown code fence or a sentence around it.
+func parseLines(content string) ([]string, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("translator reply is not a JSON array: %q", content)
+	}
+	var lines []string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &lines); err != nil {
+		return nil, fmt.Errorf("decode translator reply: %w", err)
+	}
+	return lines, nil
+}
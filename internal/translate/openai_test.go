@@ -0,0 +1,84 @@
+package translate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAITranslator(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("Authorization = %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"role": "assistant", "content": "```json\n[\"Good morning.\", \"Let's go.\"]\n```"}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	tr, err := New(OpenAI, srv.URL+"/v1/", "test-model", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines, err := tr.Translate(context.Background(), Request{
+		Source: "ja",
+		Target: "en",
+		Before: []string{"context"},
+		Lines:  []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0] != "Good morning." || lines[1] != "Let's go." {
+		t.Errorf("lines = %q", lines)
+	}
+
+	if got.Model != "test-model" || len(got.Messages) != 2 {
+		t.Fatalf("request = %+v", got)
+	}
+	if !strings.Contains(got.Messages[0].Content, "from Japanese to English") {
+		t.Errorf("system prompt = %q", got.Messages[0].Content)
+	}
+	if !strings.Contains(got.Messages[1].Content, `"context_before":["context"]`) {
+		t.Errorf("user message = %q", got.Messages[1].Content)
+	}
+}
+
+func TestOpenAITranslator_LineCountMismatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{
+				{"message": map[string]string{"content": `["only one"]`}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	tr, _ := New(OpenAI, srv.URL, "m", "")
+	if _, err := tr.Translate(context.Background(), Request{Target: "en", Lines: []string{"a", "b"}}); err == nil {
+		t.Error("expected an error when the reply has the wrong number of lines")
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	lines, err := parseLines(`Here you go: ["a", "b"]`)
+	if err != nil || len(lines) != 2 {
+		t.Errorf("parseLines() = %q, %v", lines, err)
+	}
+	if _, err := parseLines("no array"); err == nil {
+		t.Error("expected an error for a reply without an array")
+	}
+}
@@ -0,0 +1,107 @@
+// Package translate sends subtitle text to a machine translation service.
+//
+// Translators receive a batch of lines together with the lines around it,
+// so the service can resolve pronouns, omitted subjects and terminology from
+// context, and must return exactly one translation per line.
+package translate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"scribe2srt/internal/lang"
+)
+
+const requestTimeout = 2 * time.Minute
+
+// Request is one batch of lines to translate.
+type Request struct {
+	// Source and Target are language codes; Source may be empty when the
+	// language was not detected.
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Before and After are neighbouring lines given as context only.
+	Before []string `json:"context_before,omitempty"`
+	Lines  []string `json:"lines"`
+	After  []string `json:"context_after,omitempty"`
+}
+
+// Translator translates a batch of lines. It returns one translation per
+// line of the request, in order.
+type Translator interface {
+	Translate(ctx context.Context, req Request) ([]string, error)
+}
+
+// Provider selects a Translator implementation.
+type Provider int
+
+const (
+	// OpenAI is any service speaking the OpenAI chat completions API.
+	OpenAI Provider = iota
+	// HTTP is a plain JSON endpoint, such as a local translation server.
+	HTTP
+)
+
+// DefaultOpenAIURL is the base URL used by the OpenAI provider when none is
+// given.
+const DefaultOpenAIURL = "https://api.openai.com/v1"
+
+// ParseProvider parses a --translator value.
+func ParseProvider(s string) (Provider, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "openai":
+		return OpenAI, nil
+	case "http":
+		return HTTP, nil
+	}
+	return OpenAI, fmt.Errorf("unknown translator %q (want openai or http)", s)
+}
+
+func (p Provider) String() string {
+	if p == HTTP {
+		return "http"
+	}
+	return "openai"
+}
+
+// New returns a Translator for provider. url is the service base URL (the
+// OpenAI provider defaults to DefaultOpenAIURL); model and apiKey are only
+// used by the OpenAI provider.
+func New(provider Provider, url, model, apiKey string) (Translator, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	switch provider {
+	case HTTP:
+		if url == "" {
+			return nil, fmt.Errorf("the http translator requires a URL")
+		}
+		return &httpTranslator{url: url, client: client}, nil
+	default:
+		if url == "" {
+			url = DefaultOpenAIURL
+		}
+		if model == "" {
+			return nil, fmt.Errorf("the openai translator requires a model")
+		}
+		return &openAITranslator{url: strings.TrimRight(url, "/"), model: model, apiKey: apiKey, client: client}, nil
+	}
+}
+
+// languageName returns the English name of a language code for prompts, or
+// the code itself when it is not in the registry.
+func languageName(code string) string {
+	if l, ok := lang.Lookup(code); ok {
+		return l.Name
+	}
+	return code
+}
+
+// checkCount verifies that a service returned one translation per line.
+func checkCount(got []string, req Request) error {
+	if len(got) != len(req.Lines) {
+		return fmt.Errorf("translator returned %d lines for %d", len(got), len(req.Lines))
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package translate
+
+import "testing"
+
+func TestParseProvider(t *testing.T) {
+	for in, want := range map[string]Provider{"": OpenAI, "openai": OpenAI, "HTTP": HTTP} {
+		got, err := ParseProvider(in)
+		if err != nil || got != want {
+			t.Errorf("ParseProvider(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParseProvider("deepl"); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
+
+func TestNew_RequiredSettings(t *testing.T) {
+	if _, err := New(HTTP, "", "", ""); err == nil {
+		t.Error("expected an error for the http translator without a URL")
+	}
+	if _, err := New(OpenAI, "", "", ""); err == nil {
+		t.Error("expected an error for the openai translator without a model")
+	}
+	tr, err := New(OpenAI, "", "gpt-4o-mini", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tr.(*openAITranslator).url; got != DefaultOpenAIURL {
+		t.Errorf("url = %q, want %q", got, DefaultOpenAIURL)
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	if got := languageName("ja"); got != "Japanese" {
+		t.Errorf("languageName(ja) = %q", got)
+	}
+	if got := languageName("xx"); got != "xx" {
+		t.Errorf("languageName(xx) = %q", got)
+	}
+}
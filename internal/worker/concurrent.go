@@ -9,7 +9,6 @@ import (
 	"sync"
 	"time"
 
-	"scribe2srt/internal/api"
 	"scribe2srt/internal/pipeline"
 
 	"golang.org/x/sync/errgroup"
@@ -69,7 +68,7 @@ func processConcurrent(ctx context.Context, chunks []string, splitDurationSec in
 						"percent", fmt.Sprintf("%.1f%%", pct))
 				}
 
-				t, err := api.Transcribe(gctx, chunk, opts.Language, opts.TagAudioEvents, progress)
+				t, err := transcribe(gctx, chunk, opts, progress)
 				if err == nil {
 					transcript = t
 					break
@@ -3,12 +3,14 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"scribe2srt/internal/api"
 	"scribe2srt/internal/config"
@@ -37,19 +39,104 @@ type Options struct {
 	SplitDurationMin int
 	SaveJSON         bool
 	Settings         *config.SubtitleSettings
+
+	// SnapToShots runs scene-change detection on video inputs and snaps cue
+	// boundaries to the detected cuts.
+	SnapToShots   bool
+	ShotThreshold float64
+
+	// GlossaryReport, when set, is the path of a TSV file listing the
+	// glossary substitutions applied.
+	GlossaryReport string
+
+	// Keyterms are sent with every request to bias recognition. If the
+	// backend rejects them, keytermsOff switches them off for all chunks.
+	Keyterms    []string
+	keytermsOff *atomic.Bool
+
+	// Script, when set, is the reference text of the recording. It replaces
+	// the recognised text and is timed by aligning it to the recognised words.
+	Script string
 }
 
 // Run is the top-level orchestrator for the transcription pipeline.
 func Run(ctx context.Context, opts Options) error {
-	inputPath := opts.InputPath
-
 	// Determine output path.
-	outputSRT := opts.OutputPath
-	if outputSRT == "" {
-		base := strings.TrimSuffix(inputPath, filepath.Ext(inputPath))
-		outputSRT = base + ".srt"
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		base := strings.TrimSuffix(opts.InputPath, filepath.Ext(opts.InputPath))
+		outputPath = base + opts.Settings.Format.Extension()
+	}
+
+	combined, err := Transcribe(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// Save combined JSON if requested.
+	if opts.SaveJSON {
+		jsonPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".json"
+		if err := saveJSON(jsonPath, combined); err != nil {
+			slog.Warn("failed to save JSON", "err", err)
+		} else {
+			slog.Info("transcript JSON saved", "path", jsonPath)
+		}
+	}
+
+	if opts.Script != "" {
+		aligned, stats, err := pipeline.AlignScript(combined, opts.Script)
+		if err != nil {
+			return fmt.Errorf("align script: %w", err)
+		}
+		slog.Info("script aligned", "matched", stats.Matched, "substituted", stats.Substituted,
+			"interpolated", stats.Inserted, "unscripted", stats.Deleted)
+		combined = aligned
+	}
+
+	// Generate subtitles.
+	slog.Info("generating subtitles", "format", opts.Settings.Format)
+	if tr := opts.Settings.Translation; tr != nil {
+		slog.Info("translating subtitles", "target", tr.Target, "bilingual", tr.Bilingual)
+	}
+	content, report, err := pipeline.ProcessContext(ctx, combined, opts.Settings)
+	if err != nil {
+		return err
+	}
+	if content == "" {
+		return fmt.Errorf("subtitle generation produced empty output")
+	}
+
+	if opts.Settings.Profanity != nil {
+		slog.Info("profanity filter applied", "masked", report.Masked)
+	}
+
+	if opts.Settings.Glossary != nil {
+		slog.Info("glossary applied", "substitutions", len(report.Substitutions))
+		if opts.GlossaryReport != "" {
+			if err := saveGlossaryReport(opts.GlossaryReport, report.Substitutions); err != nil {
+				slog.Warn("failed to save glossary report", "err", err)
+			} else {
+				slog.Info("glossary report saved", "path", opts.GlossaryReport)
+			}
+		}
+	}
+
+	if err := os.WriteFile(outputPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("write subtitle file: %w", err)
 	}
 
+	slog.Info("subtitle file saved", "path", outputPath)
+	return nil
+}
+
+// Transcribe probes, extracts and splits the input as needed and returns the
+// combined transcript with timestamps relative to the start of the input.
+// It fills the title and artist of opts.Settings that are not set from the
+// input's metadata and, with SnapToShots, its shot changes.
+func Transcribe(ctx context.Context, opts Options) (*pipeline.TranscriptResponse, error) {
+	inputPath := opts.InputPath
+	opts.keytermsOff = new(atomic.Bool)
+
 	slog.Info("processing file", "input", filepath.Base(inputPath))
 
 	// Probe media.
@@ -58,6 +145,12 @@ func Run(ctx context.Context, opts Options) error {
 	if info != nil {
 		duration = info.Duration
 	}
+	if opts.Settings.Title == "" {
+		opts.Settings.Title = info.Tag("title")
+	}
+	if opts.Settings.Artist == "" {
+		opts.Settings.Artist = info.Tag("artist", "album_artist")
+	}
 
 	splitDurationSec := opts.SplitDurationMin * 60
 	workingPath := inputPath
@@ -70,7 +163,7 @@ func Run(ctx context.Context, opts Options) error {
 		tempAudioFile = filepath.Join(filepath.Dir(inputPath), "temp_audio_"+base+".m4a")
 		slog.Info("extracting audio from video")
 		if err := ffmpeg.ExtractAudio(ctx, inputPath, tempAudioFile); err != nil {
-			return fmt.Errorf("extract audio: %w", err)
+			return nil, fmt.Errorf("extract audio: %w", err)
 		}
 		workingPath = tempAudioFile
 		defer func() {
@@ -78,6 +171,12 @@ func Run(ctx context.Context, opts Options) error {
 		}()
 	}
 
+	if opts.SnapToShots {
+		if err := detectShots(ctx, inputPath, opts); err != nil {
+			return nil, err
+		}
+	}
+
 	var combined *pipeline.TranscriptResponse
 	var chunkFiles []string
 
@@ -88,7 +187,7 @@ func Run(ctx context.Context, opts Options) error {
 
 		chunks, err := ffmpeg.SplitAudio(ctx, workingPath, filepath.Dir(workingPath), splitDurationSec)
 		if err != nil {
-			return fmt.Errorf("split audio: %w", err)
+			return nil, fmt.Errorf("split audio: %w", err)
 		}
 		chunkFiles = chunks
 		defer cleanupChunks(chunkFiles)
@@ -101,44 +200,40 @@ func Run(ctx context.Context, opts Options) error {
 			combined, err = processSequential(ctx, chunks, splitDurationSec, opts)
 		}
 		if err != nil {
-			return err
+			return nil, err
 		}
 	} else {
 		// Single file processing.
 		slog.Info("processing as single file")
 		transcript, err := transcribeWithProgress(ctx, workingPath, opts)
 		if err != nil {
-			return fmt.Errorf("transcribe: %w", err)
+			return nil, fmt.Errorf("transcribe: %w", err)
 		}
 		combined = transcript
 	}
 
 	if combined == nil || (len(combined.Words) == 0 && combined.Text == "") {
-		return fmt.Errorf("empty transcript received")
+		return nil, fmt.Errorf("empty transcript received")
 	}
+	return combined, nil
+}
 
-	// Save combined JSON if requested.
-	if opts.SaveJSON {
-		jsonPath := strings.TrimSuffix(outputSRT, filepath.Ext(outputSRT)) + ".json"
-		if err := saveJSON(jsonPath, combined); err != nil {
-			slog.Warn("failed to save JSON", "err", err)
-		} else {
-			slog.Info("transcript JSON saved", "path", jsonPath)
-		}
+// detectShots fills opts.Settings.ShotChanges from the input video. Audio-only
+// inputs have no shots, so they are skipped with a warning.
+func detectShots(ctx context.Context, inputPath string, opts Options) error {
+	if !ffmpeg.IsVideoExtension(filepath.Ext(inputPath)) {
+		slog.Warn("--snap-to-shots ignored: input is not a video", "input", filepath.Base(inputPath))
+		return nil
 	}
-
-	// Generate SRT.
-	slog.Info("generating SRT subtitles")
-	srtContent := pipeline.Process(combined, opts.Settings)
-	if srtContent == "" {
-		return fmt.Errorf("SRT generation produced empty output")
+	if !ffmpeg.Available() {
+		return fmt.Errorf("--snap-to-shots requires ffmpeg on the PATH")
 	}
 
-	if err := os.WriteFile(outputSRT, []byte(srtContent), 0644); err != nil {
-		return fmt.Errorf("write SRT file: %w", err)
+	shots, err := ffmpeg.DetectShots(ctx, inputPath, opts.ShotThreshold)
+	if err != nil {
+		return fmt.Errorf("detect shots: %w", err)
 	}
-
-	slog.Info("SRT file saved", "path", outputSRT)
+	opts.Settings.ShotChanges = shots
 	return nil
 }
 
@@ -151,7 +246,26 @@ func transcribeWithProgress(ctx context.Context, path string, opts Options) (*pi
 		slog.Debug("upload progress", "percent", fmt.Sprintf("%.1f%%", pct))
 	}
 
-	return api.Transcribe(ctx, path, opts.Language, opts.TagAudioEvents, progress)
+	return transcribe(ctx, path, opts, progress)
+}
+
+// transcribe sends one file to the API with the key terms from opts. If the
+// backend rejects key terms, biasing is switched off for every later request
+// and the file is sent again without them.
+func transcribe(ctx context.Context, path string, opts Options, progress api.ProgressFunc) (*pipeline.TranscriptResponse, error) {
+	terms := opts.Keyterms
+	if opts.keytermsOff != nil && opts.keytermsOff.Load() {
+		terms = nil
+	}
+
+	t, err := api.Transcribe(ctx, path, opts.Language, opts.TagAudioEvents, terms, progress)
+	if len(terms) > 0 && errors.Is(err, api.ErrKeytermsUnsupported) {
+		if opts.keytermsOff == nil || opts.keytermsOff.CompareAndSwap(false, true) {
+			slog.Warn("transcription backend does not support key terms, continuing without them", "err", err)
+		}
+		return api.Transcribe(ctx, path, opts.Language, opts.TagAudioEvents, nil, progress)
+	}
+	return t, err
 }
 
 func saveJSON(path string, transcript *pipeline.TranscriptResponse) error {
@@ -162,6 +276,17 @@ func saveJSON(path string, transcript *pipeline.TranscriptResponse) error {
 	return os.WriteFile(path, data, 0644)
 }
 
+// saveGlossaryReport writes one line per substitution: start and end time,
+// the recognised text, its replacement and the glossary line of the rule.
+func saveGlossaryReport(path string, subs []pipeline.Substitution) error {
+	var sb strings.Builder
+	sb.WriteString("start\tend\toriginal\treplacement\trule_line\n")
+	for _, s := range subs {
+		fmt.Fprintf(&sb, "%.3f\t%.3f\t%s\t%s\t%d\n", s.Start, s.End, s.Original, s.Replacement, s.Line)
+	}
+	return os.WriteFile(path, []byte(sb.String()), 0644)
+}
+
 func cleanupChunks(chunks []string) {
 	for _, chunk := range chunks {
 		if err := os.Remove(chunk); err != nil && !os.IsNotExist(err) {
@@ -0,0 +1,271 @@
+// Package zhconv converts Chinese text between Simplified and Traditional
+// characters and between the Mainland, Taiwan and Hong Kong vocabularies.
+//
+// Conversion works on phrases rather than single characters: each stage
+// replaces the longest dictionary entry starting at every position, so that
+// 软件 becomes 軟體 in Taiwan usage instead of the character-by-character
+// 軟件. The dictionaries are those of the OpenCC project, bundled in the
+// binary under the Apache License 2.0 (see dict/LICENSE).
+package zhconv
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed dict/*.txt
+var dictFS embed.FS
+
+// Variant selects the target script and regional vocabulary.
+type Variant int
+
+const (
+	// None leaves text unchanged.
+	None Variant = iota
+	// Hans converts to Simplified Chinese.
+	Hans
+	// Hant converts to Traditional Chinese using the OpenCC standard
+	// character forms.
+	Hant
+	// TW converts to Traditional Chinese with Taiwan character forms and
+	// vocabulary.
+	TW
+	// HK converts to Traditional Chinese with Hong Kong character forms.
+	HK
+)
+
+// ParseVariant parses a --zh-variant value.
+func ParseVariant(s string) (Variant, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none", "off":
+		return None, nil
+	case "hans", "zh-hans", "simplified":
+		return Hans, nil
+	case "hant", "zh-hant", "traditional":
+		return Hant, nil
+	case "tw", "zh-tw":
+		return TW, nil
+	case "hk", "zh-hk":
+		return HK, nil
+	}
+	return None, fmt.Errorf("unknown Chinese variant %q (want hans, hant, tw or hk)", s)
+}
+
+func (v Variant) String() string {
+	switch v {
+	case Hans:
+		return "hans"
+	case Hant:
+		return "hant"
+	case TW:
+		return "tw"
+	case HK:
+		return "hk"
+	default:
+		return "none"
+	}
+}
+
+// chains lists, per variant, the conversion stages applied in order. Each
+// stage is a group of dictionary files; earlier files in a group win when
+// two define the same key.
+var chains = map[Variant][][]string{
+	// Traditional input in any regional form is first mapped back to the
+	// OpenCC standard forms, then simplified.
+	Hans: {
+		{"TWPhrasesRev", "TWVariantsRevPhrases", "TWVariantsRev"},
+		{"TSPhrases", "TSCharacters"},
+	},
+	Hant: {
+		{"STPhrases", "STCharacters"},
+	},
+	TW: {
+		{"STPhrases", "STCharacters"},
+		{"TWPhrases"},
+		{"TWVariants"},
+	},
+	HK: {
+		{"STPhrases", "STCharacters"},
+		{"HKVariants"},
+	},
+}
+
+// Converter converts text to one variant. A Converter is safe for
+// concurrent use.
+type Converter struct {
+	variant Variant
+	stages  []*dict
+}
+
+var (
+	convertersMu sync.Mutex
+	converters   = map[Variant]*Converter{}
+)
+
+// New returns the converter for v, loading its dictionaries on first use.
+// It returns nil for None.
+func New(v Variant) (*Converter, error) {
+	if v == None {
+		return nil, nil
+	}
+	groups, ok := chains[v]
+	if !ok {
+		return nil, fmt.Errorf("unknown Chinese variant %d", v)
+	}
+
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	if c, ok := converters[v]; ok {
+		return c, nil
+	}
+	c := &Converter{variant: v}
+	for _, names := range groups {
+		d, err := loadGroup(names)
+		if err != nil {
+			return nil, err
+		}
+		c.stages = append(c.stages, d)
+	}
+	converters[v] = c
+	return c, nil
+}
+
+// Variant returns the variant c converts to.
+func (c *Converter) Variant() Variant {
+	return c.variant
+}
+
+// Convert converts s.
+func (c *Converter) Convert(s string) string {
+	out, _ := c.ConvertAligned(s)
+	return out
+}
+
+// ConvertAligned converts s and returns, for every rune offset i in s from
+// 0 to the rune count inclusive, the rune offset in the result that
+// corresponds to it. An offset is -1 when it falls inside a phrase whose
+// replacement has a different length, where no position in the result
+// matches it.
+func (c *Converter) ConvertAligned(s string) (string, []int) {
+	runes := []rune(s)
+	cuts := make([]int, len(runes)+1)
+	for i := range cuts {
+		cuts[i] = i
+	}
+	for _, d := range c.stages {
+		var stageCuts []int
+		runes, stageCuts = d.convert(runes)
+		for i, pos := range cuts {
+			if pos >= 0 {
+				cuts[i] = stageCuts[pos]
+			}
+		}
+	}
+	return string(runes), cuts
+}
+
+// dict is one conversion stage: a phrase table matched longest first.
+type dict struct {
+	entries map[string][]rune
+	maxLen  int
+}
+
+// convert replaces the longest entry at each position of in. cuts maps
+// every offset of in to the corresponding offset of out, or -1.
+func (d *dict) convert(in []rune) (out []rune, cuts []int) {
+	out = make([]rune, 0, len(in))
+	cuts = make([]int, len(in)+1)
+	for i := 0; i < len(in); {
+		n, repl := d.match(in[i:])
+		if n == 0 {
+			cuts[i] = len(out)
+			out = append(out, in[i])
+			i++
+			continue
+		}
+		for j := 0; j < n; j++ {
+			if len(repl) == n {
+				cuts[i+j] = len(out) + j
+			} else {
+				cuts[i+j] = -1
+			}
+		}
+		cuts[i] = len(out)
+		out = append(out, repl...)
+		i += n
+	}
+	cuts[len(in)] = len(out)
+	return out, cuts
+}
+
+// match returns the length in runes of the longest entry that prefixes s,
+// with its replacement, or 0 when none does.
+func (d *dict) match(s []rune) (int, []rune) {
+	for n := min(d.maxLen, len(s)); n > 0; n-- {
+		if repl, ok := d.entries[string(s[:n])]; ok {
+			return n, repl
+		}
+	}
+	return 0, nil
+}
+
+var (
+	dictsMu sync.Mutex
+	dicts   = map[string]map[string][]rune{}
+)
+
+// loadGroup merges the named dictionary files into one stage.
+func loadGroup(names []string) (*dict, error) {
+	d := &dict{entries: map[string][]rune{}}
+	for _, name := range names {
+		entries, err := loadDict(name)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range entries {
+			if _, ok := d.entries[k]; ok {
+				continue
+			}
+			d.entries[k] = v
+			d.maxLen = max(d.maxLen, len([]rune(k)))
+		}
+	}
+	return d, nil
+}
+
+// loadDict parses an OpenCC dictionary file. Each line holds a key, a tab
+// and one or more space-separated candidates, of which the first is used.
+func loadDict(name string) (map[string][]rune, error) {
+	dictsMu.Lock()
+	defer dictsMu.Unlock()
+	if entries, ok := dicts[name]; ok {
+		return entries, nil
+	}
+
+	data, err := dictFS.ReadFile("dict/" + name + ".txt")
+	if err != nil {
+		return nil, fmt.Errorf("load dictionary %s: %w", name, err)
+	}
+	entries := map[string][]rune{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		key, values, ok := strings.Cut(sc.Text(), "\t")
+		if !ok || key == "" {
+			continue
+		}
+		value, _, _ := strings.Cut(values, " ")
+		if value == "" {
+			continue
+		}
+		entries[key] = []rune(value)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("load dictionary %s: %w", name, err)
+	}
+	dicts[name] = entries
+	return entries, nil
+}
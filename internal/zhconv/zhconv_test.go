@@ -0,0 +1,99 @@
+package zhconv
+
+import "testing"
+
+func TestParseVariant(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Variant
+	}{
+		{"", None},
+		{"none", None},
+		{"hans", Hans},
+		{"Hant", Hant},
+		{"tw", TW},
+		{"zh-HK", HK},
+	}
+	for _, tt := range tests {
+		got, err := ParseVariant(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseVariant(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+		if tt.want != None && got.String() == "none" {
+			t.Errorf("%v.String() = none", got)
+		}
+	}
+	if _, err := ParseVariant("cn"); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		variant Variant
+		in      string
+		want    string
+	}{
+		// 软件的信息 -> 軟件的信息 in standard Traditional...
+		{Hant, "\u8f6f\u4ef6\u7684\u4fe1\u606f", "\u8edf\u4ef6\u7684\u4fe1\u606f"},
+		// ...but 軟體的資訊 in Taiwan usage.
+		{TW, "\u8f6f\u4ef6\u7684\u4fe1\u606f", "\u8edf\u9ad4\u7684\u8cc7\u8a0a"},
+		// 头发 -> 頭髮, not 頭發: the phrase decides the character.
+		{Hant, "\u5934\u53d1", "\u982d\u9aee"},
+		// 里面 -> 裡面 in Taiwan, 裏面 in Hong Kong.
+		{TW, "\u91cc\u9762", "\u88e1\u9762"},
+		{HK, "\u91cc\u9762", "\u88cf\u9762"},
+		// 軟體的資訊 -> 软件的信息
+		{Hans, "\u8edf\u9ad4\u7684\u8cc7\u8a0a", "\u8f6f\u4ef6\u7684\u4fe1\u606f"},
+		{TW, "iPhone 15", "iPhone 15"},
+	}
+	for _, tt := range tests {
+		c, err := New(tt.variant)
+		if err != nil {
+			t.Fatalf("New(%v): %v", tt.variant, err)
+		}
+		if got := c.Convert(tt.in); got != tt.want {
+			t.Errorf("%v: Convert(%q) = %q, want %q", tt.variant, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNew_None(t *testing.T) {
+	c, err := New(None)
+	if c != nil || err != nil {
+		t.Errorf("New(None) = %v, %v; want nil, nil", c, err)
+	}
+}
+
+func TestConvertAligned(t *testing.T) {
+	c, err := New(TW)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 鼠标坏了 -> 滑鼠壞了: 鼠标 -> 滑鼠 keeps its length, so every
+	// offset has a counterpart.
+	out, cuts := c.ConvertAligned("\u9f20\u6807\u574f\u4e86")
+	if out != "\u6ed1\u9f20\u58de\u4e86" {
+		t.Errorf("out = %q", out)
+	}
+	want := []int{0, 1, 2, 3, 4}
+	for i := range want {
+		if cuts[i] != want[i] {
+			t.Fatalf("cuts = %v, want %v", cuts, want)
+		}
+	}
+
+	// U盘坏了 -> 隨身碟壞了: U盘 -> 隨身碟 changes length, so the offset
+	// inside it has none.
+	out, cuts = c.ConvertAligned("U\u76d8\u574f\u4e86")
+	if out != "\u96a8\u8eab\u789f\u58de\u4e86" {
+		t.Errorf("out = %q", out)
+	}
+	want = []int{0, -1, 3, 4, 5}
+	for i := range want {
+		if cuts[i] != want[i] {
+			t.Fatalf("cuts = %v, want %v", cuts, want)
+		}
+	}
+}